@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"github.com/spf13/cobra"
+	"linkreduction/internal/config"
+	"linkreduction/internal/handler"
+	"linkreduction/internal/repository/postgres"
+	"linkreduction/internal/service"
+)
+
+var apiKeyCmd = &cobra.Command{
+	Use:   "apikey",
+	Short: "Manage API keys",
+}
+
+var apiKeyCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create an API key for an owner and print it once",
+	// Ошибки возвращаются из RunE, а не через os.Exit, чтобы отложенное закрытие пула успело выполниться
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		owner, _ := cmd.Flags().GetString("owner")
+		name, _ := cmd.Flags().GetString("name")
+
+		apiKeys, closeDB, err := initAPIKeys(cmd)
+		if err != nil {
+			return err
+		}
+		defer closeDB()
+
+		plain, key, err := apiKeys.Create(context.Background(), owner, name)
+		if err != nil {
+			return fmt.Errorf("ошибка создания API-ключа: %w", err)
+		}
+
+		fmt.Printf("ID: %d\nВладелец: %s\nКлюч: %s\n", key.ID, key.OwnerID, plain)
+		fmt.Println("Сохраните ключ: повторно показать его невозможно.")
+		return nil
+	},
+}
+
+var apiKeyRevokeCmd = &cobra.Command{
+	Use:          "revoke",
+	Short:        "Revoke an API key by its ID",
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, _ := cmd.Flags().GetInt64("id")
+
+		apiKeys, closeDB, err := initAPIKeys(cmd)
+		if err != nil {
+			return err
+		}
+		defer closeDB()
+
+		if err := apiKeys.Revoke(context.Background(), id); err != nil {
+			return err
+		}
+
+		fmt.Printf("API-ключ %d отозван\n", id)
+		return nil
+	},
+}
+
+func initAPIKeys(cmd *cobra.Command) (*service.APIKeys, func(), error) {
+	cfg, err := config.LoadConfig(configPath(cmd))
+	if err != nil {
+		return nil, nil, fmt.Errorf("ошибка загрузки конфигурационного файла: %w", err)
+	}
+
+	db, err := handler.InitPostgres(context.Background(), &cfg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("ошибка инициализации базы данных: %w", err)
+	}
+
+	return service.NewAPIKeys(postgres.NewPostgresAPIKeyRepository(db)), db.Close, nil
+}
+
+func init() {
+	rootCmd.AddCommand(apiKeyCmd)
+	apiKeyCmd.AddCommand(apiKeyCreateCmd, apiKeyRevokeCmd)
+
+	apiKeyCmd.PersistentFlags().StringP("file", "f", "", "Путь к файлу конфигурации")
+
+	apiKeyCreateCmd.Flags().String("owner", "", "Идентификатор владельца ссылок (например, название команды)")
+	apiKeyCreateCmd.Flags().String("name", "", "Описание ключа")
+	_ = apiKeyCreateCmd.MarkFlagRequired("owner")
+
+	apiKeyRevokeCmd.Flags().Int64("id", 0, "ID ключа")
+	_ = apiKeyRevokeCmd.MarkFlagRequired("id")
+}
@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"os"
+	"path/filepath"
+)
+
+// configPath возвращает абсолютный путь к файлу конфигурации из флага --file
+// и завершает процесс, если файл недоступен.
+func configPath(cmd *cobra.Command) string {
+	filePath, _ := cmd.Flags().GetString("file")
+	if filePath == "" {
+		filePath = "internal/config/config.yaml"
+	}
+
+	absPath, err := filepath.Abs(filePath)
+	if err != nil {
+		fmt.Printf("Ошибка разрешения пути к файлу: %v\n", err)
+		os.Exit(1)
+	}
+
+	if _, err := os.Stat(absPath); os.IsNotExist(err) {
+		fmt.Printf("Файл не найден: %s\n", absPath)
+		os.Exit(1)
+	} else if err != nil {
+		fmt.Printf("Ошибка проверки файла: %v\n", err)
+		os.Exit(1)
+	}
+
+	return absPath
+}
@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"linkreduction/internal/config"
+	"linkreduction/internal/handler"
+	"linkreduction/internal/kafka"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+var dlqCmd = &cobra.Command{
+	Use:   "dlq",
+	Short: "Manage the shorten-urls dead-letter topic",
+}
+
+var dlqReplayCmd = &cobra.Command{
+	Use:   "replay",
+	Short: "Move messages from shorten-urls.dlq back into shorten-urls",
+	// Ошибки возвращаются из RunE, а не через os.Exit, чтобы отложенное закрытие producer успело выполниться
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		limit, _ := cmd.Flags().GetInt("limit")
+		idle, _ := cmd.Flags().GetDuration("idle-timeout")
+
+		logger := logrus.New()
+		logger.SetFormatter(&logrus.JSONFormatter{})
+
+		cfg, err := config.LoadConfig(configPath(cmd))
+		if err != nil {
+			return fmt.Errorf("ошибка загрузки конфигурационного файла: %w", err)
+		}
+
+		producer, err := handler.InitKafkaProducer(&cfg)
+		if err != nil {
+			return fmt.Errorf("ошибка инициализации Kafka: %w", err)
+		}
+		defer func() { _ = producer.Close() }()
+
+		ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+		defer stop()
+
+		n, err := kafka.NewDLQReplayer(producer, logger, limit, idle).Replay(ctx, &cfg)
+		fmt.Printf("Перенесено сообщений: %d\n", n)
+		return err
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(dlqCmd)
+	dlqCmd.AddCommand(dlqReplayCmd)
+
+	dlqCmd.PersistentFlags().StringP("file", "f", "", "Путь к файлу конфигурации")
+
+	dlqReplayCmd.Flags().Int("limit", 0, "Максимальное число сообщений (0 — все)")
+	dlqReplayCmd.Flags().Duration("idle-timeout", 10*time.Second, "Партиция считается разобранной, если за это время не пришло сообщений")
+}
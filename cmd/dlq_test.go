@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDLQReplayCommand(t *testing.T) {
+	cmd, _, err := rootCmd.Find([]string{"dlq", "replay"})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Same(t, dlqReplayCmd, cmd)
+
+	limit, _ := cmd.Flags().GetInt("limit")
+	idle, _ := cmd.Flags().GetDuration("idle-timeout")
+	assert.Equal(t, 0, limit, "по умолчанию переносятся все сообщения")
+	assert.Equal(t, 10*time.Second, idle)
+
+	t.Cleanup(func() {
+		_ = cmd.Flags().Set("limit", "0")
+		_ = cmd.Flags().Set("idle-timeout", "10s")
+		_ = cmd.Flags().Set("file", "")
+	})
+	configFile := filepath.Join(t.TempDir(), "dlq.yaml")
+	assert.NoError(t, os.WriteFile(configFile, nil, 0o600))
+	assert.NoError(t, cmd.ParseFlags([]string{"--limit", "5", "--idle-timeout", "2s", "-f", configFile}))
+
+	limit, _ = cmd.Flags().GetInt("limit")
+	idle, _ = cmd.Flags().GetDuration("idle-timeout")
+	assert.Equal(t, 5, limit)
+	assert.Equal(t, 2*time.Second, idle)
+
+	assert.Equal(t, configFile, configPath(cmd), "флаг --file наследуется от команды dlq")
+}
@@ -2,22 +2,24 @@ package cmd
 
 import (
 	"context"
-	"fmt"
 	"github.com/gofiber/fiber/v2"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"linkreduction/internal/bot"
 	"linkreduction/internal/config"
+	"linkreduction/internal/grpcserver"
 	"linkreduction/internal/handler"
 	"linkreduction/internal/kafka"
 	"linkreduction/internal/prometheus"
+	"linkreduction/internal/repository/memory"
 	"linkreduction/internal/repository/postgres"
 	"linkreduction/internal/repository/redis"
 	"linkreduction/internal/service"
 	"linkreduction/migrations"
+	"net"
 	"os"
 	"os/signal"
-	"path/filepath"
 	"syscall"
 )
 
@@ -30,24 +32,7 @@ var shortenCmd = &cobra.Command{
 		logger.SetFormatter(&logrus.JSONFormatter{})
 		logger.SetLevel(logrus.InfoLevel)
 
-		filePath, _ := cmd.Flags().GetString("file")
-		if filePath == "" {
-			filePath = "internal/config/config.yaml"
-		}
-
-		absPath, err := filepath.Abs(filePath)
-		if err != nil {
-			fmt.Printf("Ошибка разрешения пути к файлу: %v\n", err)
-			os.Exit(1)
-		}
-
-		if _, err := os.Stat(absPath); os.IsNotExist(err) {
-			fmt.Printf("Файл не найден: %s\n", absPath)
-			os.Exit(1)
-		} else if err != nil {
-			fmt.Printf("Ошибка проверки файла: %v\n", err)
-			os.Exit(1)
-		}
+		absPath := configPath(cmd)
 
 		ctx, cancel := context.WithCancel(context.Background())
 		defer cancel()
@@ -62,27 +47,35 @@ var shortenCmd = &cobra.Command{
 
 		logrus.Infof("Версия приложения:%v", cfg.Version)
 
-		migrations.RunMigrations(logger, &cfg)
+		// Хранилища sqlite и memory позволяют запустить сервис без Postgres
+		var db *pgxpool.Pool
+		if handler.UsesPostgres(&cfg) {
+			migrations.RunMigrations(logger, &cfg)
 
-		db, err := handler.InitPostgres(&cfg)
-		if err != nil {
-			logger.Fatal("Ошибка инициализации базы данных")
-		}
-		defer func() {
-			if err := db.Close(); err != nil {
-				logger.Fatal("Ошибка при закрытии базы данных")
+			db, err = handler.InitPostgres(ctx, &cfg)
+			if err != nil {
+				logger.Fatal("Ошибка инициализации базы данных")
 			}
-		}()
+			defer db.Close()
+		}
 
 		redisClient, err := handler.RedisConnect(ctx, &cfg)
 		if err != nil {
-			logger.Fatal("Ошибка инициализации Redis")
-		}
-		defer func() {
-			if err := redisClient.Close(); err != nil {
-				logger.Fatal("Ошибка при закрытии Redis соединения")
+			// Ошибка в настройках (клиент не создан) останавливает запуск и для необязательного Redis
+			if cfg.Redis.Required || (redisClient == nil && cfg.Redis.URL != "") {
+				logger.WithError(err).Fatal("Ошибка инициализации Redis")
 			}
-		}()
+			logger.WithError(err).Warn("Redis недоступен при запуске: до восстановления соединения сервис работает без кэша")
+		}
+		if redisClient == nil {
+			logger.Warn("redis.url не задан: сервис работает без кэша и ограничения частоты запросов")
+		} else {
+			defer func() {
+				if err := redisClient.Close(); err != nil {
+					logger.Fatal("Ошибка при закрытии Redis соединения")
+				}
+			}()
+		}
 
 		kafkaProducer, err := handler.InitKafkaProducer(&cfg)
 		if err != nil {
@@ -98,25 +91,110 @@ var shortenCmd = &cobra.Command{
 
 		metrics := initprometheus.InitPrometheus()
 
-		linkRepo := postgres.NewPostgresLinkRepository(db)
-		cache := redis.NewLink(redisClient, logger)
+		var replicas *postgres.Replicas
+		if db != nil {
+			replicas, err = handler.InitReplicas(ctx, &cfg)
+			if err != nil {
+				logger.WithError(err).Fatal("Ошибка инициализации реплик базы данных")
+			}
+		}
+		if replicas != nil {
+			defer replicas.Close()
+			go replicas.Watch(ctx, cfg.DB.ReplicaCheckInterval, logger)
+		}
+
+		linkRepo, closeLinkRepo, err := handler.InitLinkRepo(&cfg, db, replicas)
+		if err != nil {
+			logger.WithError(err).Fatal("Ошибка инициализации хранилища ссылок")
+		}
+		defer func() {
+			if err := closeLinkRepo(); err != nil {
+				logger.Fatal("Ошибка при закрытии хранилища ссылок")
+			}
+		}()
+		// Без Redis сервис работает без кэша и лимитов: nil-зависимости заменяются заглушками
+		var (
+			linkCache  service.LinkCache
+			titleCache service.TitleCache
+			rateStore  service.RateLimitStore
+		)
+		if redisClient != nil {
+			breaker := redis.NewBreaker(cfg.Redis.Breaker.Failures, cfg.Redis.Breaker.Cooldown, metrics, logger)
+			cache := redis.NewLink(redisClient, breaker, logger)
+			linkCache, titleCache, rateStore = cache, cache, redis.NewRateLimit(redisClient, breaker)
+
+			if cfg.Redis.LocalCache.MaxEntries > 0 {
+				invalidations := redis.NewInvalidations(redisClient, logger)
+				localCache := memory.NewLinkCache(cache, invalidations, metrics, cfg.Redis.LocalCache.MaxEntries, cfg.Redis.LocalCache.TTL)
+				go invalidations.Listen(ctx, localCache.Evict, localCache.Purge)
+				linkCache = localCache
+			}
+		}
+
+		sequence, _ := linkRepo.(service.KeySequence)
+		keyGen, err := service.NewKeyGenerator(cfg.Links.KeyGenerator, cfg.Links.KeyLength, sequence)
+		if err != nil {
+			logger.WithError(err).Fatal("Ошибка инициализации генератора ключей")
+		}
 
-		linkService := service.NewLinkService(ctx, linkRepo, cache, kafkaProducer, metrics)
+		policy, err := service.NewDestinationPolicy(service.PolicyRules(cfg.Policy))
+		if err != nil {
+			logger.WithError(err).Fatal("Ошибка инициализации политики адресов назначения")
+		}
+		go policy.WatchBlocklist(ctx, logger)
+
+		linkService := service.NewLinkService(ctx, linkRepo, linkCache, kafkaProducer, metrics,
+			service.WithDefaultTTL(cfg.Links.DefaultTTL),
+			service.WithCleanupInterval(cfg.Links.CleanupInterval),
+			service.WithNegativeCacheTTL(cfg.Links.NegativeCacheTTL),
+			service.WithKeyGenerator(keyGen),
+			service.WithKeyMaxAttempts(cfg.Links.KeyMaxAttempts),
+			service.WithBatchMaxItems(cfg.Links.BatchMaxItems),
+			service.WithDefaultRedirectCode(cfg.Links.RedirectCode),
+			service.WithDestinationPolicy(policy),
+			service.WithPageTitles(service.NewPageTitles(titleCache, cfg.Links.TitleTimeout, cfg.Policy.AllowPrivateNetworks)))
+
+		var (
+			clickRepo  service.ClickRepo
+			apiKeyRepo service.APIKeyRepo
+		)
+		if db != nil {
+			clickRepo, apiKeyRepo = postgres.NewPostgresClickRepository(db), postgres.NewPostgresAPIKeyRepository(db)
+		} else {
+			logger.Warn("db.linksdb_dsn не задан: API-ключи и переходы хранятся в памяти процесса и не переживут перезапуск")
+			clickRepo, apiKeyRepo = memory.NewClickRepository(), memory.NewAPIKeyRepository()
+		}
+		analytics := service.NewAnalytics(ctx, clickRepo, kafkaProducer, metrics, cfg.Analytics.IPSalt)
 
 		kafkaConsumer := kafka.NewConsumer(ctx, kafkaProducer,
 			logger, linkService, &cfg)
+		clickConsumer := kafka.NewClickConsumer(ctx, logger, analytics, &cfg)
+
+		apiKeys := service.NewAPIKeys(apiKeyRepo)
 
-		h, err := handler.NewHandler(ctx, linkService, metrics, logger, &cfg)
+		limiter := service.NewRateLimiter(rateStore, map[string]service.RateLimit{
+			service.RateLimitCreate:   service.RateLimit(cfg.RateLimit.Create),
+			service.RateLimitRedirect: service.RateLimit(cfg.RateLimit.Redirect),
+			service.RateLimitBot:      service.RateLimit(cfg.RateLimit.Bot),
+			service.RateLimitAuth:     service.RateLimit(cfg.RateLimit.Auth),
+		}, metrics)
+
+		if cfg.Auth.LinkCookieSecret == "" {
+			logger.Warn("auth.link_cookie_secret не задан: доступ к ссылкам с паролем сбросится при перезапуске")
+		}
+
+		h, err := handler.NewHandler(ctx, linkService, analytics, apiKeys, limiter, metrics, logger, &cfg)
 		if err != nil {
 			logger.Fatal("Ошибка инициализации обработчика")
 		}
 
-		app := fiber.New()
+		// Тела читаются потоком ради пакетов NDJSON; лимит BodyLimit для остальных запросов проверяет обработчик
+		app := fiber.New(fiber.Config{ErrorHandler: h.ErrorHandler, StreamRequestBody: true})
 		h.InitRoutes(app)
 
-		errBot := bot.StartBot(ctx, &cfg, linkService, kafkaProducer, metrics, logger)
+		errBot := bot.StartBot(ctx, &cfg, linkService, limiter, kafkaProducer, metrics, logger)
 		if errBot != nil {
-			logger.Error("Ошибка инициализации telebot %s", errBot)
+			logger.Errorf("Ошибка инициализации telebot %s", errBot)
 		}
 
 		go func() {
@@ -126,18 +204,59 @@ var shortenCmd = &cobra.Command{
 			}
 		}()
 
-		//
-		go linkService.CleanupOldLinks(logger)
+		go func() {
+			err := clickConsumer.ConsumeClicks()
+			if err != nil {
+				logger.Errorf(err.Error())
+			}
+		}()
+
+		go analytics.Run(logger)
+
+		if kafkaProducer != nil && db != nil {
+			relay := service.NewOutboxRelay(ctx, postgres.NewPostgresOutboxRepository(db), kafkaProducer, metrics,
+				cfg.Kafka.OutboxInterval, cfg.Kafka.OutboxBatchSize)
+			go relay.Run(logger)
+		}
+
+		go linkService.CleanupExpiredLinks(logger)
+		go linkService.RetryInvalidations(logger)
 
 		quit := make(chan os.Signal, 1)
 		signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 
-		go logger.Fatal(app.Listen(":8080"))
+		go func() {
+			if err := app.Listen(":8080"); err != nil {
+				logger.Fatal(err)
+			}
+		}()
+
+		grpcServer := grpcserver.NewServer(linkService, analytics, apiKeys, limiter, metrics, logger, &cfg)
+		if cfg.Server.GRPCAddr != "" {
+			listener, err := net.Listen("tcp", cfg.Server.GRPCAddr)
+			if err != nil {
+				logger.WithError(err).Fatal("Ошибка запуска gRPC-сервера")
+			}
+			go func() {
+				if err := grpcServer.Serve(listener); err != nil {
+					logger.WithError(err).Error("gRPC-сервер остановлен с ошибкой")
+				}
+			}()
+			logger.WithField("addr", cfg.Server.GRPCAddr).Info("gRPC-сервер запущен")
+		}
 
 		<-quit
 		kafkaConsumer.CloseKafka()
 
+		// Незавершённые вызовы gRPC дорабатывают параллельно с остановкой HTTP-сервера
+		grpcStopped := make(chan struct{})
+		go func() {
+			grpcServer.GracefulStop()
+			close(grpcStopped)
+		}()
+
 		app.Shutdown()
+		<-grpcStopped
 
 		logger.WithField("component", "shorten").Info("Сервер успешно остановлен")
 	},
@@ -1,8 +1,10 @@
 package bot
 
 import (
+	"bytes"
 	"context"
 	"crypto/tls"
+	"errors"
 	"fmt"
 	"github.com/IBM/sarama"
 	"github.com/sirupsen/logrus"
@@ -10,7 +12,9 @@ import (
 	"linkreduction/internal/config"
 	initprometheus "linkreduction/internal/prometheus"
 	"linkreduction/internal/service"
+	"math"
 	"net/http"
+	"strings"
 	"time"
 )
 
@@ -19,12 +23,13 @@ type Bot struct {
 	cfg      *config.Config
 	bot      *tele.Bot
 	service  *service.Service
+	limiter  *service.RateLimiter
 	producer sarama.SyncProducer
 	metrics  *initprometheus.PrometheusMetrics
 	logger   *logrus.Logger
 }
 
-func StartBot(ctx context.Context, cfg *config.Config, service *service.Service, producer sarama.SyncProducer, metrics *initprometheus.PrometheusMetrics, logger *logrus.Logger) error {
+func StartBot(ctx context.Context, cfg *config.Config, service *service.Service, limiter *service.RateLimiter, producer sarama.SyncProducer, metrics *initprometheus.PrometheusMetrics, logger *logrus.Logger) error {
 	httpClient := &http.Client{
 		Transport: &http.Transport{
 			TLSClientConfig: &tls.Config{
@@ -45,7 +50,7 @@ func StartBot(ctx context.Context, cfg *config.Config, service *service.Service,
 		return err
 	}
 
-	b := &Bot{ctx, cfg, newBot, service, producer, metrics, logger}
+	b := &Bot{ctx, cfg, newBot, service, limiter, producer, metrics, logger}
 	b.registerHandlers()
 	go newBot.Start()
 	return nil
@@ -56,18 +61,52 @@ func (b *Bot) registerHandlers() {
 		return c.Send("Я помогу тебе превратить любую длинную ссылку в короткую " +
 			"🔗\n\nПросто отправь мне свой URL, и я создам сокращённый адрес, " +
 			"который можно использовать где угодно — в соцсетях, мессенджерах, на сайтах. " +
-			"При переходе по нему пользователь будет перенаправлен на исходную страницу.")
+			"При переходе по нему пользователь будет перенаправлен на исходную страницу.\n\n" +
+			"Через пробел после ссылки можно указать срок её жизни: например, " +
+			"«https://example.com 24h» или «https://example.com 7d». «0» — бессрочная ссылка.")
 	})
 
 	b.bot.Handle(tele.OnText, b.handleShortenRequest)
 }
 
-func (b *Bot) handleShortenRequest(c tele.Context) error {
-	originalURL := c.Text()
+// parseShortenMessage разбирает сообщение вида "<url> [ttl]".
+func parseShortenMessage(text string) (string, service.ShortenOptions, error) {
+	var opts service.ShortenOptions
+
+	fields := strings.Fields(text)
+	switch len(fields) {
+	case 0:
+		return "", opts, fmt.Errorf("отправьте ссылку, которую нужно сократить")
+	case 1:
+	case 2:
+		ttl, err := service.ParseTTL(fields[1])
+		if err != nil {
+			return "", opts, err
+		}
+		opts.TTL = &ttl
+	default:
+		return "", opts, fmt.Errorf("ожидается сообщение вида «<ссылка> [срок жизни]»")
+	}
 
+	return fields[0], opts, nil
+}
+
+func (b *Bot) handleShortenRequest(c tele.Context) error {
 	baseURL := b.cfg.Server.BaseURL
 
-	shortLink, err := b.service.ShortenURL(b.ctx, originalURL, baseURL)
+	if err := b.checkRateLimit(c); err != nil {
+		return err
+	}
+
+	originalURL, opts, err := parseShortenMessage(c.Text())
+	if err != nil {
+		if err := c.Send(err.Error()); err != nil {
+			b.logger.Error(err)
+		}
+		return err
+	}
+
+	link, err := b.service.ShortenURL(b.ctx, originalURL, baseURL, opts)
 	if err != nil {
 		err := c.Send(err.Error())
 		if err != nil {
@@ -76,7 +115,7 @@ func (b *Bot) handleShortenRequest(c tele.Context) error {
 		return fmt.Errorf("shorten URL: %w", err)
 	}
 
-	err = b.service.SendMessageToDB(originalURL, shortLink)
+	link, err = b.service.SaveLink(b.ctx, link)
 	if err != nil {
 		err := c.Send(err.Error())
 		if err != nil {
@@ -85,7 +124,7 @@ func (b *Bot) handleShortenRequest(c tele.Context) error {
 		return err
 	}
 
-	shortURL := fmt.Sprintf("%s/%s", baseURL, shortLink)
+	shortURL := fmt.Sprintf("%s/%s", baseURL, link.ShortLink)
 
 	err = c.Send(shortURL)
 	if err != nil {
@@ -93,5 +132,35 @@ func (b *Bot) handleShortenRequest(c tele.Context) error {
 		return err
 	}
 
+	// QR-код — дополнение к ссылке: ошибка его отправки не считается ошибкой запроса
+	qr, err := service.QRCode(shortURL, service.QROptions{Size: 512})
+	if err != nil {
+		b.logger.Error(err)
+		return nil
+	}
+	if err := c.Send(&tele.Photo{File: tele.FromReader(bytes.NewReader(qr)), Caption: shortURL}); err != nil {
+		b.logger.Error(err)
+	}
+
+	return nil
+}
+
+// checkRateLimit ограничивает частоту запросов пользователя Telegram.
+func (b *Bot) checkRateLimit(c tele.Context) error {
+	if b.limiter == nil || c.Sender() == nil {
+		return nil
+	}
+
+	retryAfter, err := b.limiter.Allow(b.ctx, service.RateLimitBot, fmt.Sprintf("tg:%d", c.Sender().ID))
+	if errors.Is(err, service.ErrRateLimited) {
+		seconds := int(math.Ceil(retryAfter.Seconds()))
+		if err := c.Send(fmt.Sprintf("Слишком много запросов, попробуйте через %d с.", max(seconds, 1))); err != nil {
+			b.logger.Error(err)
+		}
+		return err
+	}
+	if err != nil {
+		b.logger.Warn(err)
+	}
 	return nil
 }
@@ -3,14 +3,21 @@ package config
 import (
 	"fmt"
 	"github.com/spf13/viper"
+	"time"
 )
 
 type Config struct {
 	DB         DBC        `mapstructure:"db"`
+	Storage    Storage    `mapstructure:"storage"`
 	Server     Server     `mapstructure:"server"`
 	Redis      Redis      `mapstructure:"redis"`
 	Kafka      Kafka      `mapstructure:"kafka"`
 	Prometheus Prometheus `mapstructure:"prometheus"`
+	Links      Links      `mapstructure:"links"`
+	Analytics  Analytics  `mapstructure:"analytics"`
+	Auth       Auth       `mapstructure:"auth"`
+	RateLimit  RateLimit  `mapstructure:"rate_limit"`
+	Policy     Policy     `mapstructure:"policy"`
 	BotToken   string     `mapstructure:"bot_token"`
 	Version    string     `mapstructure:"version"`
 }
@@ -20,24 +27,179 @@ type DBC struct {
 	LinksDB    string `mapstructure:"linksdb_dsn"`
 	Name       string `mapstructure:"name"`
 	Migrations string `mapstructure:"migrations"`
+	// Параметры пула соединений pgxpool; 0 — значения по умолчанию.
+	MaxConns int32 `mapstructure:"max_conns"`
+	// MinConns — число соединений, которые пул держит открытыми даже без нагрузки.
+	MinConns          int32         `mapstructure:"min_conns"`
+	ConnMaxLifetime   time.Duration `mapstructure:"conn_max_lifetime"`
+	ConnMaxIdleTime   time.Duration `mapstructure:"conn_max_idle_time"`
+	HealthCheckPeriod time.Duration `mapstructure:"health_check_period"`
+	// ReplicaDSNs — реплики для чтения ссылок хранилищем postgres; пусто — всё читается с primary.
+	ReplicaDSNs []string `mapstructure:"replica_dsns"`
+	// MaxReplicaLag — допустимое отставание реплики; отстающая реплика не используется до следующей проверки.
+	MaxReplicaLag        time.Duration `mapstructure:"max_replica_lag"`
+	ReplicaCheckInterval time.Duration `mapstructure:"replica_check_interval"`
+}
+
+// Storage выбирает хранилище ссылок: postgres (по умолчанию), sqlite или memory. При sqlite и memory
+// Postgres нужен только для API-ключей и переходов; без db.linksdb_dsn они хранятся в памяти процесса.
+// Kafka работает только с postgres: остальные хранилища не поддерживают outbox.
+type Storage struct {
+	Driver string `mapstructure:"driver"`
+	// SQLitePath — путь к файлу базы для драйвера sqlite.
+	SQLitePath string `mapstructure:"sqlite_path"`
 }
 
 type Server struct {
 	BaseURL string `mapstructure:"base_url"`
+	// GRPCAddr — адрес gRPC-сервера LinkService, например ":9090"; пусто — gRPC отключён.
+	GRPCAddr string `mapstructure:"grpc_addr"`
 }
 
+// Redis — кэш и счётчики лимитов. Пустой URL запускает сервис без Redis: без кэша и лимитов запросов.
 type Redis struct {
+	// URL — адрес Redis; для Sentinel и Cluster — адреса узлов через запятую.
 	URL string `mapstructure:"url"`
+	// MasterName включает режим Sentinel: URL содержит адреса sentinel-узлов, MasterName — имя мастера.
+	MasterName string `mapstructure:"master_name"`
+	// Cluster включает режим Redis Cluster.
+	Cluster          bool   `mapstructure:"cluster"`
+	Username         string `mapstructure:"username"`
+	Password         string `mapstructure:"password"`
+	SentinelUsername string `mapstructure:"sentinel_username"`
+	SentinelPassword string `mapstructure:"sentinel_password"`
+	// DB — номер базы; в режиме Cluster допустим только 0.
+	DB  int      `mapstructure:"db"`
+	TLS RedisTLS `mapstructure:"tls"`
+	// Параметры пула и таймауты; 0 — значения go-redis по умолчанию.
+	PoolSize     int           `mapstructure:"pool_size"`
+	MinIdleConns int           `mapstructure:"min_idle_conns"`
+	PoolTimeout  time.Duration `mapstructure:"pool_timeout"`
+	DialTimeout  time.Duration `mapstructure:"dial_timeout"`
+	ReadTimeout  time.Duration `mapstructure:"read_timeout"`
+	WriteTimeout time.Duration `mapstructure:"write_timeout"`
+	// Required останавливает запуск, если Redis недоступен; иначе соединение устанавливается лениво.
+	Required   bool         `mapstructure:"required"`
+	Breaker    RedisBreaker `mapstructure:"breaker"`
+	LocalCache LocalCache   `mapstructure:"local_cache"`
+}
+
+// RedisTLS включает TLS для соединений с Redis. CAFile задаёт собственный корневой сертификат,
+// CertFile и KeyFile — клиентский сертификат.
+type RedisTLS struct {
+	Enabled            bool   `mapstructure:"enabled"`
+	CAFile             string `mapstructure:"ca_file"`
+	CertFile           string `mapstructure:"cert_file"`
+	KeyFile            string `mapstructure:"key_file"`
+	ServerName         string `mapstructure:"server_name"`
+	InsecureSkipVerify bool   `mapstructure:"insecure_skip_verify"`
+}
+
+// RedisBreaker настраивает автомат защиты: после Failures ошибок подряд команды Redis
+// пропускаются на Cooldown, затем пробная команда проверяет соединение.
+type RedisBreaker struct {
+	Failures int           `mapstructure:"failures"`
+	Cooldown time.Duration `mapstructure:"cooldown"`
+}
+
+// LocalCache настраивает LRU-кэш ссылок в памяти процесса перед Redis.
+type LocalCache struct {
+	// MaxEntries — максимальное число записей; 0 — кэш отключён.
+	MaxEntries int `mapstructure:"max_entries"`
+	// TTL — предельное время жизни записи, ограничивает устаревание при потере сообщений об инвалидации.
+	TTL time.Duration `mapstructure:"ttl"`
 }
 
 type Kafka struct {
 	Brokers string `mapstructure:"brokers"`
+	// OutboxInterval — период опроса outbox для публикации сообщений о новых ссылках.
+	OutboxInterval  time.Duration `mapstructure:"outbox_interval"`
+	OutboxBatchSize int           `mapstructure:"outbox_batch_size"`
+	// BatchSize — максимальное число сообщений shorten-urls, записываемых в базу одной пачкой.
+	BatchSize int `mapstructure:"batch_size"`
+	// Retry — повторы записи пачки из shorten-urls, после которых сообщения отправляются в DLQ.
+	Retry KafkaRetry `mapstructure:"retry"`
+}
+
+type KafkaRetry struct {
+	MaxAttempts int `mapstructure:"max_attempts"`
+	// InitialBackoff удваивается после каждой неудачной попытки, но не превышает MaxBackoff.
+	InitialBackoff time.Duration `mapstructure:"initial_backoff"`
+	MaxBackoff     time.Duration `mapstructure:"max_backoff"`
 }
 
 type Prometheus struct {
 	URL string `mapstructure:"url"`
 }
 
+type Auth struct {
+	// Required запрещает анонимное создание ссылок: без API-ключа запросы получают 401.
+	Required bool `mapstructure:"required"`
+	// LinkCookieSecret подписывает cookie доступа к ссылкам с паролем; должен совпадать на всех экземплярах.
+	LinkCookieSecret string        `mapstructure:"link_cookie_secret"`
+	LinkCookieTTL    time.Duration `mapstructure:"link_cookie_ttl"`
+}
+
+// RateLimit задаёт лимиты запросов по областям. Лимит считается в скользящем окне
+// отдельно для каждого клиента: API-ключа, IP или пользователя Telegram.
+type RateLimit struct {
+	Create   RateLimitRule `mapstructure:"create"`
+	Redirect RateLimitRule `mapstructure:"redirect"`
+	Bot      RateLimitRule `mapstructure:"bot"`
+	// Auth ограничивает по IP запросы с API-ключом до его проверки в базе.
+	Auth RateLimitRule `mapstructure:"auth"`
+}
+
+type RateLimitRule struct {
+	// Requests — допустимое число запросов за Window; 0 — без ограничений.
+	Requests int           `mapstructure:"requests"`
+	Window   time.Duration `mapstructure:"window"`
+}
+
+// Policy ограничивает адреса, на которые можно создавать короткие ссылки.
+type Policy struct {
+	AllowIPLiterals      bool `mapstructure:"allow_ip_literals"`
+	AllowPrivateNetworks bool `mapstructure:"allow_private_networks"`
+	// ResolveHosts проверяет, что домен не разрешается в адрес частной сети.
+	ResolveHosts   bool     `mapstructure:"resolve_hosts"`
+	BlockedDomains []string `mapstructure:"blocked_domains"`
+	// AllowedDomains, если не пуст, разрешает только перечисленные домены.
+	AllowedDomains []string `mapstructure:"allowed_domains"`
+	// BlocklistFile перечитывается раз в BlocklistReloadInterval, если файл изменился.
+	BlocklistFile           string        `mapstructure:"blocklist_file"`
+	BlocklistReloadInterval time.Duration `mapstructure:"blocklist_reload_interval"`
+	// Shorteners — домены других сокращателей; если не задан, используется встроенный список.
+	Shorteners      []string      `mapstructure:"shorteners"`
+	FollowRedirects bool          `mapstructure:"follow_redirects"`
+	MaxRedirects    int           `mapstructure:"max_redirects"`
+	RedirectTimeout time.Duration `mapstructure:"redirect_timeout"`
+	// BatchCheckBudget — общее время сетевых проверок адресов одного пакета.
+	BatchCheckBudget time.Duration `mapstructure:"batch_check_budget"`
+}
+
+type Analytics struct {
+	// IPSalt добавляется к IP клиента перед хэшированием, чтобы хэши нельзя было сопоставить с адресами.
+	IPSalt string `mapstructure:"ip_salt"`
+}
+
+type Links struct {
+	// DefaultTTL — время жизни ссылки, если срок не указан при создании; 0 — бессрочно.
+	DefaultTTL      time.Duration `mapstructure:"default_ttl"`
+	CleanupInterval time.Duration `mapstructure:"cleanup_interval"`
+	// KeyGenerator — стратегия генерации ключей: hash, random или sequence.
+	KeyGenerator   string `mapstructure:"key_generator"`
+	KeyLength      int    `mapstructure:"key_length"`
+	KeyMaxAttempts int    `mapstructure:"key_max_attempts"`
+	// BatchMaxItems ограничивает число URL в одном запросе к /api/links/batch.
+	BatchMaxItems int `mapstructure:"batch_max_items"`
+	// RedirectCode — код перенаправления по умолчанию: 301, 302, 307 или 308.
+	RedirectCode int `mapstructure:"redirect_code"`
+	// TitleTimeout ограничивает загрузку заголовка страницы для предпросмотра.
+	TitleTimeout time.Duration `mapstructure:"title_timeout"`
+	// NegativeCacheTTL — сколько кэшируется отсутствие ссылки, чтобы перебор ключей не нагружал базу.
+	NegativeCacheTTL time.Duration `mapstructure:"negative_cache_ttl"`
+}
+
 func LoadConfig(path string) (cfg Config, err error) {
 	viper.SetConfigFile(path)
 	viper.SetConfigType("yaml")
@@ -1,11 +1,48 @@
 package message
 
+import (
+	"linkreduction/internal/models"
+	"time"
+)
+
 const (
 	ShortenURLsTopic = "shorten-urls"
 	ShortenURLsGroup = "shorten-urls-group"
+
+	// ShortenURLsDLQTopic получает сообщения shorten-urls, которые не удалось обработать.
+	ShortenURLsDLQTopic       = "shorten-urls.dlq"
+	ShortenURLsDLQReplayGroup = "shorten-urls-dlq-replay"
+
+	ClicksTopic = "link-clicks"
+	ClicksGroup = "link-clicks-group"
 )
 
 type ShortenMessage struct {
-	OriginalURL string `json:"original_url"`
-	ShortLink   string `json:"short_link"`
+	OriginalURL  string     `json:"original_url"`
+	ShortLink    string     `json:"short_link"`
+	Custom       bool       `json:"custom,omitempty"`
+	ExpiresAt    *time.Time `json:"expires_at,omitempty"`
+	OwnerID      string     `json:"owner_id,omitempty"`
+	RedirectCode int        `json:"redirect_code,omitempty"`
+	PasswordHash string     `json:"password_hash,omitempty"`
+	Interstitial bool       `json:"interstitial,omitempty"`
+}
+
+func NewShortenMessage(link models.LinkURL) ShortenMessage {
+	return ShortenMessage{OriginalURL: link.OriginalURL, ShortLink: link.ShortLink, Custom: link.Custom,
+		ExpiresAt: link.ExpiresAt, OwnerID: link.OwnerID, RedirectCode: link.RedirectCode, PasswordHash: link.PasswordHash,
+		Interstitial: link.Interstitial}
+}
+
+func (m ShortenMessage) Link() models.LinkURL {
+	return models.LinkURL{OriginalURL: m.OriginalURL, ShortLink: m.ShortLink, Custom: m.Custom, ExpiresAt: m.ExpiresAt,
+		OwnerID: m.OwnerID, RedirectCode: m.RedirectCode, PasswordHash: m.PasswordHash, Interstitial: m.Interstitial}
+}
+
+type ClickMessage struct {
+	ShortLink string    `json:"short_link"`
+	ClickedAt time.Time `json:"clicked_at"`
+	Referrer  string    `json:"referrer,omitempty"`
+	UserAgent string    `json:"user_agent,omitempty"`
+	IPHash    string    `json:"ip_hash,omitempty"`
 }
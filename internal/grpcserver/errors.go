@@ -0,0 +1,75 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	linkv1 "linkreduction/api/link/v1"
+	"linkreduction/internal/service"
+)
+
+// statusError преобразует ошибку сервиса в статус gRPC по её категории. Подробности
+// непредвиденных ошибок клиенту не передаются, а только пишутся в лог.
+func (s *Server) statusError(err error) error {
+	code := statusCode(err)
+	switch code {
+	case codes.Internal, codes.Unavailable:
+		s.logger.WithField("code", code.String()).Error(err)
+	default:
+		s.logger.WithField("code", code.String()).Warn(err)
+	}
+	return status.Error(code, publicMessage(code, err))
+}
+
+// setItemError записывает в результат элемента пакета код и описание ошибки, скрывая
+// подробности непредвиденных ошибок так же, как statusError.
+func (s *Server) setItemError(result *linkv1.BatchShortenResult, err error) {
+	code := statusCode(err)
+	result.Code, result.Error = service.ErrorCode(err), publicMessage(code, err)
+	switch code {
+	case codes.Internal:
+		result.Code = "internal"
+		s.logger.WithFields(logrus.Fields{"code": code.String(), "index": result.Index}).Error(err)
+	case codes.Unavailable:
+		s.logger.WithFields(logrus.Fields{"code": code.String(), "index": result.Index}).Error(err)
+	}
+}
+
+// publicMessage возвращает текст ошибки для клиента.
+func publicMessage(code codes.Code, err error) string {
+	switch code {
+	case codes.Internal:
+		return "внутренняя ошибка сервера"
+	case codes.Unavailable:
+		return service.ErrUnavailable.Message
+	}
+	return err.Error()
+}
+
+// statusCode возвращает код gRPC для категории ошибки сервиса; неизвестные ошибки — Internal.
+func statusCode(err error) codes.Code {
+	code := codes.Internal
+	switch {
+	case errors.Is(err, service.ErrInvalidURL), errors.Is(err, service.ErrInvalidInput):
+		code = codes.InvalidArgument
+	case errors.Is(err, service.ErrUnauthorized):
+		code = codes.Unauthenticated
+	case errors.Is(err, service.ErrNotFound):
+		code = codes.NotFound
+	case errors.Is(err, service.ErrConflict):
+		code = codes.AlreadyExists
+	case errors.Is(err, service.ErrExpired):
+		code = codes.FailedPrecondition
+	case errors.Is(err, service.ErrRateLimited):
+		code = codes.ResourceExhausted
+	case errors.Is(err, service.ErrUnavailable):
+		code = codes.Unavailable
+	case errors.Is(err, context.Canceled):
+		code = codes.Canceled
+	case errors.Is(err, context.DeadlineExceeded):
+		code = codes.DeadlineExceeded
+	}
+	return code
+}
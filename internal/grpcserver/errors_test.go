@@ -0,0 +1,53 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"linkreduction/internal/service"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestServer_StatusError(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	s := &Server{logger: logger}
+
+	tests := []struct {
+		name    string
+		err     error
+		code    codes.Code
+		message string
+	}{
+		{name: "invalid url", err: fmt.Errorf("%w: ftp://x", service.ErrInvalidURL), code: codes.InvalidArgument, message: "некорректный URL: ftp://x"},
+		{name: "invalid input", err: service.ErrInvalidTTL, code: codes.InvalidArgument, message: service.ErrInvalidTTL.Message},
+		{name: "unauthorized", err: service.ErrUnauthorized, code: codes.Unauthenticated, message: service.ErrUnauthorized.Message},
+		{name: "not found", err: service.ErrLinkNotFound, code: codes.NotFound, message: service.ErrLinkNotFound.Message},
+		{name: "conflict", err: service.ErrAliasTaken, code: codes.AlreadyExists, message: service.ErrAliasTaken.Message},
+		{name: "expired", err: service.ErrLinkExpired, code: codes.FailedPrecondition, message: service.ErrLinkExpired.Message},
+		{name: "rate limited", err: service.ErrRateLimited, code: codes.ResourceExhausted, message: service.ErrRateLimited.Message},
+		{name: "unavailable hides details", err: fmt.Errorf("%w: redis: connection refused", service.ErrUnavailable),
+			code: codes.Unavailable, message: service.ErrUnavailable.Message},
+		{name: "canceled", err: fmt.Errorf("ошибка базы данных: %w", context.Canceled), code: codes.Canceled},
+		{name: "deadline exceeded", err: context.DeadlineExceeded, code: codes.DeadlineExceeded},
+		{name: "unexpected error hides details", err: errors.New("pq: password authentication failed"),
+			code: codes.Internal, message: "внутренняя ошибка сервера"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st, ok := status.FromError(s.statusError(tt.err))
+			assert.True(t, ok)
+			assert.Equal(t, tt.code, st.Code())
+			if tt.message != "" {
+				assert.Equal(t, tt.message, st.Message())
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	linkv1 "linkreduction/api/link/v1"
+	"linkreduction/internal/repository/redis"
+	"linkreduction/internal/service"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+)
+
+type ownerKey struct{}
+
+type apiKeyIDKey struct{}
+
+// rateLimitScopes — области лимитов методов; методы без области не ограничиваются.
+var rateLimitScopes = map[string]string{
+	linkv1.LinkService_Shorten_FullMethodName:      service.RateLimitCreate,
+	linkv1.LinkService_BatchShorten_FullMethodName: service.RateLimitCreate,
+	linkv1.LinkService_Resolve_FullMethodName:      service.RateLimitRedirect,
+}
+
+// authenticate проверяет API-ключ из метаданных x-api-key или authorization: Bearer
+// и сохраняет владельца в контексте. Запросы без ключа проходят анонимно, если
+// создание ссылок не требует авторизации.
+func (s *Server) authenticate(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if plain := apiKeyFromMetadata(ctx); plain != "" {
+		key, err := s.apiKeys.Authenticate(ctx, plain)
+		if err != nil {
+			return nil, s.statusError(err)
+		}
+		ctx = context.WithValue(ctx, ownerKey{}, key.OwnerID)
+		ctx = context.WithValue(ctx, apiKeyIDKey{}, key.ID)
+	}
+
+	if s.cfg.Auth.Required && ownerID(ctx) == "" && rateLimitScopes[info.FullMethod] == service.RateLimitCreate {
+		return nil, status.Error(codes.Unauthenticated, "требуется API-ключ")
+	}
+
+	return handler(ctx, req)
+}
+
+// rateLimit ограничивает частоту вызовов по тем же областям, что и HTTP API. Время до
+// освобождения окна передаётся в заголовке retry-after. Сбой хранилища лимитов не блокирует запрос.
+func (s *Server) rateLimit(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	scope, ok := rateLimitScopes[info.FullMethod]
+	if !ok {
+		return handler(ctx, req)
+	}
+	if err := s.allow(ctx, scope, clientKey(ctx)); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// limitAuth ограничивает по IP вызовы с API-ключом до его проверки в authenticate.
+func (s *Server) limitAuth(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if apiKeyFromMetadata(ctx) != "" {
+		if err := s.allow(ctx, service.RateLimitAuth, "ip:"+peerIP(ctx)); err != nil {
+			return nil, err
+		}
+	}
+	return handler(ctx, req)
+}
+
+func (s *Server) allow(ctx context.Context, scope, client string) error {
+	retryAfter, err := s.limiter.Allow(ctx, scope, client)
+	if errors.Is(err, service.ErrRateLimited) {
+		seconds := int(math.Ceil(retryAfter.Seconds()))
+		_ = grpc.SetHeader(ctx, metadata.Pairs("retry-after", strconv.Itoa(max(seconds, 1))))
+		return status.Error(codes.ResourceExhausted, err.Error())
+	}
+	// Пока автомат защиты Redis разомкнут, каждый запрос получал бы то же предупреждение;
+	// о размыкании сообщает сам автомат
+	if err != nil && !errors.Is(err, redis.ErrCircuitOpen) {
+		s.logger.WithField("scope", scope).Warn(err)
+	}
+	return nil
+}
+
+// ownerID возвращает владельца, определённый перехватчиком authenticate.
+func ownerID(ctx context.Context) string {
+	owner, _ := ctx.Value(ownerKey{}).(string)
+	return owner
+}
+
+// clientKey определяет клиента для лимита: API-ключ, если он передан, иначе IP.
+func clientKey(ctx context.Context) string {
+	if id, ok := ctx.Value(apiKeyIDKey{}).(int64); ok {
+		return fmt.Sprintf("apikey:%d", id)
+	}
+	return "ip:" + peerIP(ctx)
+}
+
+func peerIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
+
+func apiKeyFromMetadata(ctx context.Context) string {
+	if values := metadata.ValueFromIncomingContext(ctx, "x-api-key"); len(values) > 0 && values[0] != "" {
+		return values[0]
+	}
+	for _, value := range metadata.ValueFromIncomingContext(ctx, "authorization") {
+		if token, ok := strings.CutPrefix(value, "Bearer "); ok {
+			return strings.TrimSpace(token)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,244 @@
+package grpcserver
+
+import (
+	"context"
+	"fmt"
+	linkv1 "linkreduction/api/link/v1"
+	"linkreduction/internal/models"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const testAPIKey = "lr_0123456789abcdef"
+
+func scopeKey(scope string) any {
+	return mock.MatchedBy(func(key string) bool { return strings.HasPrefix(key, "ratelimit:"+scope+":") })
+}
+
+func TestServer_Authenticate(t *testing.T) {
+	stats := &models.LinkStats{ShortLink: "abc", TotalClicks: 3}
+
+	tests := []struct {
+		name     string
+		md       metadata.MD
+		required bool
+		setup    func(deps *testDeps)
+		call     func(client linkv1.LinkServiceClient, ctx context.Context) error
+		code     codes.Code
+	}{
+		{
+			name: "x-api-key sets owner",
+			md:   metadata.Pairs("x-api-key", testAPIKey),
+			setup: func(deps *testDeps) {
+				deps.keys.On("FindAPIKeyByHash", mock.Anything, mock.Anything).Return(&models.APIKey{ID: 1, OwnerID: "alice"}, nil)
+				deps.repo.On("FindLink", mock.Anything, "abc").Return(&models.LinkURL{ShortLink: "abc", OwnerID: "alice"}, nil)
+				deps.clicks.On("GetStats", mock.Anything, "abc", mock.Anything, mock.Anything, mock.Anything).Return(stats, nil)
+			},
+			call: func(client linkv1.LinkServiceClient, ctx context.Context) error {
+				_, err := client.GetStats(ctx, &linkv1.GetStatsRequest{ShortLink: "abc"})
+				return err
+			},
+			code: codes.OK,
+		},
+		{
+			name: "bearer token sets owner",
+			md:   metadata.Pairs("authorization", "Bearer "+testAPIKey),
+			setup: func(deps *testDeps) {
+				deps.keys.On("FindAPIKeyByHash", mock.Anything, mock.Anything).Return(&models.APIKey{ID: 1, OwnerID: "alice"}, nil)
+				deps.repo.On("FindLink", mock.Anything, "abc").Return(&models.LinkURL{ShortLink: "abc", OwnerID: "alice"}, nil)
+				deps.clicks.On("GetStats", mock.Anything, "abc", mock.Anything, mock.Anything, mock.Anything).Return(stats, nil)
+			},
+			call: func(client linkv1.LinkServiceClient, ctx context.Context) error {
+				_, err := client.GetStats(ctx, &linkv1.GetStatsRequest{ShortLink: "abc"})
+				return err
+			},
+			code: codes.OK,
+		},
+		{
+			name: "unknown key",
+			md:   metadata.Pairs("x-api-key", testAPIKey),
+			setup: func(deps *testDeps) {
+				deps.keys.On("FindAPIKeyByHash", mock.Anything, mock.Anything).Return(nil, nil)
+			},
+			call: func(client linkv1.LinkServiceClient, ctx context.Context) error {
+				_, err := client.GetStats(ctx, &linkv1.GetStatsRequest{ShortLink: "abc"})
+				return err
+			},
+			code: codes.Unauthenticated,
+		},
+		{
+			name: "revoked key",
+			md:   metadata.Pairs("x-api-key", testAPIKey),
+			setup: func(deps *testDeps) {
+				revoked := time.Now()
+				deps.keys.On("FindAPIKeyByHash", mock.Anything, mock.Anything).
+					Return(&models.APIKey{ID: 1, OwnerID: "alice", RevokedAt: &revoked}, nil)
+			},
+			call: func(client linkv1.LinkServiceClient, ctx context.Context) error {
+				_, err := client.GetStats(ctx, &linkv1.GetStatsRequest{ShortLink: "abc"})
+				return err
+			},
+			code: codes.Unauthenticated,
+		},
+		{
+			name: "key lookup error",
+			md:   metadata.Pairs("x-api-key", testAPIKey),
+			setup: func(deps *testDeps) {
+				deps.keys.On("FindAPIKeyByHash", mock.Anything, mock.Anything).Return(nil, fmt.Errorf("db down"))
+			},
+			call: func(client linkv1.LinkServiceClient, ctx context.Context) error {
+				_, err := client.GetStats(ctx, &linkv1.GetStatsRequest{ShortLink: "abc"})
+				return err
+			},
+			code: codes.Internal,
+		},
+		{
+			name: "stats without key",
+			call: func(client linkv1.LinkServiceClient, ctx context.Context) error {
+				_, err := client.GetStats(ctx, &linkv1.GetStatsRequest{ShortLink: "abc"})
+				return err
+			},
+			code: codes.Unauthenticated,
+		},
+		{
+			name:     "anonymous shorten when auth is required",
+			required: true,
+			call: func(client linkv1.LinkServiceClient, ctx context.Context) error {
+				_, err := client.Shorten(ctx, &linkv1.ShortenRequest{Url: "https://example.com"})
+				return err
+			},
+			code: codes.Unauthenticated,
+		},
+		{
+			name:     "anonymous resolve when auth is required",
+			required: true,
+			setup: func(deps *testDeps) {
+				deps.cache.On("GetRedirect", mock.Anything, "abc").
+					Return(&models.Redirect{URL: "https://example.com", Code: http.StatusFound}, nil)
+			},
+			call: func(client linkv1.LinkServiceClient, ctx context.Context) error {
+				_, err := client.Resolve(ctx, &linkv1.ResolveRequest{ShortLink: "abc"})
+				return err
+			},
+			code: codes.OK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps := newTestDeps()
+			deps.cfg.Auth.Required = tt.required
+			deps.store.On("Allow", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(true, time.Duration(0), nil)
+			if tt.setup != nil {
+				tt.setup(deps)
+			}
+			client := newTestClient(t, deps)
+
+			ctx := metadata.NewOutgoingContext(context.Background(), tt.md)
+			err := tt.call(client, ctx)
+
+			assert.Equal(t, tt.code, status.Code(err), "%v", err)
+			deps.keys.AssertExpectations(t)
+		})
+	}
+
+	t.Run("key without prefix is rejected without a lookup", func(t *testing.T) {
+		deps := newTestDeps()
+		deps.store.On("Allow", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(true, time.Duration(0), nil)
+		client := newTestClient(t, deps)
+
+		ctx := metadata.NewOutgoingContext(context.Background(), metadata.Pairs("x-api-key", "not-a-key"))
+		_, err := client.GetStats(ctx, &linkv1.GetStatsRequest{ShortLink: "abc"})
+
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+		deps.keys.AssertNotCalled(t, "FindAPIKeyByHash", mock.Anything, mock.Anything)
+	})
+}
+
+func TestServer_RateLimit(t *testing.T) {
+	redirect := &models.Redirect{URL: "https://example.com", Code: http.StatusFound}
+
+	t.Run("limit exceeded", func(t *testing.T) {
+		deps := newTestDeps()
+		deps.store.On("Allow", mock.Anything, scopeKey("redirect"), 10, time.Minute).Return(false, 1500*time.Millisecond, nil)
+		client := newTestClient(t, deps)
+
+		var header metadata.MD
+		_, err := client.Resolve(context.Background(), &linkv1.ResolveRequest{ShortLink: "abc"}, grpc.Header(&header))
+
+		assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+		assert.Equal(t, []string{"2"}, header.Get("retry-after"))
+		deps.cache.AssertNotCalled(t, "GetRedirect", mock.Anything, mock.Anything)
+	})
+
+	t.Run("store failure does not block", func(t *testing.T) {
+		deps := newTestDeps()
+		deps.store.On("Allow", mock.Anything, scopeKey("redirect"), 10, time.Minute).Return(false, time.Duration(0), fmt.Errorf("redis down"))
+		deps.cache.On("GetRedirect", mock.Anything, "abc").Return(redirect, nil)
+		client := newTestClient(t, deps)
+
+		resp, err := client.Resolve(context.Background(), &linkv1.ResolveRequest{ShortLink: "abc"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "https://example.com", resp.GetOriginalUrl())
+	})
+
+	t.Run("create scope is keyed by api key", func(t *testing.T) {
+		deps := newTestDeps()
+		deps.keys.On("FindAPIKeyByHash", mock.Anything, mock.Anything).Return(&models.APIKey{ID: 7, OwnerID: "alice"}, nil)
+		deps.store.On("Allow", mock.Anything, scopeKey("auth"), 10, time.Minute).Return(true, time.Duration(0), nil)
+		deps.store.On("Allow", mock.Anything, "ratelimit:create:apikey:7", 10, time.Minute).Return(false, time.Second, nil)
+		client := newTestClient(t, deps)
+
+		ctx := metadata.NewOutgoingContext(context.Background(), metadata.Pairs("x-api-key", testAPIKey))
+		_, err := client.Shorten(ctx, &linkv1.ShortenRequest{Url: "https://example.com"})
+
+		assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+		deps.store.AssertExpectations(t)
+	})
+
+	t.Run("methods without scope are not limited", func(t *testing.T) {
+		deps := newTestDeps()
+		client := newTestClient(t, deps)
+
+		_, err := client.GetStats(context.Background(), &linkv1.GetStatsRequest{ShortLink: "abc"})
+
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+		deps.store.AssertNotCalled(t, "Allow", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestServer_LimitAuth(t *testing.T) {
+	t.Run("limit exceeded before key lookup", func(t *testing.T) {
+		deps := newTestDeps()
+		deps.store.On("Allow", mock.Anything, scopeKey("auth"), 10, time.Minute).Return(false, time.Second, nil)
+		client := newTestClient(t, deps)
+
+		ctx := metadata.NewOutgoingContext(context.Background(), metadata.Pairs("x-api-key", testAPIKey))
+		_, err := client.GetStats(ctx, &linkv1.GetStatsRequest{ShortLink: "abc"})
+
+		assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+		deps.keys.AssertNotCalled(t, "FindAPIKeyByHash", mock.Anything, mock.Anything)
+	})
+
+	t.Run("calls without key are not counted", func(t *testing.T) {
+		deps := newTestDeps()
+		deps.store.On("Allow", mock.Anything, scopeKey("redirect"), 10, time.Minute).Return(true, time.Duration(0), nil)
+		deps.cache.On("GetRedirect", mock.Anything, "abc").Return(&models.Redirect{URL: "https://example.com", Code: http.StatusFound}, nil)
+		client := newTestClient(t, deps)
+
+		_, err := client.Resolve(context.Background(), &linkv1.ResolveRequest{ShortLink: "abc"})
+
+		assert.NoError(t, err)
+		deps.store.AssertNotCalled(t, "Allow", mock.Anything, scopeKey("auth"), mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,264 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	linkv1 "linkreduction/api/link/v1"
+	"linkreduction/internal/config"
+	"linkreduction/internal/models"
+	"linkreduction/internal/prometheus"
+	"linkreduction/internal/service"
+	"time"
+)
+
+// Server реализует linkv1.LinkService поверх того же *service.Service, что и HTTP-обработчик.
+type Server struct {
+	linkv1.UnimplementedLinkServiceServer
+
+	service   *service.Service
+	analytics *service.Analytics
+	apiKeys   *service.APIKeys
+	limiter   *service.RateLimiter
+	metrics   *initprometheus.PrometheusMetrics
+	logger    *logrus.Logger
+	cfg       *config.Config
+}
+
+// NewServer создаёт gRPC-сервер с зарегистрированным LinkService, проверкой API-ключей
+// и ограничением частоты запросов.
+func NewServer(service *service.Service, analytics *service.Analytics, apiKeys *service.APIKeys,
+	limiter *service.RateLimiter, metrics *initprometheus.PrometheusMetrics, logger *logrus.Logger, cfg *config.Config) *grpc.Server {
+
+	s := &Server{
+		service:   service,
+		analytics: analytics,
+		apiKeys:   apiKeys,
+		limiter:   limiter,
+		metrics:   metrics,
+		logger:    logger,
+		cfg:       cfg,
+	}
+
+	// Лимит по IP применяется до проверки API-ключа, которая обращается к базе
+	interceptors := []grpc.UnaryServerInterceptor{s.authenticate}
+	if limiter != nil {
+		interceptors = []grpc.UnaryServerInterceptor{s.limitAuth, s.authenticate, s.rateLimit}
+	}
+
+	server := grpc.NewServer(grpc.ChainUnaryInterceptor(interceptors...))
+	linkv1.RegisterLinkServiceServer(server, s)
+	return server
+}
+
+func (s *Server) Shorten(ctx context.Context, req *linkv1.ShortenRequest) (*linkv1.ShortenResponse, error) {
+	if req.GetUrl() == "" {
+		return nil, status.Error(codes.InvalidArgument, "URL обязателен")
+	}
+	opts, err := shortenOptions(req)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	opts.OwnerID = ownerID(ctx)
+
+	link, err := s.service.ShortenURL(ctx, req.GetUrl(), s.cfg.Server.BaseURL, opts)
+	if err != nil {
+		return nil, s.statusError(err)
+	}
+
+	link, err = s.service.SaveLink(ctx, link)
+	if err != nil {
+		return nil, s.statusError(err)
+	}
+
+	return &linkv1.ShortenResponse{Link: s.newLink(&link)}, nil
+}
+
+func (s *Server) Resolve(ctx context.Context, req *linkv1.ResolveRequest) (*linkv1.ResolveResponse, error) {
+	shortLink := req.GetShortLink()
+	if shortLink == "" {
+		return nil, status.Error(codes.InvalidArgument, "short_link обязателен")
+	}
+
+	redirect, err := s.service.GetRedirect(ctx, shortLink)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrExpired):
+			s.countRedirect("expired", "none")
+		case errors.Is(err, service.ErrNotFound):
+			s.countRedirect("not_found", "none")
+		default:
+			s.countRedirect("error", "db_query")
+		}
+		return nil, s.statusError(err)
+	}
+
+	if redirect.Protected() {
+		if req.GetPassword() == "" {
+			s.countRedirect("password_required", "none")
+			return nil, status.Error(codes.PermissionDenied, "ссылка защищена паролем")
+		}
+		if !service.VerifyLinkPassword(redirect.PasswordHash, req.GetPassword()) {
+			s.countRedirect("password_required", "none")
+			return nil, status.Error(codes.PermissionDenied, "неверный пароль")
+		}
+	}
+
+	resp := &linkv1.ResolveResponse{
+		OriginalUrl:  redirect.URL,
+		RedirectCode: int32(redirect.Code),
+		Interstitial: redirect.Interstitial,
+	}
+
+	// Как и в HTTP API, переход по ссылке с предпросмотром засчитывается, только когда
+	// посетитель подтвердил его, а не при показе страницы
+	if redirect.Interstitial && !req.GetProceed() {
+		s.countRedirect("interstitial", "none")
+		return resp, nil
+	}
+
+	s.countRedirect("success", "none")
+
+	var userAgent, clientIP string
+	// Сведениям о клиенте из запроса доверяем только у вызовов с API-ключом (например, прокси),
+	// иначе анонимный вызывающий мог бы подменить ими статистику
+	if ownerID(ctx) != "" {
+		userAgent, clientIP = req.GetUserAgent(), req.GetClientIp()
+	}
+	if userAgent == "" {
+		if values := metadata.ValueFromIncomingContext(ctx, "user-agent"); len(values) > 0 {
+			userAgent = values[0]
+		}
+	}
+	if clientIP == "" {
+		clientIP = peerIP(ctx)
+	}
+	s.analytics.RecordClick(shortLink, req.GetReferrer(), userAgent, clientIP)
+
+	return resp, nil
+}
+
+func (s *Server) BatchShorten(ctx context.Context, req *linkv1.BatchShortenRequest) (*linkv1.BatchShortenResponse, error) {
+	if len(req.GetItems()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "пакет не содержит ссылок")
+	}
+
+	results := make([]*linkv1.BatchShortenResult, len(req.GetItems()))
+	items := make([]service.BatchItem, 0, len(req.GetItems()))
+	positions := make([]int, 0, len(req.GetItems()))
+	owner := ownerID(ctx)
+
+	for i, item := range req.GetItems() {
+		results[i] = &linkv1.BatchShortenResult{Index: int32(i), Url: item.GetUrl()}
+
+		if item.GetUrl() == "" {
+			results[i].Code, results[i].Error = "url_required", "URL обязателен"
+			continue
+		}
+		opts, err := shortenOptions(item)
+		if err != nil {
+			s.setItemError(results[i], err)
+			continue
+		}
+		opts.OwnerID = owner
+
+		items = append(items, service.BatchItem{URL: item.GetUrl(), Options: opts})
+		positions = append(positions, i)
+	}
+
+	batchResults, err := s.service.ShortenBatch(ctx, items, s.cfg.Server.BaseURL)
+	if err != nil {
+		return nil, s.statusError(err)
+	}
+
+	for j, result := range batchResults {
+		i := positions[j]
+		if result.Err != nil {
+			s.setItemError(results[i], result.Err)
+			continue
+		}
+		results[i].Link = s.newLink(&result.Link)
+	}
+
+	return &linkv1.BatchShortenResponse{Results: results}, nil
+}
+
+func (s *Server) GetStats(ctx context.Context, req *linkv1.GetStatsRequest) (*linkv1.GetStatsResponse, error) {
+	owner := ownerID(ctx)
+	if owner == "" {
+		return nil, status.Error(codes.Unauthenticated, "требуется API-ключ")
+	}
+
+	link, err := s.service.GetLink(ctx, owner, req.GetShortLink())
+	if err != nil {
+		return nil, s.statusError(err)
+	}
+
+	stats, err := s.analytics.Stats(ctx, link, int(req.GetDays()))
+	if err != nil {
+		return nil, s.statusError(err)
+	}
+
+	resp := &linkv1.GetStatsResponse{
+		ShortLink:      stats.ShortLink,
+		TotalClicks:    stats.TotalClicks,
+		UniqueVisitors: stats.UniqueVisitors,
+	}
+	for _, day := range stats.Daily {
+		resp.Daily = append(resp.Daily, &linkv1.DailyClicks{Date: day.Date, Clicks: day.Clicks})
+	}
+	for _, ref := range stats.TopReferrers {
+		resp.TopReferrers = append(resp.TopReferrers, &linkv1.ReferrerClicks{Referrer: ref.Referrer, Clicks: ref.Clicks})
+	}
+	return resp, nil
+}
+
+func (s *Server) newLink(link *models.LinkURL) *linkv1.Link {
+	resp := &linkv1.Link{
+		ShortLink:    link.ShortLink,
+		ShortUrl:     fmt.Sprintf("%s/%s", s.cfg.Server.BaseURL, link.ShortLink),
+		OriginalUrl:  link.OriginalURL,
+		RedirectCode: int32(link.RedirectCode),
+		Protected:    link.PasswordHash != "",
+		Interstitial: link.Interstitial,
+	}
+	if link.ExpiresAt != nil {
+		resp.ExpiresAt = timestamppb.New(*link.ExpiresAt)
+	}
+	return resp
+}
+
+func (s *Server) countRedirect(status, reason string) {
+	if s.metrics != nil && s.metrics.RedirectTotal != nil {
+		s.metrics.RedirectTotal.WithLabelValues(status, reason).Inc()
+	}
+}
+
+// shortenOptions преобразует запрос в параметры создания ссылки по тем же правилам, что и HTTP API.
+func shortenOptions(req *linkv1.ShortenRequest) (service.ShortenOptions, error) {
+	opts := service.ShortenOptions{Alias: req.GetAlias(), RedirectCode: int(req.GetRedirectCode()),
+		Password: req.GetPassword(), Interstitial: req.GetInterstitial()}
+
+	var expiresAt *time.Time
+	if req.GetExpiresAt() != nil {
+		if err := req.GetExpiresAt().CheckValid(); err != nil {
+			return opts, fmt.Errorf("%w: expires_at: %v", service.ErrInvalidTTL, err)
+		}
+		t := req.GetExpiresAt().AsTime()
+		expiresAt = &t
+	}
+
+	ttl, err := service.ParseExpiry(req.GetTtl(), expiresAt)
+	if err != nil {
+		return opts, err
+	}
+	opts.TTL = ttl
+
+	return opts, nil
+}
@@ -0,0 +1,257 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"io"
+	linkv1 "linkreduction/api/link/v1"
+	"linkreduction/internal/config"
+	"linkreduction/internal/mocks"
+	"linkreduction/internal/models"
+	"linkreduction/internal/service"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// testDeps — зависимости сервера, подменённые моками.
+type testDeps struct {
+	repo   *mocks.LinkRepo
+	cache  *mocks.LinkCache
+	clicks *mocks.ClickRepo
+	keys   *mocks.APIKeyRepo
+	store  *mocks.RateLimitStore
+	cfg    *config.Config
+
+	analytics *service.Analytics
+	// stopAnalytics останавливает запись переходов, см. recordedClicks.
+	stopAnalytics context.CancelFunc
+}
+
+func newTestDeps() *testDeps {
+	return &testDeps{
+		repo:   new(mocks.LinkRepo),
+		cache:  new(mocks.LinkCache),
+		clicks: new(mocks.ClickRepo),
+		keys:   new(mocks.APIKeyRepo),
+		store:  new(mocks.RateLimitStore),
+		cfg:    &config.Config{Server: config.Server{BaseURL: "https://localhost:8080"}},
+	}
+}
+
+// newTestClient запускает сервер поверх bufconn и возвращает подключённого к нему клиента.
+// Лимиты заданы для всех областей, поэтому хранилище лимитов вызывается на каждый ограниченный метод.
+func newTestClient(t *testing.T, deps *testDeps) linkv1.LinkServiceClient {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	ctx := context.Background()
+	svc := service.NewLinkService(ctx, deps.repo, deps.cache, nil, nil)
+	analyticsCtx, stopAnalytics := context.WithCancel(ctx)
+	t.Cleanup(stopAnalytics)
+	analytics := service.NewAnalytics(analyticsCtx, deps.clicks, nil, nil, "")
+	deps.analytics, deps.stopAnalytics = analytics, stopAnalytics
+	limit := service.RateLimit{Requests: 10, Window: time.Minute}
+	limiter := service.NewRateLimiter(deps.store, map[string]service.RateLimit{
+		service.RateLimitCreate:   limit,
+		service.RateLimitRedirect: limit,
+		service.RateLimitAuth:     limit,
+	}, nil)
+
+	server := NewServer(svc, analytics, service.NewAPIKeys(deps.keys), limiter, nil, logger, deps.cfg)
+	lis := bufconn.Listen(1 << 20)
+	go func() { _ = server.Serve(lis) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	assert.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return linkv1.NewLinkServiceClient(conn)
+}
+
+// recordedClicks останавливает запись переходов и возвращает переходы, записанные за тест.
+func recordedClicks(deps *testDeps) []models.Click {
+	var clicks []models.Click
+	deps.clicks.On("InsertClicks", mock.Anything, mock.Anything).Return(nil).
+		Run(func(args mock.Arguments) { clicks = append(clicks, args.Get(1).([]models.Click)...) }).Maybe()
+
+	deps.stopAnalytics()
+	deps.analytics.Run(nil)
+	return clicks
+}
+
+func TestServer_ResolvePassword(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		password string
+		code     codes.Code
+	}{
+		{name: "without password", code: codes.PermissionDenied},
+		{name: "wrong password", password: "wrong", code: codes.PermissionDenied},
+		{name: "correct password", password: "secret", code: codes.OK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps := newTestDeps()
+			deps.store.On("Allow", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(true, time.Duration(0), nil)
+			deps.cache.On("GetRedirect", mock.Anything, "abc").
+				Return(&models.Redirect{URL: "https://example.com", Code: http.StatusFound, PasswordHash: string(hash)}, nil)
+			client := newTestClient(t, deps)
+
+			resp, err := client.Resolve(context.Background(), &linkv1.ResolveRequest{ShortLink: "abc", Password: tt.password})
+
+			assert.Equal(t, tt.code, status.Code(err))
+			if tt.code == codes.OK {
+				assert.Equal(t, "https://example.com", resp.GetOriginalUrl())
+				assert.Equal(t, int32(http.StatusFound), resp.GetRedirectCode())
+			}
+		})
+	}
+
+	t.Run("unprotected link", func(t *testing.T) {
+		deps := newTestDeps()
+		deps.store.On("Allow", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(true, time.Duration(0), nil)
+		deps.cache.On("GetRedirect", mock.Anything, "abc").
+			Return(&models.Redirect{URL: "https://example.com", Code: http.StatusFound}, nil)
+		client := newTestClient(t, deps)
+
+		resp, err := client.Resolve(context.Background(), &linkv1.ResolveRequest{ShortLink: "abc", Password: "ignored"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "https://example.com", resp.GetOriginalUrl())
+	})
+}
+
+func TestServer_BatchShorten(t *testing.T) {
+	deps := newTestDeps()
+	deps.store.On("Allow", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(true, time.Duration(0), nil)
+	deps.cache.On("GetShortLink", mock.Anything, "https://example.com/a").Return("", nil).Maybe()
+	deps.repo.On("FindByOriginalURL", mock.Anything, "https://example.com/a").
+		Return(nil, errors.New("pq: password authentication failed"))
+	client := newTestClient(t, deps)
+
+	resp, err := client.BatchShorten(context.Background(), &linkv1.BatchShortenRequest{Items: []*linkv1.ShortenRequest{
+		{Url: "https://example.com/a"},
+		{},
+		{Url: "https://example.com/b", Ttl: "soon"},
+		{Url: "https://example.com/c", ExpiresAt: &timestamppb.Timestamp{Nanos: -1}},
+	}})
+
+	if !assert.NoError(t, err) {
+		return
+	}
+	results := resp.GetResults()
+	if !assert.Len(t, results, 4) {
+		return
+	}
+	assert.Equal(t, "internal", results[0].GetCode())
+	assert.Equal(t, "внутренняя ошибка сервера", results[0].GetError())
+	assert.Equal(t, "url_required", results[1].GetCode())
+	assert.Equal(t, "invalid_ttl", results[2].GetCode())
+	assert.Contains(t, results[2].GetError(), "soon")
+	assert.Equal(t, "invalid_ttl", results[3].GetCode())
+	for i, result := range results {
+		assert.Equal(t, int32(i), result.GetIndex())
+		assert.Nil(t, result.GetLink())
+	}
+}
+
+func TestServer_ResolveClicks(t *testing.T) {
+	plain := &models.Redirect{URL: "https://example.com", Code: http.StatusFound}
+	interstitial := &models.Redirect{URL: "https://example.com", Code: http.StatusFound, Interstitial: true}
+	proxied := &linkv1.ResolveRequest{ShortLink: "abc", Referrer: "https://ref.example", UserAgent: "proxied-agent", ClientIp: "203.0.113.7"}
+
+	tests := []struct {
+		name      string
+		redirect  *models.Redirect
+		req       *linkv1.ResolveRequest
+		apiKey    bool
+		clicks    int
+		userAgent string
+	}{
+		{name: "plain link is a click", redirect: plain, req: &linkv1.ResolveRequest{ShortLink: "abc"}, clicks: 1, userAgent: "grpc-go/"},
+		{name: "interstitial link without proceed is not a click", redirect: interstitial,
+			req: &linkv1.ResolveRequest{ShortLink: "abc"}},
+		{name: "proceed from interstitial is a click", redirect: interstitial,
+			req: &linkv1.ResolveRequest{ShortLink: "abc", Proceed: true}, clicks: 1, userAgent: "grpc-go/"},
+		{name: "anonymous caller cannot set client metadata", redirect: plain, req: proxied, clicks: 1, userAgent: "grpc-go/"},
+		{name: "api key caller sets client metadata", redirect: plain, req: proxied, apiKey: true, clicks: 1, userAgent: "proxied-agent"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps := newTestDeps()
+			deps.store.On("Allow", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(true, time.Duration(0), nil)
+			deps.cache.On("GetRedirect", mock.Anything, "abc").Return(tt.redirect, nil)
+			md := metadata.MD{}
+			if tt.apiKey {
+				deps.keys.On("FindAPIKeyByHash", mock.Anything, mock.Anything).Return(&models.APIKey{ID: 1, OwnerID: "proxy"}, nil)
+				md.Append("x-api-key", testAPIKey)
+			}
+			client := newTestClient(t, deps)
+
+			resp, err := client.Resolve(metadata.NewOutgoingContext(context.Background(), md), tt.req)
+
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, "https://example.com", resp.GetOriginalUrl())
+			assert.Equal(t, tt.redirect.Interstitial, resp.GetInterstitial())
+
+			clicks := recordedClicks(deps)
+			if !assert.Len(t, clicks, tt.clicks) || tt.clicks == 0 {
+				return
+			}
+			assert.Equal(t, "abc", clicks[0].ShortLink)
+			assert.Equal(t, tt.req.GetReferrer(), clicks[0].Referrer)
+			// Без API-ключа записывается user-agent самого клиента gRPC
+			assert.True(t, strings.HasPrefix(clicks[0].UserAgent, tt.userAgent), clicks[0].UserAgent)
+			assert.NotEmpty(t, clicks[0].IPHash)
+		})
+	}
+
+	t.Run("client ip from the request is used only with api key", func(t *testing.T) {
+		hashes := make(map[bool]string)
+		for _, apiKey := range []bool{false, true} {
+			deps := newTestDeps()
+			deps.store.On("Allow", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(true, time.Duration(0), nil)
+			deps.cache.On("GetRedirect", mock.Anything, "abc").Return(plain, nil)
+			ctx := context.Background()
+			if apiKey {
+				deps.keys.On("FindAPIKeyByHash", mock.Anything, mock.Anything).Return(&models.APIKey{ID: 1, OwnerID: "proxy"}, nil)
+				ctx = metadata.NewOutgoingContext(ctx, metadata.Pairs("x-api-key", testAPIKey))
+			}
+			client := newTestClient(t, deps)
+
+			_, err := client.Resolve(ctx, proxied)
+			assert.NoError(t, err)
+
+			clicks := recordedClicks(deps)
+			if assert.Len(t, clicks, 1) {
+				hashes[apiKey] = clicks[0].IPHash
+			}
+		}
+		assert.NotEqual(t, hashes[false], hashes[true])
+	})
+}
@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	"linkreduction/internal/service"
+	"net/http"
+	"strings"
+)
+
+const (
+	headerAPIKey   = "X-API-Key"
+	localsOwnerKey = "owner_id"
+	localsAPIKeyID = "api_key_id"
+)
+
+// authenticate проверяет API-ключ из заголовка X-API-Key или Authorization: Bearer
+// и сохраняет владельца в контексте запроса. Запросы без ключа проходят анонимно.
+func (h *Handler) authenticate(c *fiber.Ctx) error {
+	plain := apiKeyFromRequest(c)
+	if plain == "" {
+		return c.Next()
+	}
+
+	key, err := h.apiKeys.Authenticate(h.ctx, plain)
+	if err != nil {
+		if errors.Is(err, service.ErrUnauthorized) {
+			c.Set(fiber.HeaderWWWAuthenticate, "Bearer")
+		}
+		return err
+	}
+
+	c.Locals(localsOwnerKey, key.OwnerID)
+	c.Locals(localsAPIKeyID, key.ID)
+	return c.Next()
+}
+
+// requireAuth пропускает только запросы с действительным API-ключом.
+func (h *Handler) requireAuth(c *fiber.Ctx) error {
+	if ownerID(c) == "" {
+		c.Set(fiber.HeaderWWWAuthenticate, "Bearer")
+		return newRequestError(http.StatusUnauthorized, "api_key_required", "требуется API-ключ")
+	}
+	return c.Next()
+}
+
+// ownerID возвращает владельца, определённый middleware authenticate.
+func ownerID(c *fiber.Ctx) string {
+	owner, _ := c.Locals(localsOwnerKey).(string)
+	return owner
+}
+
+func apiKeyFromRequest(c *fiber.Ctx) string {
+	if key := c.Get(headerAPIKey); key != "" {
+		return key
+	}
+	if token, ok := strings.CutPrefix(c.Get(fiber.HeaderAuthorization), "Bearer "); ok {
+		return strings.TrimSpace(token)
+	}
+	return ""
+}
@@ -0,0 +1,226 @@
+package handler
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+	"linkreduction/internal/service"
+	"net/http"
+	"strings"
+)
+
+const (
+	mimeNDJSON = "application/x-ndjson"
+	// batchChunkSize — сколько строк NDJSON обрабатывается и сохраняется за один проход.
+	batchChunkSize = 500
+	// maxNDJSONLineSize — наибольшая длина строки NDJSON без перевода строки. Строка — тот же
+	// запрос, что и при сокращении одной ссылки, а пакет до разбора целиком держится в памяти.
+	maxNDJSONLineSize = maxShortenBodySize
+
+	batchPath = "/api/links/batch"
+)
+
+type BatchItemResult struct {
+	Index    int    `json:"index"`
+	URL      string `json:"url"`
+	ShortURL string `json:"shortURL,omitempty"`
+	Error    string `json:"error,omitempty"`
+	// Code — машиночитаемый код ошибки элемента, как в Problem.Code.
+	Code string `json:"code,omitempty"`
+}
+
+// createShortLinks принимает JSON-массив запросов или NDJSON (по запросу в строке)
+// и возвращает результат для каждого элемента в том же формате.
+func (h *Handler) createShortLinks(c *fiber.Ctx) error {
+	contentType := strings.TrimSpace(strings.Split(c.Get(fiber.HeaderContentType), ";")[0])
+
+	switch contentType {
+	case fiber.MIMEApplicationJSON:
+		var reqs []ShortenRequest
+		if err := json.Unmarshal(c.Body(), &reqs); err != nil {
+			return newRequestError(http.StatusBadRequest, "invalid_json", fmt.Sprintf("некорректное тело JSON: %v", err))
+		}
+		if len(reqs) == 0 {
+			return newRequestError(http.StatusBadRequest, "empty_batch", "пакет не содержит ссылок")
+		}
+
+		results, err := h.shortenBatch(reqs, 0, ownerID(c))
+		if err != nil {
+			return err
+		}
+		return c.Status(http.StatusOK).JSON(results)
+
+	case mimeNDJSON:
+		return h.createShortLinksNDJSON(c)
+
+	default:
+		return newRequestError(http.StatusUnsupportedMediaType, "unsupported_media_type",
+			"Content-Type должен быть application/json или "+mimeNDJSON)
+	}
+}
+
+// ndjsonLine — разобранная строка NDJSON: запрос или ошибка разбора.
+type ndjsonLine struct {
+	req ShortenRequest
+	err error
+}
+
+// createShortLinksNDJSON читает тело потоком и проверяет число строк до сохранения первой ссылки,
+// поэтому слишком большой пакет отклоняется целиком. Результаты отправляются потоком по мере
+// сохранения частей пакета.
+func (h *Handler) createShortLinksNDJSON(c *fiber.Ctx) error {
+	body := c.Context().RequestBodyStream()
+	if body == nil {
+		body = bytes.NewReader(c.Body())
+	}
+	scanner := bufio.NewScanner(body)
+	// Буфер вмещает строку вместе с "\r\n"
+	scanner.Buffer(make([]byte, 0, maxNDJSONLineSize+2), maxNDJSONLineSize+2)
+
+	maxItems := h.cfg.Links.BatchMaxItems
+	var lines []ndjsonLine
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		if len(line) > maxNDJSONLineSize {
+			c.Context().SetConnectionClose()
+			return newRequestError(http.StatusBadRequest, "invalid_ndjson", fmt.Sprintf("строка NDJSON длиннее %d байт", maxNDJSONLineSize))
+		}
+		if maxItems > 0 && len(lines) >= maxItems {
+			// Остаток тела не читается, поэтому соединение не переиспользуется
+			c.Context().SetConnectionClose()
+			return newRequestError(http.StatusBadRequest, "batch_too_large",
+				fmt.Sprintf("пакет содержит больше %d элементов", maxItems))
+		}
+
+		var parsed ndjsonLine
+		if err := json.Unmarshal(line, &parsed.req); err != nil {
+			// Некорректная строка не прерывает обработку остальных
+			parsed.err = newRequestError(http.StatusBadRequest, "invalid_json", fmt.Sprintf("некорректная строка JSON: %v", err))
+		}
+		lines = append(lines, parsed)
+	}
+	if err := scanner.Err(); err != nil {
+		c.Context().SetConnectionClose()
+		if errors.Is(err, bufio.ErrTooLong) {
+			return newRequestError(http.StatusBadRequest, "invalid_ndjson", fmt.Sprintf("строка NDJSON длиннее %d байт", maxNDJSONLineSize))
+		}
+		return newRequestError(http.StatusBadRequest, "invalid_ndjson", fmt.Sprintf("ошибка чтения NDJSON: %v", err))
+	}
+
+	owner := ownerID(c)
+	c.Set(fiber.HeaderContentType, mimeNDJSON)
+	c.Status(http.StatusOK)
+	// Тело пишется после возврата из обработчика, когда c уже нельзя использовать
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		encoder := json.NewEncoder(w)
+		chunk := make([]ShortenRequest, 0, batchChunkSize)
+		flush := func(next int) error {
+			if len(chunk) == 0 {
+				return nil
+			}
+			results, err := h.shortenBatch(chunk, next-len(chunk), owner)
+			if err != nil {
+				return err
+			}
+			for _, result := range results {
+				if err := encoder.Encode(result); err != nil {
+					return err
+				}
+			}
+			chunk = chunk[:0]
+			return w.Flush()
+		}
+
+		for i, line := range lines {
+			if line.err != nil {
+				if err := flush(i); err != nil {
+					h.logger.WithError(err).Error("Ошибка обработки пакета NDJSON")
+					return
+				}
+				result := BatchItemResult{Index: i}
+				h.setItemError(&result, line.err)
+				_ = encoder.Encode(result)
+				continue
+			}
+			chunk = append(chunk, line.req)
+			if len(chunk) >= batchChunkSize {
+				if err := flush(i + 1); err != nil {
+					h.logger.WithError(err).Error("Ошибка обработки пакета NDJSON")
+					return
+				}
+			}
+		}
+		if err := flush(len(lines)); err != nil {
+			h.logger.WithError(err).Error("Ошибка обработки пакета NDJSON")
+		}
+	})
+	return nil
+}
+
+// shortenBatch сокращает пачку запросов; offset — индекс первого элемента во всём запросе.
+func (h *Handler) shortenBatch(reqs []ShortenRequest, offset int, owner string) ([]BatchItemResult, error) {
+	baseURL := h.cfg.Server.BaseURL
+
+	results := make([]BatchItemResult, len(reqs))
+	items := make([]service.BatchItem, 0, len(reqs))
+	positions := make([]int, 0, len(reqs))
+
+	for i, req := range reqs {
+		results[i] = BatchItemResult{Index: offset + i, URL: req.URL}
+
+		if req.URL == "" {
+			h.setItemError(&results[i], newRequestError(http.StatusBadRequest, "url_required", "URL обязателен"))
+			continue
+		}
+		opts, err := req.options()
+		if err != nil {
+			h.setItemError(&results[i], err)
+			continue
+		}
+		opts.OwnerID = owner
+
+		items = append(items, service.BatchItem{URL: req.URL, Options: opts})
+		positions = append(positions, i)
+	}
+
+	batchResults, err := h.service.ShortenBatch(h.ctx, items, baseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	for j, result := range batchResults {
+		i := positions[j]
+		if result.Err != nil {
+			h.setItemError(&results[i], result.Err)
+			continue
+		}
+		results[i].ShortURL = fmt.Sprintf("%s/%s", baseURL, result.Link.ShortLink)
+	}
+
+	return results, nil
+}
+
+// setItemError записывает в результат элемента код и описание ошибки. Как и в ErrorHandler,
+// подробности ошибок сервера клиенту не передаются, а только пишутся в лог.
+func (h *Handler) setItemError(result *BatchItemResult, err error) {
+	status, code := statusOf(err), service.ErrorCode(err)
+	var reqErr *requestError
+	if errors.As(err, &reqErr) {
+		status, code = reqErr.status, reqErr.code
+	}
+	if status >= http.StatusInternalServerError {
+		h.logger.WithFields(logrus.Fields{
+			"status": status,
+			"code":   code,
+			"index":  result.Index,
+		}).Error(err)
+	}
+	result.Code, result.Error = publicError(status, code, err.Error())
+}
@@ -0,0 +1,202 @@
+package handler
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"linkreduction/internal/mocks"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func batchRequest(contentType, body string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, batchPath, strings.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	return req
+}
+
+// ndjsonBody собирает тело NDJSON из n одинаковых по формату запросов.
+func ndjsonBody(n int) string {
+	var b strings.Builder
+	for i := range n {
+		fmt.Fprintf(&b, "{\"url\": \"https://example.com/%d\"}\n", i)
+	}
+	return b.String()
+}
+
+func readNDJSON(t *testing.T, resp *http.Response) []BatchItemResult {
+	var results []BatchItemResult
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		var result BatchItemResult
+		assert.NoError(t, json.Unmarshal(scanner.Bytes(), &result))
+		results = append(results, result)
+	}
+	assert.NoError(t, scanner.Err())
+	return results
+}
+
+func TestHandler_CreateShortLinksJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		status  int
+		code    string
+		results func(t *testing.T, results []BatchItemResult)
+	}{
+		{
+			name:   "result for every item",
+			body:   `[{"url": "https://example.com/a"}, {"url": ""}, {"url": "https://example.com/b", "ttl": "soon"}, {"url": "ftp://example.com"}]`,
+			status: http.StatusOK,
+			results: func(t *testing.T, results []BatchItemResult) {
+				if !assert.Len(t, results, 4) {
+					return
+				}
+				for i, result := range results {
+					assert.Equal(t, i, result.Index)
+				}
+				assert.True(t, strings.HasPrefix(results[0].ShortURL, testBaseURL+"/"), results[0].ShortURL)
+				assert.Empty(t, results[0].Error)
+				for _, result := range results[1:] {
+					assert.Empty(t, result.ShortURL)
+					assert.NotEmpty(t, result.Error)
+				}
+				assert.Equal(t, "url_required", results[1].Code)
+				assert.Equal(t, "invalid_ttl", results[2].Code)
+				assert.Equal(t, "invalid_url", results[3].Code)
+			},
+		},
+		{name: "malformed json", body: `[{"url": `, status: http.StatusBadRequest, code: "invalid_json"},
+		{name: "empty batch", body: `[]`, status: http.StatusBadRequest, code: "empty_batch"},
+		{name: "too many items", body: `[{"url": "https://a.com"}, {"url": "https://b.com"}, {"url": "https://c.com"}, {"url": "https://d.com"}]`,
+			status: http.StatusBadRequest, code: "invalid_input"},
+		{name: "body over limit", body: "[" + strings.Repeat(" ", testBodyLimit) + "]",
+			status: http.StatusRequestEntityTooLarge, code: "body_too_large"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newTestConfig()
+			cfg.Links.BatchMaxItems = 3
+			if tt.results != nil {
+				cfg.Links.BatchMaxItems = 10
+			}
+			app := newTestApp(t, cfg)
+
+			resp := app.do(t, batchRequest("application/json", tt.body))
+
+			assert.Equal(t, tt.status, resp.StatusCode)
+			if tt.results == nil {
+				var problem Problem
+				assert.NoError(t, json.NewDecoder(resp.Body).Decode(&problem))
+				assert.Equal(t, tt.code, problem.Code)
+				return
+			}
+			var results []BatchItemResult
+			assert.NoError(t, json.NewDecoder(resp.Body).Decode(&results))
+			tt.results(t, results)
+		})
+	}
+}
+
+func TestHandler_CreateShortLinksNDJSON(t *testing.T) {
+	t.Run("malformed lines do not stop the batch", func(t *testing.T) {
+		app := newTestApp(t, newTestConfig())
+		body := "{\"url\": \"https://example.com/a\"}\n\n{not json\r\n{\"url\": \"https://example.com/b\", \"ttl\": \"soon\"}\n{\"url\": \"https://example.com/c\"}"
+
+		resp := app.do(t, batchRequest(mimeNDJSON, body))
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, mimeNDJSON, resp.Header.Get("Content-Type"))
+		results := readNDJSON(t, resp)
+		if !assert.Len(t, results, 4) {
+			return
+		}
+		for i, result := range results {
+			assert.Equal(t, i, result.Index)
+		}
+		assert.NotEmpty(t, results[0].ShortURL)
+		assert.Contains(t, results[1].Error, "некорректная строка JSON")
+		assert.Equal(t, "invalid_json", results[1].Code)
+		assert.Equal(t, "invalid_ttl", results[2].Code)
+		assert.NotEmpty(t, results[3].ShortURL)
+	})
+
+	t.Run("body is not limited by BodyLimit", func(t *testing.T) {
+		app := newTestApp(t, newTestConfig())
+		body := ndjsonBody(200)
+		assert.Greater(t, len(body), testBodyLimit)
+
+		resp := app.do(t, batchRequest(mimeNDJSON, body))
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		results := readNDJSON(t, resp)
+		assert.Len(t, results, 200)
+		for _, result := range results {
+			assert.Empty(t, result.Error)
+		}
+	})
+
+	rejected := []struct {
+		name string
+		body string
+		code string
+	}{
+		{name: "too many lines", body: ndjsonBody(4), code: "batch_too_large"},
+		{name: "line longer than a single request", code: "invalid_ndjson",
+			body: ndjsonBody(1) + `{"url": "https://example.com/` + strings.Repeat("a", maxNDJSONLineSize) + "\"}\n"},
+		{name: "line too long for the reader", code: "invalid_ndjson",
+			body: ndjsonBody(1) + strings.Repeat("a", 2*maxNDJSONLineSize)},
+	}
+	for _, tt := range rejected {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newTestConfig()
+			cfg.Links.BatchMaxItems = 3
+			app := newTestApp(t, cfg)
+
+			resp := app.do(t, batchRequest(mimeNDJSON, tt.body))
+
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+			var problem Problem
+			assert.NoError(t, json.NewDecoder(resp.Body).Decode(&problem))
+			assert.Equal(t, tt.code, problem.Code)
+			// Пакет отклоняется целиком: первая строка тоже не сохранена
+			link, err := app.links.FindByOriginalURL(context.Background(), "https://example.com/0")
+			assert.NoError(t, err)
+			assert.Nil(t, link)
+		})
+	}
+
+	t.Run("unsupported content type", func(t *testing.T) {
+		app := newTestApp(t, newTestConfig())
+
+		resp := app.do(t, batchRequest("text/plain", ndjsonBody(1)))
+
+		assert.Equal(t, http.StatusUnsupportedMediaType, resp.StatusCode)
+	})
+}
+
+func TestHandler_CreateShortLinksHidesServerErrors(t *testing.T) {
+	repo := new(mocks.LinkRepo)
+	repo.On("FindByOriginalURL", mock.Anything, "https://example.com/a").
+		Return(nil, errors.New("pq: password authentication failed"))
+	app := newTestAppWithRepo(t, newTestConfig(), repo)
+
+	resp := app.do(t, batchRequest("application/json", `[{"url": "https://example.com/a"}, {"url": "https://example.com/b", "ttl": "soon"}]`))
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	var results []BatchItemResult
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&results))
+	assert.Equal(t, []BatchItemResult{
+		{Index: 0, URL: "https://example.com/a", Error: "внутренняя ошибка сервера", Code: "internal"},
+		{Index: 1, URL: "https://example.com/b", Error: results[1].Error, Code: "invalid_ttl"},
+	}, results)
+	assert.Contains(t, results[1].Error, "soon")
+}
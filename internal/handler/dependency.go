@@ -2,48 +2,221 @@ package handler
 
 import (
 	"context"
-	"database/sql"
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"github.com/IBM/sarama"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/redis/go-redis/v9"
 	"linkreduction/internal/config"
+	"linkreduction/internal/repository/memory"
+	"linkreduction/internal/repository/postgres"
+	"linkreduction/internal/repository/sqlite"
+	"linkreduction/internal/service"
+	"os"
 	"strings"
 	"time"
 )
 
-func InitPostgres(cfg *config.Config) (*sql.DB, error) {
+func InitPostgres(ctx context.Context, cfg *config.Config) (*pgxpool.Pool, error) {
 	dbURL := cfg.DB.LinksDB
 	if dbURL == "" {
 		return nil, fmt.Errorf("переменная окружения DB_DSN_LINKSDB не задана")
 	}
 
-	db, err := sql.Open("postgres", dbURL)
+	db, err := openPool(ctx, dbURL, cfg.DB)
 	if err != nil {
 		return nil, fmt.Errorf("ошибка открытия базы данных: %w", err)
 	}
 
-	if err := db.Ping(); err != nil {
+	if err := db.Ping(ctx); err != nil {
+		db.Close()
 		return nil, fmt.Errorf("база данных недоступна: %v", err)
 	}
 
 	return db, nil
 }
 
-func RedisConnect(ctx context.Context, cfg *config.Config) (*redis.Client, error) {
-	redisURL := cfg.Redis.URL
-	if redisURL == "" {
-		return nil, fmt.Errorf("переменная окружения REDIS_URL не задана")
+// InitReplicas открывает реплики из db.replica_dsns; без реплик возвращается nil. Соединения
+// проверяет Replicas.Watch: недоступная при запуске реплика начнёт использоваться, когда появится.
+func InitReplicas(ctx context.Context, cfg *config.Config) (*postgres.Replicas, error) {
+	if len(cfg.DB.ReplicaDSNs) == 0 {
+		return nil, nil
 	}
 
-	redisClient := redis.NewClient(&redis.Options{Addr: redisURL})
+	dbs := make([]*pgxpool.Pool, 0, len(cfg.DB.ReplicaDSNs))
+	for _, dsn := range cfg.DB.ReplicaDSNs {
+		db, err := openPool(ctx, dsn, cfg.DB)
+		if err != nil {
+			for _, opened := range dbs {
+				opened.Close()
+			}
+			return nil, fmt.Errorf("ошибка открытия реплики базы данных: %w", err)
+		}
+		dbs = append(dbs, db)
+	}
+	return postgres.NewReplicas(dbs, cfg.DB.MaxReplicaLag), nil
+}
+
+// openPool создаёт пул с настройками из db; соединения открываются при первом запросе.
+func openPool(ctx context.Context, dsn string, cfg config.DBC) (*pgxpool.Pool, error) {
+	poolConfig, err := pgxpool.ParseConfig(dsn)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.MaxConns > 0 {
+		poolConfig.MaxConns = cfg.MaxConns
+	}
+	if cfg.MinConns > 0 {
+		poolConfig.MinConns = cfg.MinConns
+	}
+	if cfg.ConnMaxLifetime > 0 {
+		poolConfig.MaxConnLifetime = cfg.ConnMaxLifetime
+	}
+	if cfg.ConnMaxIdleTime > 0 {
+		poolConfig.MaxConnIdleTime = cfg.ConnMaxIdleTime
+	}
+	if cfg.HealthCheckPeriod > 0 {
+		poolConfig.HealthCheckPeriod = cfg.HealthCheckPeriod
+	}
+	return pgxpool.NewWithConfig(ctx, poolConfig)
+}
+
+// UsesPostgres сообщает, нужен ли сервису Postgres: для хранилища ссылок postgres или, при другом
+// хранилище, для API-ключей и переходов, если задан db.linksdb_dsn.
+func UsesPostgres(cfg *config.Config) bool {
+	return cfg.Storage.Driver == "" || cfg.Storage.Driver == "postgres" || cfg.DB.LinksDB != ""
+}
+
+// InitLinkRepo создаёт хранилище ссылок по storage.driver. closeRepo освобождает ресурсы хранилища;
+// соединение с Postgres db и реплики replicas закрывает вызывающий. Хранилища sqlite и memory
+// не поддерживают outbox, поэтому вместе с Kafka не запускаются: сообщения о ссылках не отправлялись бы.
+func InitLinkRepo(cfg *config.Config, db *pgxpool.Pool, replicas *postgres.Replicas) (repo service.LinkRepo, closeRepo func() error, err error) {
+	noop := func() error { return nil }
+
+	switch cfg.Storage.Driver {
+	case "", "postgres":
+		return postgres.NewPostgresLinkRepository(db, replicas), noop, nil
+	}
+	if cfg.Kafka.Brokers != "" {
+		return nil, nil, fmt.Errorf("хранилище %s не поддерживает outbox: kafka.brokers требует storage.driver postgres",
+			cfg.Storage.Driver)
+	}
+
+	switch cfg.Storage.Driver {
+	case "memory":
+		return memory.NewLinkRepository(), noop, nil
+	case "sqlite":
+		if cfg.Storage.SQLitePath == "" {
+			return nil, nil, fmt.Errorf("для хранилища sqlite требуется storage.sqlite_path")
+		}
+		sqliteDB, err := sqlite.Open(cfg.Storage.SQLitePath)
+		if err != nil {
+			return nil, nil, err
+		}
+		return sqlite.NewSQLiteLinkRepository(sqliteDB), sqliteDB.Close, nil
+	default:
+		return nil, nil, fmt.Errorf("неизвестное хранилище ссылок: %q", cfg.Storage.Driver)
+	}
+}
+
+// RedisConnect создаёт клиент Redis: одиночный узел, Sentinel или Cluster в зависимости от настроек.
+// Пустой redis.url означает работу без Redis: возвращается nil без ошибки. Если Redis не отвечает,
+// клиент возвращается вместе с ошибкой: он подключится сам, когда Redis станет доступен.
+func RedisConnect(ctx context.Context, cfg *config.Config) (redis.UniversalClient, error) {
+	if cfg.Redis.URL == "" {
+		return nil, nil
+	}
+
+	options, err := RedisOptions(cfg)
+	if err != nil {
+		return nil, err
+	}
+	redisClient := redis.NewUniversalClient(options)
 
 	if _, err := redisClient.Ping(ctx).Result(); err != nil {
-		return nil, fmt.Errorf("Redis недоступен: %v", err)
+		return redisClient, fmt.Errorf("Redis недоступен: %v", err)
 	}
 
 	return redisClient, nil
 }
 
+// RedisOptions собирает параметры клиента из конфигурации. Несколько адресов допустимы только
+// для Sentinel (задан master_name) и Cluster.
+func RedisOptions(cfg *config.Config) (*redis.UniversalOptions, error) {
+	redisCfg := cfg.Redis
+	addrs := make([]string, 0)
+	for _, addr := range strings.Split(redisCfg.URL, ",") {
+		if trimmed := strings.TrimSpace(addr); trimmed != "" {
+			addrs = append(addrs, trimmed)
+		}
+	}
+	if len(addrs) == 0 {
+		return nil, fmt.Errorf("redis.url не содержит адресов")
+	}
+	if redisCfg.Cluster && redisCfg.MasterName != "" {
+		return nil, fmt.Errorf("redis.cluster и redis.master_name нельзя задать одновременно")
+	}
+	if redisCfg.Cluster && redisCfg.DB != 0 {
+		return nil, fmt.Errorf("в режиме Redis Cluster доступна только база 0")
+	}
+	if len(addrs) > 1 && !redisCfg.Cluster && redisCfg.MasterName == "" {
+		return nil, fmt.Errorf("несколько адресов Redis требуют redis.cluster или redis.master_name")
+	}
+
+	options := &redis.UniversalOptions{
+		Addrs:            addrs,
+		MasterName:       redisCfg.MasterName,
+		IsClusterMode:    redisCfg.Cluster,
+		Username:         redisCfg.Username,
+		Password:         redisCfg.Password,
+		SentinelUsername: redisCfg.SentinelUsername,
+		SentinelPassword: redisCfg.SentinelPassword,
+		DB:               redisCfg.DB,
+		PoolSize:         redisCfg.PoolSize,
+		MinIdleConns:     redisCfg.MinIdleConns,
+		PoolTimeout:      redisCfg.PoolTimeout,
+		DialTimeout:      redisCfg.DialTimeout,
+		ReadTimeout:      redisCfg.ReadTimeout,
+		WriteTimeout:     redisCfg.WriteTimeout,
+	}
+	if redisCfg.TLS.Enabled {
+		tlsConfig, err := redisTLSConfig(redisCfg.TLS)
+		if err != nil {
+			return nil, err
+		}
+		options.TLSConfig = tlsConfig
+	}
+	return options, nil
+}
+
+func redisTLSConfig(cfg config.RedisTLS) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         cfg.ServerName,
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+	}
+	if cfg.CAFile != "" {
+		caCert, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка чтения CA-сертификата Redis: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("файл %s не содержит PEM-сертификатов", cfg.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if cfg.CertFile != "" || cfg.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка загрузки клиентского сертификата Redis: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
+
 func GetKafkaBrokers(cfg *config.Config) ([]string, error) {
 	kafkaEnv := cfg.Kafka.Brokers
 	if kafkaEnv == "" {
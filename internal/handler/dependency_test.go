@@ -0,0 +1,158 @@
+package handler
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"linkreduction/internal/config"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInitLinkRepo(t *testing.T) {
+	tests := []struct {
+		name         string
+		cfg          config.Config
+		usesPostgres bool
+		expectErr    bool
+	}{
+		{name: "postgres by default", cfg: config.Config{}, usesPostgres: true},
+		{name: "memory without postgres", cfg: config.Config{Storage: config.Storage{Driver: "memory"}}},
+		{name: "memory with postgres for api keys", cfg: config.Config{Storage: config.Storage{Driver: "memory"},
+			DB: config.DBC{LinksDB: "postgres://localhost/links"}}, usesPostgres: true},
+		{name: "sqlite in a temp file", cfg: config.Config{Storage: config.Storage{Driver: "sqlite",
+			SQLitePath: filepath.Join(t.TempDir(), "links.db")}}},
+		{name: "sqlite without path", cfg: config.Config{Storage: config.Storage{Driver: "sqlite"}}, expectErr: true},
+		{name: "memory with kafka", cfg: config.Config{Storage: config.Storage{Driver: "memory"},
+			Kafka: config.Kafka{Brokers: "kafka:9092"}}, expectErr: true},
+		{name: "unknown driver", cfg: config.Config{Storage: config.Storage{Driver: "mongo"}}, expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.usesPostgres, UsesPostgres(&tt.cfg))
+
+			repo, closeRepo, err := InitLinkRepo(&tt.cfg, nil, nil)
+			if tt.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.NotNil(t, repo)
+				assert.NoError(t, closeRepo())
+			}
+		})
+	}
+}
+
+func TestRedisOptions(t *testing.T) {
+	tests := []struct {
+		name      string
+		redis     config.Redis
+		addrs     []string
+		expectErr bool
+	}{
+		{name: "single address", redis: config.Redis{URL: "redis:6379", DB: 2}, addrs: []string{"redis:6379"}},
+		{name: "sentinel", redis: config.Redis{URL: "s1:26379, s2:26379,", MasterName: "mymaster"}, addrs: []string{"s1:26379", "s2:26379"}},
+		{name: "cluster", redis: config.Redis{URL: "n1:6379,n2:6379", Cluster: true}, addrs: []string{"n1:6379", "n2:6379"}},
+		{name: "empty address list", redis: config.Redis{URL: " , "}, expectErr: true},
+		{name: "cluster with master name", redis: config.Redis{URL: "n1:6379", Cluster: true, MasterName: "mymaster"}, expectErr: true},
+		{name: "cluster with non-zero db", redis: config.Redis{URL: "n1:6379", Cluster: true, DB: 1}, expectErr: true},
+		{name: "several addresses without mode", redis: config.Redis{URL: "r1:6379,r2:6379"}, expectErr: true},
+		{name: "tls with missing ca file", redis: config.Redis{URL: "redis:6379",
+			TLS: config.RedisTLS{Enabled: true, CAFile: filepath.Join(t.TempDir(), "missing.pem")}}, expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options, err := RedisOptions(&config.Config{Redis: tt.redis})
+			if tt.expectErr {
+				assert.Error(t, err)
+				assert.Nil(t, options)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.addrs, options.Addrs)
+				assert.Equal(t, tt.redis.MasterName, options.MasterName)
+				assert.Equal(t, tt.redis.Cluster, options.IsClusterMode)
+				assert.Equal(t, tt.redis.DB, options.DB)
+				assert.Nil(t, options.TLSConfig)
+			}
+		})
+	}
+}
+
+func TestRedisTLSConfig(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeTestCertificate(t, dir)
+	garbage := filepath.Join(dir, "garbage.pem")
+	assert.NoError(t, os.WriteFile(garbage, []byte("not a certificate"), 0o600))
+	missing := filepath.Join(dir, "missing.pem")
+
+	tests := []struct {
+		name      string
+		cfg       config.RedisTLS
+		expectErr bool
+	}{
+		{name: "without files", cfg: config.RedisTLS{Enabled: true, ServerName: "redis.local"}},
+		{name: "ca and client certificate", cfg: config.RedisTLS{Enabled: true, CAFile: certFile, CertFile: certFile, KeyFile: keyFile}},
+		{name: "missing ca file", cfg: config.RedisTLS{Enabled: true, CAFile: missing}, expectErr: true},
+		{name: "ca file without pem", cfg: config.RedisTLS{Enabled: true, CAFile: garbage}, expectErr: true},
+		{name: "missing key file", cfg: config.RedisTLS{Enabled: true, CertFile: certFile, KeyFile: missing}, expectErr: true},
+		{name: "certificate without key", cfg: config.RedisTLS{Enabled: true, CertFile: certFile}, expectErr: true},
+		{name: "key that is not pem", cfg: config.RedisTLS{Enabled: true, CertFile: certFile, KeyFile: garbage}, expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tlsConfig, err := redisTLSConfig(tt.cfg)
+			if tt.expectErr {
+				assert.Error(t, err)
+				assert.Nil(t, tlsConfig)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.Equal(t, uint16(tls.VersionTLS12), tlsConfig.MinVersion)
+				assert.Equal(t, tt.cfg.ServerName, tlsConfig.ServerName)
+				assert.Equal(t, tt.cfg.CAFile != "", tlsConfig.RootCAs != nil)
+				if tt.cfg.CertFile != "" {
+					assert.Len(t, tlsConfig.Certificates, 1)
+				} else {
+					assert.Empty(t, tlsConfig.Certificates)
+				}
+			}
+		})
+	}
+}
+
+// writeTestCertificate записывает самоподписанный сертификат и его ключ в PEM.
+func writeTestCertificate(t *testing.T, dir string) (certFile, keyFile string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "redis.local"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IsCA:         true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	assert.NoError(t, err)
+
+	certFile = filepath.Join(dir, "cert.pem")
+	keyFile = filepath.Join(dir, "key.pem")
+	assert.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	assert.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	return certFile, keyFile
+}
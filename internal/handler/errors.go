@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+	"linkreduction/internal/service"
+	"net/http"
+	"strings"
+)
+
+const mimeProblemJSON = "application/problem+json"
+
+// Problem — описание ошибки в формате RFC 7807.
+type Problem struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	// Code — стабильный машиночитаемый код ошибки, например alias_taken.
+	Code string `json:"code"`
+}
+
+// requestError — ошибка разбора HTTP-запроса, обнаруженная до обращения к сервису.
+type requestError struct {
+	status int
+	code   string
+	detail string
+}
+
+func (e *requestError) Error() string {
+	return e.detail
+}
+
+func newRequestError(status int, code, detail string) error {
+	return &requestError{status: status, code: code, detail: detail}
+}
+
+// ErrorHandler — общий обработчик ошибок Fiber. Ошибки сервиса сопоставляются кодам HTTP
+// по категории, подробности непредвиденных ошибок пишутся только в лог.
+func (h *Handler) ErrorHandler(c *fiber.Ctx, err error) error {
+	var (
+		status int
+		code   string
+		detail = err.Error()
+	)
+
+	var reqErr *requestError
+	var fiberErr *fiber.Error
+	switch {
+	case errors.As(err, &reqErr):
+		status, code = reqErr.status, reqErr.code
+	case errors.As(err, &fiberErr):
+		status = fiberErr.Code
+		code = strings.ReplaceAll(strings.ToLower(http.StatusText(status)), " ", "_")
+	default:
+		status, code = statusOf(err), service.ErrorCode(err)
+	}
+
+	fields := logrus.Fields{
+		"status":     status,
+		"code":       code,
+		"path":       c.Path(),
+		"request_id": c.Get("X-Request-ID"),
+	}
+	if status >= http.StatusInternalServerError {
+		h.logger.WithFields(fields).Error(err)
+	} else {
+		h.logger.WithFields(fields).Warn(err)
+	}
+	code, detail = publicError(status, code, detail)
+
+	return c.Status(status).JSON(Problem{
+		Type:     "about:blank",
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   detail,
+		Instance: c.Path(),
+		Code:     code,
+	}, mimeProblemJSON)
+}
+
+// publicError скрывает от клиента подробности ошибок сервера: они пишутся только в лог.
+func publicError(status int, code, detail string) (string, string) {
+	switch {
+	case status == http.StatusServiceUnavailable:
+		return code, service.ErrUnavailable.Message
+	case status >= http.StatusInternalServerError:
+		return "internal", "внутренняя ошибка сервера"
+	}
+	return code, detail
+}
+
+// statusOf возвращает код HTTP для категории ошибки сервиса; неизвестные ошибки — 500.
+func statusOf(err error) int {
+	switch {
+	case errors.Is(err, service.ErrInvalidURL), errors.Is(err, service.ErrInvalidInput):
+		return http.StatusBadRequest
+	case errors.Is(err, service.ErrUnauthorized):
+		return http.StatusUnauthorized
+	case errors.Is(err, service.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrConflict):
+		return http.StatusConflict
+	case errors.Is(err, service.ErrExpired):
+		return http.StatusGone
+	case errors.Is(err, service.ErrRateLimited):
+		return http.StatusTooManyRequests
+	case errors.Is(err, service.ErrUnavailable):
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusInternalServerError
+}
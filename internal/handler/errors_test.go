@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"linkreduction/internal/service"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_ErrorHandler(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		code   string
+		detail string
+	}{
+		{name: "request error", err: newRequestError(http.StatusBadRequest, "url_required", "URL обязателен"),
+			status: http.StatusBadRequest, code: "url_required", detail: "URL обязателен"},
+		{name: "fiber error", err: fiber.ErrMethodNotAllowed,
+			status: http.StatusMethodNotAllowed, code: "method_not_allowed", detail: fiber.ErrMethodNotAllowed.Message},
+		{name: "invalid url", err: fmt.Errorf("%w: ftp://x", service.ErrInvalidURL),
+			status: http.StatusBadRequest, code: "invalid_url", detail: "некорректный URL: ftp://x"},
+		{name: "invalid input", err: service.ErrInvalidTTL,
+			status: http.StatusBadRequest, code: "invalid_ttl", detail: service.ErrInvalidTTL.Message},
+		{name: "unauthorized", err: service.ErrUnauthorized,
+			status: http.StatusUnauthorized, code: "unauthorized", detail: service.ErrUnauthorized.Message},
+		{name: "not found", err: service.ErrLinkNotFound,
+			status: http.StatusNotFound, code: "link_not_found", detail: service.ErrLinkNotFound.Message},
+		{name: "conflict", err: fmt.Errorf("%w: spring-sale", service.ErrAliasTaken),
+			status: http.StatusConflict, code: "alias_taken", detail: "псевдоним уже занят: spring-sale"},
+		{name: "expired", err: service.ErrLinkExpired,
+			status: http.StatusGone, code: "link_expired", detail: service.ErrLinkExpired.Message},
+		{name: "rate limited", err: service.ErrRateLimited,
+			status: http.StatusTooManyRequests, code: "rate_limited", detail: service.ErrRateLimited.Message},
+		{name: "unavailable hides details", err: fmt.Errorf("%w: redis: connection refused", service.ErrUnavailable),
+			status: http.StatusServiceUnavailable, code: "unavailable", detail: service.ErrUnavailable.Message},
+		{name: "unexpected error hides details", err: fmt.Errorf("ошибка базы данных: %w", errors.New("pq: password authentication failed")),
+			status: http.StatusInternalServerError, code: "internal", detail: "внутренняя ошибка сервера"},
+		{name: "canceled request", err: context.Canceled,
+			status: http.StatusInternalServerError, code: "internal", detail: "внутренняя ошибка сервера"},
+	}
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	h := &Handler{logger: logger}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New(fiber.Config{ErrorHandler: h.ErrorHandler})
+			app.Get("/fail", func(*fiber.Ctx) error { return tt.err })
+
+			resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/fail", nil), -1)
+			if !assert.NoError(t, err) {
+				return
+			}
+			defer resp.Body.Close()
+
+			assert.Equal(t, tt.status, resp.StatusCode)
+			assert.Equal(t, mimeProblemJSON, resp.Header.Get("Content-Type"))
+			var problem Problem
+			assert.NoError(t, json.NewDecoder(resp.Body).Decode(&problem))
+			assert.Equal(t, Problem{
+				Type:     "about:blank",
+				Title:    http.StatusText(tt.status),
+				Status:   tt.status,
+				Detail:   tt.detail,
+				Instance: "/fail",
+				Code:     tt.code,
+			}, problem)
+		})
+	}
+}
@@ -3,27 +3,65 @@ package handler
 import (
 	"context"
 	_ "context"
+	"errors"
 	"fmt"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/gofiber/fiber/v2/utils"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/sirupsen/logrus"
+	"io"
 	"linkreduction/internal/config"
 	"linkreduction/internal/prometheus"
 	"linkreduction/internal/service"
 	"net/http"
+	"strings"
+	"time"
 )
 
+// maxShortenBodySize — наибольший размер запроса на сокращение одной ссылки.
+const maxShortenBodySize = 2048
+
 type Handler struct {
-	ctx     context.Context
-	service *service.Service
-	metrics *initprometheus.PrometheusMetrics
-	logger  *logrus.Logger
-	cfg     *config.Config
+	ctx       context.Context
+	service   *service.Service
+	analytics *service.Analytics
+	apiKeys   *service.APIKeys
+	limiter   *service.RateLimiter
+	access    *service.AccessSigner
+	metrics   *initprometheus.PrometheusMetrics
+	logger    *logrus.Logger
+	cfg       *config.Config
 }
 
 type ShortenRequest struct {
 	URL string `json:"url"`
+	// Alias — необязательный человекочитаемый ключ короткой ссылки, например spring-sale.
+	Alias string `json:"alias,omitempty"`
+	// TTL — время жизни ссылки ("24h", "7d"; "0" — бессрочно). Нельзя указывать вместе с ExpiresAt.
+	TTL string `json:"ttl,omitempty"`
+	// ExpiresAt — момент истечения ссылки в формате RFC 3339.
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	// RedirectCode — код перенаправления (301, 302, 307 или 308); по умолчанию links.redirect_code.
+	RedirectCode int `json:"redirect_code,omitempty"`
+	// Password закрывает переход по ссылке паролем.
+	Password string `json:"password,omitempty"`
+	// Interstitial показывает страницу предпросмотра перед каждым переходом.
+	Interstitial bool `json:"interstitial,omitempty"`
+}
+
+// options преобразует запрос в параметры создания ссылки.
+func (r ShortenRequest) options() (service.ShortenOptions, error) {
+	opts := service.ShortenOptions{Alias: r.Alias, RedirectCode: r.RedirectCode, Password: r.Password,
+		Interstitial: r.Interstitial}
+
+	ttl, err := service.ParseExpiry(r.TTL, r.ExpiresAt)
+	if err != nil {
+		return opts, err
+	}
+	opts.TTL = ttl
+
+	return opts, nil
 }
 
 type ShortenMessage struct {
@@ -31,21 +69,87 @@ type ShortenMessage struct {
 	ShortLink   string `json:"short_link"`
 }
 
-func NewHandler(ctx context.Context, service *service.Service, metrics *initprometheus.PrometheusMetrics, logger *logrus.Logger, cfg *config.Config) (*Handler, error) {
+func NewHandler(ctx context.Context, service *service.Service, analytics *service.Analytics, apiKeys *service.APIKeys,
+	limiter *service.RateLimiter, metrics *initprometheus.PrometheusMetrics, logger *logrus.Logger, cfg *config.Config) (*Handler, error) {
+
+	access, err := newAccessSigner(cfg)
+	if err != nil {
+		return nil, err
+	}
 
 	return &Handler{
-		service: service,
-		metrics: metrics,
-		logger:  logger,
-		cfg:     cfg,
-		ctx:     ctx,
+		access:    access,
+		service:   service,
+		analytics: analytics,
+		apiKeys:   apiKeys,
+		limiter:   limiter,
+		metrics:   metrics,
+		logger:    logger,
+		cfg:       cfg,
+		ctx:       ctx,
 	}, nil
 }
 
 func (h *Handler) InitRoutes(app *fiber.App) {
+	// Лимит по IP применяется до проверки API-ключа, которая обращается к базе
+	auth := []fiber.Handler{h.authenticate}
+	if h.limiter != nil {
+		auth = []fiber.Handler{h.limitAuth, h.authenticate}
+	}
+
+	// Создание ссылок доступно анонимно, если auth.required не включён
+	create := append([]fiber.Handler{}, auth...)
+	if h.cfg.Auth.Required {
+		create = append(create, h.requireAuth)
+	}
+	redirect := []fiber.Handler{}
+	if h.limiter != nil {
+		create = append(create, h.rateLimit(service.RateLimitCreate))
+		redirect = append(redirect, h.rateLimit(service.RateLimitRedirect))
+	}
+
+	app.Use(bufferBody)
 	app.Get("/metrics", adaptor.HTTPHandler(promhttp.Handler()))
-	app.Post("/createShortLink", h.createShortLink)
-	app.Get("/:key", h.redirect)
+	app.Post("/createShortLink", append(create, h.createShortLink)...)
+	app.Post(batchPath, append(create, h.createShortLinks)...)
+
+	// Управление ссылками доступно только их владельцу
+	manage := append(append([]fiber.Handler{}, auth...), h.requireAuth)
+
+	app.Get("/api/links/:key/stats", append(manage, h.linkStats)...)
+	// QR-код не раскрывает ничего сверх самой короткой ссылки, поэтому доступен без ключа
+	app.Get("/api/links/:key/qr", append(redirect, h.linkQR)...)
+	app.Get("/api/links/:key", append(manage, h.getLink)...)
+	app.Patch("/api/links/:key", append(manage, h.updateLink)...)
+	app.Delete("/api/links/:key", append(manage, h.deleteLink)...)
+
+	app.Get("/p/:key", append(redirect, h.previewLink)...)
+	app.Get("/:key", append(redirect, h.redirect)...)
+	app.Post("/:key", append(redirect, h.unlockLink)...)
+}
+
+// bufferBody читает тело запроса в память, не больше BodyLimit. Сервер принимает тела потоком,
+// чтобы пакет NDJSON не держался в памяти целиком, поэтому для остальных запросов лимит
+// проверяется здесь.
+func bufferBody(c *fiber.Ctx) error {
+	stream := c.Context().RequestBodyStream()
+	if stream == nil || (c.Path() == batchPath && strings.HasPrefix(c.Get(fiber.HeaderContentType), mimeNDJSON)) {
+		return c.Next()
+	}
+
+	limit := c.App().Config().BodyLimit
+	body, err := io.ReadAll(io.LimitReader(stream, int64(limit)+1))
+	if err != nil {
+		return newRequestError(http.StatusBadRequest, "invalid_body", fmt.Sprintf("ошибка чтения тела запроса: %v", err))
+	}
+	if len(body) > limit {
+		// Непрочитанный остаток тела нельзя принять за следующий запрос в том же соединении
+		c.Context().SetConnectionClose()
+		return newRequestError(http.StatusRequestEntityTooLarge, "body_too_large",
+			fmt.Sprintf("размер тела запроса превышает лимит (%d байт)", limit))
+	}
+	c.Request().SetBodyRaw(body)
+	return c.Next()
 }
 
 func (h *Handler) restrictBodySize(c *fiber.Ctx, maxBodySize int) error {
@@ -56,60 +160,65 @@ func (h *Handler) restrictBodySize(c *fiber.Ctx, maxBodySize int) error {
 			"client_ip":  c.IP(),
 			"request_id": c.Get("X-Request-ID"),
 		}).Warn("Слишком большой размер тела запроса")
-		return respondError(c, false, h.logger, http.StatusBadRequest,
+		return newRequestError(http.StatusRequestEntityTooLarge, "body_too_large",
 			fmt.Sprintf("размер тела запроса (%d байт) "+
 				"превышает лимит (%d байт)", bodySize, maxBodySize))
 	}
 	return nil
 }
 
-func (h *Handler) checkOriginalURL(c *fiber.Ctx) (string, error) {
-	const maxBodySize = 2048
+func (h *Handler) checkShortenRequest(c *fiber.Ctx) (ShortenRequest, error) {
+	var req ShortenRequest
 
-	if err := h.restrictBodySize(c, maxBodySize); err != nil {
+	if err := h.restrictBodySize(c, maxShortenBodySize); err != nil {
 
-		return "", err
+		return req, err
 	}
 
 	if c.Get("Content-Type") != "application/json" {
-		return "", fmt.Errorf("неверный Content-Type != application/json")
+		return req, newRequestError(http.StatusUnsupportedMediaType, "unsupported_media_type", "Content-Type должен быть application/json")
 	}
 
-	var req ShortenRequest
 	if err := c.BodyParser(&req); err != nil {
 		if h.metrics != nil && h.metrics.CreateShortLinkTotal != nil {
 			h.metrics.CreateShortLinkTotal.WithLabelValues("error", "json_parse").Inc()
 		}
-		return "", fmt.Errorf("некорректное тело JSON: %v", err)
+		return req, newRequestError(http.StatusBadRequest, "invalid_json", fmt.Sprintf("некорректное тело JSON: %v", err))
 	}
 
 	if req.URL == "" {
-		return "", fmt.Errorf("URL обязателен")
+		return req, newRequestError(http.StatusBadRequest, "url_required", "URL обязателен")
 	}
 
-	return req.URL, nil
+	return req, nil
 }
 
 func (h *Handler) createShortLink(c *fiber.Ctx) error {
 
 	baseURL := h.cfg.Server.BaseURL
 
-	originalURL, err := h.checkOriginalURL(c)
+	req, err := h.checkShortenRequest(c)
 	if err != nil {
-		return respondError(c, true, h.logger, http.StatusBadRequest, err.Error())
+		return err
 	}
 
-	shortLink, err := h.service.ShortenURL(h.ctx, originalURL, baseURL)
+	opts, err := req.options()
 	if err != nil {
-		return respondError(c, true, h.logger, http.StatusBadRequest, err.Error())
+		return err
 	}
+	opts.OwnerID = ownerID(c)
 
-	err = h.service.SendMessageToDB(originalURL, shortLink)
+	link, err := h.service.ShortenURL(h.ctx, req.URL, baseURL, opts)
 	if err != nil {
-		return respondError(c, false, h.logger, http.StatusBadRequest, err.Error())
+		return err
 	}
 
-	shortURL := fmt.Sprintf("%s/%s", baseURL, shortLink)
+	link, err = h.service.SaveLink(h.ctx, link)
+	if err != nil {
+		return err
+	}
+
+	shortURL := fmt.Sprintf("%s/%s", baseURL, link.ShortLink)
 
 	return c.Status(http.StatusCreated).JSON(fiber.Map{
 		"shortURL": shortURL,
@@ -120,31 +229,62 @@ func (h *Handler) redirect(c *fiber.Ctx) error {
 
 	shortLink := c.Params("key")
 
-	originalURL, err := h.service.GetOriginalURL(h.ctx, shortLink)
+	redirect, err := h.service.GetRedirect(h.ctx, shortLink)
 	if err != nil {
-		if h.metrics != nil && h.metrics.CreateShortLinkTotal != nil {
-			h.metrics.RedirectTotal.WithLabelValues("error", "db_query").Inc()
+		if h.metrics != nil && h.metrics.RedirectTotal != nil {
+			switch {
+			case errors.Is(err, service.ErrExpired):
+				h.metrics.RedirectTotal.WithLabelValues("expired", "none").Inc()
+			case errors.Is(err, service.ErrNotFound):
+				h.metrics.RedirectTotal.WithLabelValues("not_found", "none").Inc()
+			default:
+				h.metrics.RedirectTotal.WithLabelValues("error", "db_query").Inc()
+			}
 		}
-		return respondError(c, false, h.logger, http.StatusBadRequest, fmt.Sprintf("internal server error get original URL: %v", err))
+		return err
 	}
-	if originalURL == "" {
-		if h.metrics != nil && h.metrics.CreateShortLinkTotal != nil {
-			h.metrics.RedirectTotal.WithLabelValues("not_found", "none").Inc()
+
+	// Кэшированная запись содержит хэш пароля, поэтому проверка выполняется и при попадании в кэш
+	if redirect.Protected() && !h.hasLinkAccess(c, shortLink, redirect) {
+		if h.metrics != nil && h.metrics.RedirectTotal != nil {
+			h.metrics.RedirectTotal.WithLabelValues("password_required", "none").Inc()
+		}
+		return h.renderPasswordForm(c, http.StatusOK, shortLink, "")
+	}
+
+	// Переход засчитывается, когда посетитель уходит со страницы предпросмотра, а не при её показе
+	if redirect.Interstitial && c.Query(proceedParam) == "" {
+		if h.metrics != nil && h.metrics.RedirectTotal != nil {
+			h.metrics.RedirectTotal.WithLabelValues("interstitial", "none").Inc()
 		}
-		return respondError(c, false, h.logger, http.StatusBadRequest, "Короткая ссылка не найдена")
+		return h.renderPreview(c, shortLink, redirect)
 	}
 
-	if h.metrics != nil && h.metrics.CreateShortLinkTotal != nil {
+	if h.metrics != nil && h.metrics.RedirectTotal != nil {
 		h.metrics.RedirectTotal.WithLabelValues("success", "none").Inc()
 	}
 
-	return c.Redirect(originalURL, http.StatusMovedPermanently)
+	// Значения fasthttp переиспользуются после ответа, поэтому для асинхронной записи их нужно скопировать
+	h.analytics.RecordClick(utils.CopyString(shortLink), utils.CopyString(c.Get(fiber.HeaderReferer)),
+		utils.CopyString(c.Get(fiber.HeaderUserAgent)), utils.CopyString(c.IP()))
+
+	return c.Redirect(redirect.URL, redirect.Code)
 }
 
-func respondError(c *fiber.Ctx, show bool, logger *logrus.Logger, status int, msg string) error {
-	logger.Error(msg)
-	if show {
-		return c.Status(status).JSON(fiber.Map{"error": msg})
+func (h *Handler) linkStats(c *fiber.Ctx) error {
+	shortLink := c.Params("key")
+
+	link, err := h.service.GetLink(h.ctx, ownerID(c), shortLink)
+	if err != nil {
+		return err
 	}
-	return c.Status(status).JSON(fiber.Map{"error": "internal server error"})
+
+	days := c.QueryInt("days", 0)
+
+	stats, err := h.analytics.Stats(h.ctx, link, days)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(http.StatusOK).JSON(stats)
 }
@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"context"
+	"io"
+	"linkreduction/internal/config"
+	"linkreduction/internal/models"
+	"linkreduction/internal/repository/memory"
+	"linkreduction/internal/service"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testBaseURL = "https://localhost:8080"
+	// testBodyLimit — BodyLimit тестового приложения, меньше пакета NDJSON из тестов.
+	testBodyLimit = 4096
+)
+
+// testApp — приложение с обработчиком поверх хранилищ в памяти.
+type testApp struct {
+	app *fiber.App
+	// links — хранилище ссылок; nil, если приложение создано поверх другого хранилища.
+	links  *memory.Link
+	clicks *memory.Click
+	stop   context.CancelFunc
+	done   chan struct{}
+}
+
+func newTestConfig() *config.Config {
+	return &config.Config{Server: config.Server{BaseURL: testBaseURL}}
+}
+
+func newTestApp(t *testing.T, cfg *config.Config) *testApp {
+	links := memory.NewLinkRepository()
+	app := newTestAppWithRepo(t, cfg, links)
+	app.links = links
+	return app
+}
+
+// newTestAppWithRepo создаёт приложение поверх заданного хранилища ссылок, например мока.
+func newTestAppWithRepo(t *testing.T, cfg *config.Config, links service.LinkRepo) *testApp {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	ctx, stop := context.WithCancel(context.Background())
+	clicks := memory.NewClickRepository()
+
+	var opts []service.Option
+	if cfg.Links.BatchMaxItems > 0 {
+		opts = append(opts, service.WithBatchMaxItems(cfg.Links.BatchMaxItems))
+	}
+	svc := service.NewLinkService(ctx, links, nil, nil, nil, opts...)
+	analytics := service.NewAnalytics(ctx, clicks, nil, nil, "")
+	done := make(chan struct{})
+	go func() {
+		analytics.Run(logger)
+		close(done)
+	}()
+	t.Cleanup(stop)
+
+	h, err := NewHandler(ctx, svc, analytics, service.NewAPIKeys(memory.NewAPIKeyRepository()), nil, nil, logger, cfg)
+	assert.NoError(t, err)
+
+	app := fiber.New(fiber.Config{ErrorHandler: h.ErrorHandler, StreamRequestBody: true, BodyLimit: testBodyLimit})
+	h.InitRoutes(app)
+
+	return &testApp{app: app, clicks: clicks, stop: stop, done: done}
+}
+
+// saveLink сохраняет ссылку в хранилище в обход API.
+func (a *testApp) saveLink(t *testing.T, link models.LinkURL) {
+	if link.RedirectCode == 0 {
+		link.RedirectCode = http.StatusFound
+	}
+	_, statuses, err := a.links.SaveLinks(context.Background(), []models.LinkURL{link}, false)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.InsertStatus{models.InsertInserted}, statuses)
+}
+
+// totalClicks останавливает запись переходов и возвращает число сохранённых переходов по ссылке.
+// После вызова приложение больше не записывает переходы.
+func (a *testApp) totalClicks(t *testing.T, shortLink string) int64 {
+	a.stop()
+	<-a.done
+
+	stats, err := a.clicks.GetStats(context.Background(), shortLink, time.Time{}, time.Now(), 1)
+	assert.NoError(t, err)
+	return stats.TotalClicks
+}
+
+func (a *testApp) do(t *testing.T, req *http.Request) *http.Response {
+	resp, err := a.app.Test(req, -1)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	t.Cleanup(func() { _ = resp.Body.Close() })
+	return resp
+}
@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"linkreduction/internal/models"
+	"net/http"
+	"time"
+)
+
+type LinkResponse struct {
+	ShortLink    string     `json:"short_link"`
+	ShortURL     string     `json:"shortURL"`
+	OriginalURL  string     `json:"original_url"`
+	Custom       bool       `json:"custom"`
+	CreatedAt    time.Time  `json:"created_at"`
+	ExpiresAt    *time.Time `json:"expires_at"`
+	Expired      bool       `json:"expired"`
+	RedirectCode int        `json:"redirect_code"`
+	Protected    bool       `json:"protected"`
+	Interstitial bool       `json:"interstitial"`
+}
+
+type UpdateLinkRequest struct {
+	URL string `json:"url"`
+}
+
+func (h *Handler) newLinkResponse(link *models.LinkURL) LinkResponse {
+	return LinkResponse{
+		ShortLink:    link.ShortLink,
+		ShortURL:     fmt.Sprintf("%s/%s", h.cfg.Server.BaseURL, link.ShortLink),
+		OriginalURL:  link.OriginalURL,
+		Custom:       link.Custom,
+		CreatedAt:    link.CreatedAt,
+		ExpiresAt:    link.ExpiresAt,
+		Expired:      link.Expired(time.Now()),
+		RedirectCode: link.RedirectCode,
+		Protected:    link.PasswordHash != "",
+		Interstitial: link.Interstitial,
+	}
+}
+
+func (h *Handler) getLink(c *fiber.Ctx) error {
+	link, err := h.service.GetLink(h.ctx, ownerID(c), c.Params("key"))
+	if err != nil {
+		return err
+	}
+
+	return c.Status(http.StatusOK).JSON(h.newLinkResponse(link))
+}
+
+func (h *Handler) updateLink(c *fiber.Ctx) error {
+	const maxBodySize = 2048
+
+	if err := h.restrictBodySize(c, maxBodySize); err != nil {
+		return err
+	}
+
+	var req UpdateLinkRequest
+	if err := c.BodyParser(&req); err != nil {
+		return newRequestError(http.StatusBadRequest, "invalid_json", fmt.Sprintf("некорректное тело JSON: %v", err))
+	}
+	if req.URL == "" {
+		return newRequestError(http.StatusBadRequest, "url_required", "URL обязателен")
+	}
+
+	link, err := h.service.UpdateOriginalURL(h.ctx, ownerID(c), c.Params("key"), req.URL, h.cfg.Server.BaseURL)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(http.StatusOK).JSON(h.newLinkResponse(link))
+}
+
+func (h *Handler) deleteLink(c *fiber.Ctx) error {
+	if err := h.service.DeleteLink(h.ctx, ownerID(c), c.Params("key")); err != nil {
+		return err
+	}
+
+	return c.SendStatus(http.StatusNoContent)
+}
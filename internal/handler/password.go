@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"bytes"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"html/template"
+	"linkreduction/internal/config"
+	"linkreduction/internal/models"
+	"linkreduction/internal/service"
+	"net/http"
+	"time"
+)
+
+const linkAccessCookie = "link_access"
+
+var passwordFormTemplate = template.Must(template.New("password").Parse(`<!DOCTYPE html>
+<html lang="ru">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex">
+<title>Ссылка защищена паролем</title>
+<style>
+body{font-family:sans-serif;display:flex;justify-content:center;margin-top:15vh}
+form{display:flex;flex-direction:column;gap:.75em;min-width:16em}
+.error{color:#b00020}
+</style>
+</head>
+<body>
+<form method="post" action="/{{.Key}}">
+<h2>Ссылка защищена паролем</h2>
+{{if .Error}}<p class="error">{{.Error}}</p>{{end}}
+<input type="password" name="password" placeholder="Пароль" autocomplete="current-password" required autofocus>
+<button type="submit">Перейти</button>
+</form>
+</body>
+</html>
+`))
+
+func newAccessSigner(cfg *config.Config) (*service.AccessSigner, error) {
+	return service.NewAccessSigner(cfg.Auth.LinkCookieSecret, cfg.Auth.LinkCookieTTL)
+}
+
+// linkAccessCookieName возвращает имя cookie доступа к ссылке. Cookie выдаётся на весь сайт,
+// чтобы её получали и переход, и предпросмотр /p/:key, поэтому ключ входит в имя, а не в путь.
+func linkAccessCookieName(shortLink string) string {
+	return linkAccessCookie + "_" + shortLink
+}
+
+// hasLinkAccess проверяет подписанную cookie доступа к защищённой ссылке.
+func (h *Handler) hasLinkAccess(c *fiber.Ctx, shortLink string, redirect *models.Redirect) bool {
+	value := c.Cookies(linkAccessCookieName(shortLink))
+	return value != "" && h.access.Verify(value, shortLink, redirect.PasswordHash, time.Now())
+}
+
+func (h *Handler) renderPasswordForm(c *fiber.Ctx, status int, shortLink, errMsg string) error {
+	var buf bytes.Buffer
+	if err := passwordFormTemplate.Execute(&buf, struct{ Key, Error string }{shortLink, errMsg}); err != nil {
+		return err
+	}
+
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	c.Type("html", "utf-8")
+	return c.Status(status).Send(buf.Bytes())
+}
+
+// unlockLink принимает пароль из формы и при успехе выдаёт cookie доступа.
+// Ответ 303 возвращает браузер на GET /:key, где выполняется обычный переход.
+func (h *Handler) unlockLink(c *fiber.Ctx) error {
+	shortLink := utils.CopyString(c.Params("key"))
+
+	redirect, err := h.service.GetRedirect(h.ctx, shortLink)
+	if err != nil {
+		return err
+	}
+	if !redirect.Protected() {
+		return c.Redirect("/"+shortLink, http.StatusSeeOther)
+	}
+
+	if !service.VerifyLinkPassword(redirect.PasswordHash, c.FormValue("password")) {
+		if h.metrics != nil && h.metrics.RedirectTotal != nil {
+			h.metrics.RedirectTotal.WithLabelValues("error", "wrong_password").Inc()
+		}
+		return h.renderPasswordForm(c, http.StatusUnauthorized, shortLink, "Неверный пароль")
+	}
+
+	value, expires := h.access.Sign(shortLink, redirect.PasswordHash, time.Now())
+	c.Cookie(&fiber.Cookie{
+		Name:     linkAccessCookieName(shortLink),
+		Value:    value,
+		Path:     "/",
+		Expires:  expires,
+		Secure:   c.Protocol() == "https",
+		HTTPOnly: true,
+		SameSite: fiber.CookieSameSiteLaxMode,
+	})
+
+	return c.Redirect("/"+shortLink, http.StatusSeeOther)
+}
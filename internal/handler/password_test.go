@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"io"
+	"linkreduction/internal/models"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func unlockRequest(shortLink, password string) *http.Request {
+	form := url.Values{"password": {password}}
+	req := httptest.NewRequest(http.MethodPost, "/"+shortLink, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return req
+}
+
+func TestHandler_UnlockLink(t *testing.T) {
+	const target = "https://example.com/secret"
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("s3cret"), bcrypt.MinCost)
+	assert.NoError(t, err)
+	app := newTestApp(t, newTestConfig())
+	app.saveLink(t, models.LinkURL{OriginalURL: target, ShortLink: "abc", Custom: true, PasswordHash: string(hash)})
+	app.saveLink(t, models.LinkURL{OriginalURL: target, ShortLink: "xyz", Custom: true, PasswordHash: string(hash)})
+
+	wrong := app.do(t, unlockRequest("abc", "wrong"))
+	assert.Equal(t, http.StatusUnauthorized, wrong.StatusCode)
+	assert.Empty(t, wrong.Cookies())
+
+	unlock := app.do(t, unlockRequest("abc", "s3cret"))
+	assert.Equal(t, http.StatusSeeOther, unlock.StatusCode)
+	assert.Equal(t, "/abc", unlock.Header.Get("Location"))
+	cookies := unlock.Cookies()
+	if !assert.Len(t, cookies, 1) {
+		return
+	}
+	cookie := cookies[0]
+	assert.Equal(t, linkAccessCookieName("abc"), cookie.Name)
+	assert.Equal(t, "/", cookie.Path)
+	assert.True(t, cookie.HttpOnly)
+
+	// Браузер отправляет cookie с путём "/" на любой адрес сайта, в том числе на /p/:key
+	tests := []struct {
+		name   string
+		path   string
+		status int
+		body   string
+	}{
+		{name: "redirect", path: "/abc", status: http.StatusFound},
+		{name: "preview", path: "/p/abc", status: http.StatusOK, body: target},
+		{name: "another protected link", path: "/p/xyz", status: http.StatusOK, body: `name="password"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			req.AddCookie(&http.Cookie{Name: cookie.Name, Value: cookie.Value})
+
+			resp := app.do(t, req)
+
+			assert.Equal(t, tt.status, resp.StatusCode)
+			if tt.status == http.StatusFound {
+				assert.Equal(t, target, resp.Header.Get("Location"))
+				return
+			}
+			body, err := io.ReadAll(resp.Body)
+			assert.NoError(t, err)
+			assert.Contains(t, string(body), tt.body)
+		})
+	}
+
+	t.Run("cookie of another link", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/xyz", nil)
+		req.AddCookie(&http.Cookie{Name: linkAccessCookieName("xyz"), Value: cookie.Value})
+
+		resp := app.do(t, req)
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		body, err := io.ReadAll(resp.Body)
+		assert.NoError(t, err)
+		assert.Contains(t, string(body), `name="password"`)
+	})
+}
@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"bytes"
+	"github.com/gofiber/fiber/v2"
+	"html/template"
+	"linkreduction/internal/models"
+	"net/http"
+	"net/url"
+)
+
+// proceedParam отмечает переход со страницы предпросмотра: он засчитывается как переход по ссылке.
+const proceedParam = "go"
+
+var previewTemplate = template.Must(template.New("preview").Parse(`<!DOCTYPE html>
+<html lang="ru">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex">
+<title>Переход по ссылке</title>
+<style>
+body{font-family:sans-serif;display:flex;justify-content:center;margin-top:15vh}
+main{max-width:36em;display:flex;flex-direction:column;gap:.75em}
+.url{word-break:break-all;color:#555}
+.host{font-weight:bold}
+a.continue{align-self:flex-start;padding:.5em 1.25em;background:#1a73e8;color:#fff;text-decoration:none;border-radius:4px}
+</style>
+</head>
+<body>
+<main>
+<p>Ссылка ведёт на сайт <span class="host">{{.Host}}</span></p>
+{{if .Title}}<h2>{{.Title}}</h2>{{end}}
+<p class="url">{{.URL}}</p>
+<a class="continue" href="/{{.Key}}?{{.Proceed}}=1" rel="noopener noreferrer nofollow">Продолжить</a>
+</main>
+</body>
+</html>
+`))
+
+// previewLink показывает адрес назначения ссылки без перехода.
+func (h *Handler) previewLink(c *fiber.Ctx) error {
+	shortLink := c.Params("key")
+
+	redirect, err := h.service.GetRedirect(h.ctx, shortLink)
+	if err != nil {
+		return err
+	}
+
+	if redirect.Protected() && !h.hasLinkAccess(c, shortLink, redirect) {
+		return h.renderPasswordForm(c, http.StatusOK, shortLink, "")
+	}
+
+	return h.renderPreview(c, shortLink, redirect)
+}
+
+// renderPreview показывает страницу предпросмотра. Кнопка перехода ведёт на короткую ссылку,
+// чтобы переход был учтён в статистике.
+func (h *Handler) renderPreview(c *fiber.Ctx, shortLink string, redirect *models.Redirect) error {
+	var host string
+	if u, err := url.Parse(redirect.URL); err == nil {
+		host = u.Hostname()
+	}
+
+	data := struct{ Key, Proceed, URL, Host, Title string }{
+		Key:     shortLink,
+		Proceed: proceedParam,
+		URL:     redirect.URL,
+		Host:    host,
+		Title:   h.service.PageTitle(h.ctx, redirect.URL),
+	}
+
+	var buf bytes.Buffer
+	if err := previewTemplate.Execute(&buf, data); err != nil {
+		return err
+	}
+
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	c.Type("html", "utf-8")
+	return c.Status(http.StatusOK).Send(buf.Bytes())
+}
@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"io"
+	"linkreduction/internal/models"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_Interstitial(t *testing.T) {
+	const target = "https://example.com/page"
+
+	tests := []struct {
+		name         string
+		interstitial bool
+		paths        []string
+		status       int
+		preview      bool
+		clicks       int64
+	}{
+		{name: "interstitial page is not a click", interstitial: true, paths: []string{"/abc"},
+			status: http.StatusOK, preview: true},
+		{name: "proceed from interstitial is a click", interstitial: true, paths: []string{"/abc", "/abc?go=1"},
+			status: http.StatusFound, clicks: 1},
+		{name: "preview of interstitial link is not a click", interstitial: true, paths: []string{"/p/abc"},
+			status: http.StatusOK, preview: true},
+		{name: "preview of plain link is not a click", paths: []string{"/p/abc"}, status: http.StatusOK, preview: true},
+		{name: "plain link redirects at once", paths: []string{"/abc"}, status: http.StatusFound, clicks: 1},
+		{name: "proceed flag on plain link", paths: []string{"/abc?go=1"}, status: http.StatusFound, clicks: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApp(t, newTestConfig())
+			app.saveLink(t, models.LinkURL{OriginalURL: target, ShortLink: "abc", Custom: true, Interstitial: tt.interstitial})
+
+			var resp *http.Response
+			for _, path := range tt.paths {
+				resp = app.do(t, httptest.NewRequest(http.MethodGet, path, nil))
+			}
+
+			assert.Equal(t, tt.status, resp.StatusCode)
+			body, err := io.ReadAll(resp.Body)
+			assert.NoError(t, err)
+			if tt.preview {
+				assert.Equal(t, "no-store", resp.Header.Get("Cache-Control"))
+				assert.Contains(t, string(body), target)
+				assert.Contains(t, string(body), `href="/abc?go=1"`)
+			} else {
+				assert.Equal(t, target, resp.Header.Get("Location"))
+			}
+			assert.Equal(t, tt.clicks, app.totalClicks(t, "abc"))
+		})
+	}
+
+	t.Run("preview of unknown link", func(t *testing.T) {
+		app := newTestApp(t, newTestConfig())
+
+		resp := app.do(t, httptest.NewRequest(http.MethodGet, "/p/missing", nil))
+
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+}
@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"linkreduction/internal/service"
+	"net/http"
+)
+
+// linkQR возвращает QR-код полной короткой ссылки.
+// Параметры запроса: format (png, svg), size (пиксели), margin (модули), ec (L, M, Q, H).
+func (h *Handler) linkQR(c *fiber.Ctx) error {
+	shortLink := c.Params("key")
+
+	opts := service.QROptions{
+		Format: c.Query("format"),
+		Size:   c.QueryInt("size", 0),
+		Level:  c.Query("ec"),
+	}
+	if c.Query("margin") != "" {
+		margin := c.QueryInt("margin", -1)
+		opts.Margin = &margin
+	}
+
+	if _, err := h.service.GetRedirect(h.ctx, shortLink); err != nil {
+		return err
+	}
+
+	image, err := service.QRCode(fmt.Sprintf("%s/%s", h.cfg.Server.BaseURL, shortLink), opts)
+	if err != nil {
+		return err
+	}
+
+	// Срок кэширования короткий: после удаления или истечения ссылки её QR-код не должен
+	// отдаваться из кэшей, а повторная проверка по ETag проходит через GetRedirect
+	sum := sha256.Sum256(image)
+	c.Set(fiber.HeaderETag, `"`+hex.EncodeToString(sum[:16])+`"`)
+	c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	if c.Fresh() {
+		return c.SendStatus(http.StatusNotModified)
+	}
+
+	c.Set(fiber.HeaderContentType, opts.ContentType())
+	return c.Status(http.StatusOK).Send(image)
+}
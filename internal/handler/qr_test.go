@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"io"
+	"linkreduction/internal/models"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func qrRequest(path, etag string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	return req
+}
+
+func TestHandler_LinkQR(t *testing.T) {
+	app := newTestApp(t, newTestConfig())
+	app.saveLink(t, models.LinkURL{OriginalURL: "https://example.com", ShortLink: "abc", Custom: true})
+
+	first := app.do(t, qrRequest("/api/links/abc/qr", ""))
+	assert.Equal(t, http.StatusOK, first.StatusCode)
+	assert.Equal(t, "image/png", first.Header.Get("Content-Type"))
+	assert.Equal(t, "public, max-age=300", first.Header.Get("Cache-Control"))
+	etag := first.Header.Get("ETag")
+	assert.NotEmpty(t, etag)
+
+	svg := app.do(t, qrRequest("/api/links/abc/qr?format=svg", ""))
+	assert.Equal(t, http.StatusOK, svg.StatusCode)
+	assert.NotEqual(t, etag, svg.Header.Get("ETag"))
+
+	tests := []struct {
+		name   string
+		path   string
+		etag   string
+		status int
+	}{
+		{name: "matching etag", path: "/api/links/abc/qr", etag: etag, status: http.StatusNotModified},
+		{name: "matching etag among several", path: "/api/links/abc/qr", etag: `"stale", ` + etag, status: http.StatusNotModified},
+		{name: "stale etag", path: "/api/links/abc/qr", etag: `"stale"`, status: http.StatusOK},
+		{name: "etag of another format", path: "/api/links/abc/qr?format=svg", etag: etag, status: http.StatusOK},
+		{name: "etag of unknown link", path: "/api/links/missing/qr", etag: etag, status: http.StatusNotFound},
+		{name: "invalid options", path: "/api/links/abc/qr?size=100000", status: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := app.do(t, qrRequest(tt.path, tt.etag))
+
+			assert.Equal(t, tt.status, resp.StatusCode)
+			body, err := io.ReadAll(resp.Body)
+			assert.NoError(t, err)
+			if tt.status == http.StatusNotModified {
+				assert.Empty(t, body)
+				assert.Equal(t, etag, resp.Header.Get("ETag"))
+			} else {
+				assert.NotEmpty(t, body)
+			}
+		})
+	}
+}
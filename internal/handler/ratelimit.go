@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"linkreduction/internal/repository/redis"
+	"linkreduction/internal/service"
+	"math"
+	"strconv"
+)
+
+// rateLimit ограничивает частоту запросов клиента в области scope. Если хранилище
+// лимитов недоступно, запрос пропускается, чтобы сбой Redis не останавливал сервис.
+func (h *Handler) rateLimit(scope string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		return h.allow(c, scope, clientKey(c))
+	}
+}
+
+// limitAuth ограничивает по IP запросы с API-ключом до его проверки: подбор ключей
+// не должен обращаться к базе чаще лимита.
+func (h *Handler) limitAuth(c *fiber.Ctx) error {
+	if apiKeyFromRequest(c) == "" {
+		return c.Next()
+	}
+	return h.allow(c, service.RateLimitAuth, "ip:"+c.IP())
+}
+
+func (h *Handler) allow(c *fiber.Ctx, scope, client string) error {
+	retryAfter, err := h.limiter.Allow(h.ctx, scope, client)
+	if errors.Is(err, service.ErrRateLimited) {
+		seconds := int(math.Ceil(retryAfter.Seconds()))
+		c.Set(fiber.HeaderRetryAfter, strconv.Itoa(max(seconds, 1)))
+		return err
+	}
+	// Пока автомат защиты Redis разомкнут, каждый запрос получал бы то же предупреждение;
+	// о размыкании сообщает сам автомат
+	if err != nil && !errors.Is(err, redis.ErrCircuitOpen) {
+		h.logger.WithField("scope", scope).Warn(err)
+	}
+	return c.Next()
+}
+
+// clientKey определяет клиента для лимита: API-ключ, если он передан, иначе IP.
+func clientKey(c *fiber.Ctx) string {
+	if id, ok := c.Locals(localsAPIKeyID).(int64); ok {
+		return fmt.Sprintf("apikey:%d", id)
+	}
+	return "ip:" + c.IP()
+}
@@ -0,0 +1,130 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/IBM/sarama"
+	"github.com/sirupsen/logrus"
+	"io"
+	"linkreduction/internal/config"
+	"linkreduction/internal/const"
+	"linkreduction/internal/models"
+	"linkreduction/internal/service"
+	"log"
+	"time"
+)
+
+const (
+	clickBatchSize    = 200
+	clickBatchTimeout = 5 * time.Second
+)
+
+// ClickConsumer читает переходы по ссылкам из топика link-clicks и сохраняет их пачками.
+// Смещения фиксируются только после успешной записи пачки в базу.
+type ClickConsumer struct {
+	ctx          context.Context
+	logger       *logrus.Logger
+	analytics    *service.Analytics
+	cfg          *config.Config
+	batchSize    int
+	batchTimeout time.Duration
+}
+
+func NewClickConsumer(ctx context.Context, logger *logrus.Logger, analytics *service.Analytics, cfg *config.Config) *ClickConsumer {
+	return &ClickConsumer{
+		ctx:          ctx,
+		logger:       logger,
+		analytics:    analytics,
+		cfg:          cfg,
+		batchSize:    clickBatchSize,
+		batchTimeout: clickBatchTimeout,
+	}
+}
+
+func (c *ClickConsumer) ConsumeClicks() error {
+	kafkaBrokers, err := kafkaBrokers(c.cfg)
+	if err != nil {
+		return err
+	}
+
+	consumerGroup, err := connectConsumerGroup(kafkaBrokers, message.ClicksGroup, false)
+	if err != nil {
+		//sarama logger off
+		sarama.Logger = log.New(io.Discard, "", 0)
+		return nil
+	}
+
+	consumeLoop(c.ctx, c.logger, consumerGroup, message.ClicksTopic, c)
+	return nil
+}
+
+// ConsumeClaim записывает переходы пачками. Если запись не удалась, пачка сохраняется и записывается
+// повторно по таймеру, а заполненная пачка не принимает новых сообщений, пока не будет записана:
+// при недоступной базе потребление приостанавливается, и память не растёт.
+func (c *ClickConsumer) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	ticker := time.NewTicker(c.batchTimeout)
+	defer ticker.Stop()
+
+	batch := make([]models.Click, 0, c.batchSize)
+	var last *sarama.ConsumerMessage
+
+	flush := func() {
+		if last == nil {
+			return
+		}
+		if err := c.analytics.InsertClicks(session.Context(), batch); err != nil {
+			c.logger.WithFields(logrus.Fields{
+				"batch_size": len(batch),
+			}).Error("Ошибка при вставке переходов: ", err)
+			return
+		}
+		session.MarkMessage(last, "")
+		batch = batch[:0]
+		last = nil
+	}
+
+	for {
+		messages := claim.Messages()
+		if len(batch) >= c.batchSize {
+			messages = nil
+		}
+
+		select {
+		case msg, ok := <-messages:
+			if !ok {
+				flush()
+				return nil
+			}
+			last = msg
+
+			var clickMsg message.ClickMessage
+			if err := json.Unmarshal(msg.Value, &clickMsg); err != nil {
+				c.logger.WithError(err).Warn("Некорректное сообщение о переходе")
+				continue
+			}
+			batch = append(batch, models.Click{
+				ShortLink: clickMsg.ShortLink,
+				ClickedAt: clickMsg.ClickedAt,
+				Referrer:  clickMsg.Referrer,
+				UserAgent: clickMsg.UserAgent,
+				IPHash:    clickMsg.IPHash,
+			})
+			if len(batch) >= c.batchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-session.Context().Done():
+			flush()
+			return nil
+		}
+	}
+}
+
+func (c *ClickConsumer) Setup(_ sarama.ConsumerGroupSession) error {
+	return nil
+}
+
+func (c *ClickConsumer) Cleanup(_ sarama.ConsumerGroupSession) error {
+	return nil
+}
@@ -0,0 +1,50 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"linkreduction/internal/const"
+	"linkreduction/internal/mocks"
+	"linkreduction/internal/models"
+	"linkreduction/internal/service"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func clickValue(t *testing.T, shortLink string) []byte {
+	value, err := json.Marshal(message.ClickMessage{ShortLink: shortLink})
+	assert.NoError(t, err)
+	return value
+}
+
+func TestClickConsumer_ConsumeClaim(t *testing.T) {
+	t.Run("full batch stops consumption until it is stored", func(t *testing.T) {
+		repo := new(mocks.ClickRepo)
+		logger := logrus.New()
+		logger.SetOutput(io.Discard)
+		c := NewClickConsumer(context.Background(), logger, service.NewAnalytics(context.Background(), repo, nil, nil, ""), nil)
+		c.batchSize = 2
+		c.batchTimeout = 10 * time.Millisecond
+		session := newTestSession(context.Background())
+		claim, pc := newTestClaim(t, clickValue(t, "a"), clickValue(t, "b"), clickValue(t, "c"))
+
+		var sizes []int
+		repo.On("InsertClicks", mock.Anything, mock.Anything).Return(fmt.Errorf("db down")).Twice().
+			Run(func(args mock.Arguments) { sizes = append(sizes, len(args.Get(1).([]models.Click))) })
+		repo.On("InsertClicks", mock.Anything, mock.Anything).Return(nil).
+			Run(func(args mock.Arguments) { sizes = append(sizes, len(args.Get(1).([]models.Click))) })
+
+		pc.AsyncClose()
+		assert.NoError(t, c.ConsumeClaim(session, claim))
+
+		assert.Equal(t, []int{2, 2, 2, 1}, sizes, "пачка не должна расти, пока база недоступна")
+		marked, _ := session.offsets()
+		assert.Equal(t, int64(3), marked)
+	})
+}
@@ -16,89 +16,129 @@ import (
 	"time"
 )
 
+// Consumer сохраняет ссылки из топика shorten-urls. Каждая партиция разбирается своей пачкой,
+// а смещение фиксируется только после того, как пачка записана в Postgres или отправлена в DLQ.
 type Consumer struct {
-	ctx         context.Context
-	producer    sarama.SyncProducer
-	logger      *logrus.Logger
-	linkService *service.Service
-	cfg         *config.Config
-	batchChan   chan models.LinkURL
+	ctx          context.Context
+	cancel       context.CancelFunc
+	done         chan struct{}
+	producer     sarama.SyncProducer
+	logger       *logrus.Logger
+	linkService  *service.Service
+	cfg          *config.Config
+	retry        retryPolicy
+	batchSize    int
+	batchTimeout time.Duration
+}
+
+// linkMessage — ссылка вместе с исходным сообщением, которое попадёт в DLQ, если ссылку не удастся сохранить.
+type linkMessage struct {
+	link models.LinkURL
+	msg  *sarama.ConsumerMessage
 }
 
 const (
-	batchSize                 = 50
+	defaultBatchSize          = 50
 	batchTimeout              = 10 * time.Second
 	attemptCreateConsumeGroup = 10
+
+	// flushTimeout ограничивает запись последней пачки при потере партиции или остановке.
+	flushTimeout = 5 * time.Second
 )
 
 func NewConsumer(ctx context.Context, producer sarama.SyncProducer,
 	logger *logrus.Logger, linkService *service.Service, cfg *config.Config) *Consumer {
 
-	batchChan := make(chan models.LinkURL)
+	ctx, cancel := context.WithCancel(ctx)
+
+	batchSize := cfg.Kafka.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
 
 	return &Consumer{
-		ctx:         ctx,
-		producer:    producer,
-		logger:      logger,
-		linkService: linkService,
-		cfg:         cfg,
-		batchChan:   batchChan,
+		ctx:          ctx,
+		cancel:       cancel,
+		done:         make(chan struct{}),
+		producer:     producer,
+		logger:       logger,
+		linkService:  linkService,
+		cfg:          cfg,
+		retry:        newRetryPolicy(cfg.Kafka.Retry),
+		batchSize:    batchSize,
+		batchTimeout: batchTimeout,
 	}
 }
 
-func (c *Consumer) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+func (c *Consumer) ConsumeShortenURLs() error {
+	defer close(c.done)
+
+	kafkaBrokers, err := kafkaBrokers(c.cfg)
+	if err != nil {
+		return err
+	}
 
-	go c.processBatchInsert(c.ctx, c.batchChan, batchSize, batchTimeout)
+	consumerGroup, err := connectConsumerGroup(kafkaBrokers, message.ShortenURLsGroup, true)
+	if err != nil {
+		//sarama logger off
+		sarama.Logger = log.New(io.Discard, "", 0)
+		return nil
+	}
 
-	err := c.processKafkaMessages(session, claim)
-	return err
+	consumeLoop(c.ctx, c.logger, consumerGroup, message.ShortenURLsTopic, c)
+	return nil
 }
 
-func (c *Consumer) ConsumeShortenURLs() error {
-	kafkaEnv := c.cfg.Kafka.Brokers
+func kafkaBrokers(cfg *config.Config) ([]string, error) {
+	kafkaEnv := cfg.Kafka.Brokers
 	kafkaBrokers := strings.Split(kafkaEnv, ",")
 
 	if len(kafkaBrokers) == 0 || kafkaBrokers[0] == "" {
-		return fmt.Errorf("переменная окружения KAFKA_BROKERS пуста или не задана, пропуск создания consumer group")
+		return nil, fmt.Errorf("переменная окружения KAFKA_BROKERS пуста или не задана, пропуск создания consumer group")
 	}
 
 	for _, broker := range kafkaBrokers {
 		if strings.TrimSpace(broker) == "" {
-			return fmt.Errorf("обнаружен пустой адрес брокера Kafka, пропуск создания consumer group")
+			return nil, fmt.Errorf("обнаружен пустой адрес брокера Kafka, пропуск создания consumer group")
 		}
 	}
+	return kafkaBrokers, nil
+}
 
+// connectConsumerGroup подключается к группе. При manualCommit смещения фиксируются только
+// явным вызовом session.Commit.
+func connectConsumerGroup(kafkaBrokers []string, groupID string, manualCommit bool) (sarama.ConsumerGroup, error) {
 	sconfig := sarama.NewConfig()
 	sconfig.Consumer.Offsets.Initial = sarama.OffsetOldest
+	sconfig.Consumer.Offsets.AutoCommit.Enable = !manualCommit
 
 	var consumerGroup sarama.ConsumerGroup
 	var err error
 	for i := 0; i < attemptCreateConsumeGroup; i++ {
-		consumerGroup, err = sarama.NewConsumerGroup(kafkaBrokers, message.ShortenURLsGroup, sconfig)
+		consumerGroup, err = sarama.NewConsumerGroup(kafkaBrokers, groupID, sconfig)
 		if err == nil {
-			break
+			return consumerGroup, nil
 		}
 		time.Sleep(2 * time.Second)
 	}
-	if err != nil {
-		//sarama logger off
-		sarama.Logger = log.New(io.Discard, "", 0)
-		return nil
-	}
+	return nil, err
+}
 
+// consumeLoop потребляет топик до отмены контекста, переподключаясь после ошибок.
+func consumeLoop(ctx context.Context, logger *logrus.Logger, consumerGroup sarama.ConsumerGroup, topic string, handler sarama.ConsumerGroupHandler) {
 	for {
 		select {
-		case <-c.ctx.Done():
-			c.logger.Info("Остановка потребления сообщений Kafka")
+		case <-ctx.Done():
+			logger.Info("Остановка потребления сообщений Kafka")
 			err := consumerGroup.Close()
 			if err != nil {
-				c.logger.WithError(err).Error("Ошибка при закрытии Kafka consumer group")
+				logger.WithError(err).Error("Ошибка при закрытии Kafka consumer group")
 			}
-			return nil
+			return
 		default:
-			err := consumerGroup.Consume(c.ctx, []string{message.ShortenURLsTopic}, c)
+			err := consumerGroup.Consume(ctx, []string{topic}, handler)
 			if err != nil {
-				c.logger.Error("Ошибка потребления сообщений Kafka")
+				logger.Error("Ошибка потребления сообщений Kafka")
 				time.Sleep(5 * time.Second)
 			}
 		}
@@ -110,91 +150,178 @@ func (c *Consumer) deserializeMessage(msg *sarama.ConsumerMessage) (ret message.
 	return
 }
 
-func (c *Consumer) processBatchInsert(ctx context.Context, batchChan <-chan models.LinkURL, batchSize int, batchTimeout time.Duration) {
-	ticker := time.NewTicker(batchTimeout)
+// Setup вызывается после назначения партиций, до запуска ConsumeClaim.
+func (c *Consumer) Setup(session sarama.ConsumerGroupSession) error {
+	c.logger.WithFields(logrus.Fields{
+		"member_id":  session.MemberID(),
+		"generation": session.GenerationID(),
+		"claims":     session.Claims(),
+	}).Info("Назначены партиции shorten-urls")
+	return nil
+}
+
+// Cleanup вызывается при ребалансировке после завершения всех ConsumeClaim: к этому моменту
+// пачки записаны, и отмеченные смещения фиксируются до передачи партиций другому экземпляру.
+func (c *Consumer) Cleanup(session sarama.ConsumerGroupSession) error {
+	session.Commit()
+	c.logger.WithFields(logrus.Fields{
+		"member_id":  session.MemberID(),
+		"generation": session.GenerationID(),
+	}).Info("Партиции shorten-urls освобождены")
+	return nil
+}
+
+// ConsumeClaim разбирает весь поток сообщений партиции. Пачка записывается при заполнении,
+// по таймеру и при завершении сессии; ошибка записи завершает сессию без фиксации смещения,
+// и необработанные сообщения будут прочитаны повторно.
+func (c *Consumer) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	ticker := time.NewTicker(c.batchTimeout)
 	defer ticker.Stop()
 
-	batch := make([]models.LinkURL, 0, batchSize)
+	batch := make([]linkMessage, 0, c.batchSize)
+	// last — последнее прочитанное сообщение партиции, включая отправленные в DLQ
+	var last *sarama.ConsumerMessage
+
+	flush := func(ctx context.Context) error {
+		if last == nil {
+			return nil
+		}
+		if err := c.insertBatch(ctx, batch); err != nil {
+			return err
+		}
+		session.MarkMessage(last, "")
+		session.Commit()
+		batch = batch[:0]
+		last = nil
+		return nil
+	}
+
+	// finalFlush записывает остаток пачки, когда контекст сессии уже может быть отменён
+	finalFlush := func() error {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(session.Context()), flushTimeout)
+		defer cancel()
+		return flush(ctx)
+	}
+
 	for {
 		select {
-		case msg, ok := <-batchChan:
+		case msg, ok := <-claim.Messages():
 			if !ok {
-				if len(batch) > 0 {
-					if err := c.linkService.InsertBatch(ctx, batch); err != nil {
-						c.logger.WithFields(logrus.Fields{
-							"batch_size": len(batch),
-						}).Error("Ошибка при вставке последнего батча: ", err)
-					}
-				}
-				c.logger.Info("Канал batchChan закрыт, завершение пакетной вставки")
-				return
+				return finalFlush()
 			}
-			batch = append(batch, msg)
-			if len(batch) >= batchSize {
-				if err := c.linkService.InsertBatch(ctx, batch); err != nil {
-					c.logger.WithFields(logrus.Fields{
-						"batch_size": len(batch),
-					}).Error("Ошибка при вставке батча: ", err)
+			last = msg
+
+			shortenMsg, err := c.deserializeMessage(msg)
+			if err != nil {
+				if err := c.sendToDLQ(msg, fmt.Errorf("ошибка десериализации сообщения: %w", err), 1); err != nil {
+					return err
 				}
-				batch = batch[:0]
-				ticker.Reset(batchTimeout)
+				continue
 			}
-		case <-ticker.C:
-			if len(batch) > 0 {
-				if err := c.linkService.InsertBatch(ctx, batch); err != nil {
-					c.logger.WithFields(logrus.Fields{
-						"batch_size": len(batch),
-					}).Error("Ошибка при вставке батча по таймеру: ", err)
+			batch = append(batch, linkMessage{link: shortenMsg.Link(), msg: msg})
+
+			if len(batch) >= c.batchSize {
+				if err := flush(session.Context()); err != nil {
+					return err
 				}
-				batch = batch[:0]
-				ticker.Reset(batchTimeout)
+				ticker.Reset(c.batchTimeout)
 			}
-		case <-ctx.Done():
-			if len(batch) > 0 {
-				if err := c.linkService.InsertBatch(context.Background(), batch); err != nil {
-					c.logger.WithFields(logrus.Fields{
-						"batch_size": len(batch),
-					}).Error("Ошибка при вставке батча при завершении: ", err)
-				}
+		case <-ticker.C:
+			if err := flush(session.Context()); err != nil {
+				return err
 			}
-			c.logger.Debug("Контекст отменён, завершение пакетной вставки")
-			return
+		case <-session.Context().Done():
+			return finalFlush()
 		}
 	}
 }
 
-func (c *Consumer) processKafkaMessages(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
-	for consumerMessage := range claim.Messages() {
-		shortenMsg, err := c.deserializeMessage(consumerMessage)
+// insertBatch сохраняет пачку с повторами. Если повторы исчерпаны из-за данных пачки, ссылки
+// сохраняются по одной, и в DLQ отправляются только те, которые отклонены из-за своих данных.
+// Временная ошибка (база недоступна, соединение прервано) возвращается без DLQ: пачка не
+// фиксируется и будет прочитана повторно. Ошибка означает, что часть сообщений не сохранена
+// и не попала в DLQ.
+func (c *Consumer) insertBatch(ctx context.Context, batch []linkMessage) error {
+	if len(batch) == 0 {
+		return nil
+	}
+
+	links := make([]models.LinkURL, len(batch))
+	for i, m := range batch {
+		links[i] = m.link
+	}
+
+	var statuses []models.InsertStatus
+	attempts, err := c.retry.do(ctx, func() (err error) {
+		statuses, err = c.linkService.InsertBatch(ctx, links)
+		return err
+	})
+	if err == nil {
+		c.logConflicts(batch, statuses)
+		return nil
+	}
+	c.logger.WithFields(logrus.Fields{
+		"batch_size": len(batch),
+		"attempts":   attempts,
+	}).Error("Ошибка при вставке батча: ", err)
+
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if service.Retryable(err) {
+		return err
+	}
+	for _, m := range batch {
+		statuses, err := c.linkService.InsertBatch(ctx, []models.LinkURL{m.link})
 		if err != nil {
+			if service.Retryable(err) {
+				return err
+			}
+			if err := c.sendToDLQ(m.msg, err, attempts+1); err != nil {
+				return err
+			}
 			continue
 		}
-
-		select {
-		case c.batchChan <- models.LinkURL{OriginalURL: shortenMsg.OriginalURL, ShortLink: shortenMsg.ShortLink}:
-			session.MarkMessage(consumerMessage, "")
-			c.logger.WithFields(logrus.Fields{
-				"original_url": shortenMsg.OriginalURL,
-				"short_link":   shortenMsg.ShortLink,
-			}).Info("Отправлено сообщение в batchChan")
-			return nil
-		case <-c.ctx.Done():
-			c.logger.Info("Контекст отменён, прекращение отправки в batchChan")
-			return c.ctx.Err()
-		}
-
+		c.logConflicts([]linkMessage{m}, statuses)
 	}
 	return nil
 }
 
-func (c *Consumer) Setup(_ sarama.ConsumerGroupSession) error {
-	return nil
+// logConflicts журналирует ссылки, которые не сохранены, потому что ключ уже занят другим URL.
+// Повторная обработка их не сохранит, поэтому в DLQ они не отправляются.
+func (c *Consumer) logConflicts(batch []linkMessage, statuses []models.InsertStatus) {
+	for i, status := range statuses {
+		if status != models.InsertConflict {
+			continue
+		}
+		c.logger.WithFields(logrus.Fields{
+			"short_link": batch[i].link.ShortLink,
+			"partition":  batch[i].msg.Partition,
+			"offset":     batch[i].msg.Offset,
+		}).Warn("Ссылка не сохранена: ключ или URL заняты другой ссылкой")
+	}
 }
 
-func (c *Consumer) Cleanup(_ sarama.ConsumerGroupSession) error {
+// sendToDLQ отправляет необработанное сообщение в shorten-urls.dlq.
+func (c *Consumer) sendToDLQ(msg *sarama.ConsumerMessage, reason error, attempts int) error {
+	fields := logrus.Fields{
+		"partition": msg.Partition,
+		"offset":    msg.Offset,
+		"reason":    reason.Error(),
+	}
+	if c.producer == nil {
+		return fmt.Errorf("kafka producer недоступен, сообщение %d/%d не отправлено в DLQ: %w", msg.Partition, msg.Offset, reason)
+	}
+
+	if _, _, err := c.producer.SendMessage(deadLetter(msg, message.ShortenURLsDLQTopic, reason, attempts)); err != nil {
+		return fmt.Errorf("ошибка отправки сообщения %d/%d в DLQ: %w", msg.Partition, msg.Offset, err)
+	}
+	c.logger.WithFields(fields).Warn("Сообщение отправлено в DLQ")
 	return nil
 }
 
+// CloseKafka останавливает потребление и дожидается записи последних пачек и фиксации смещений.
 func (c *Consumer) CloseKafka() {
-	close(c.batchChan)
+	c.cancel()
+	<-c.done
 }
@@ -0,0 +1,316 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"linkreduction/internal/config"
+	"linkreduction/internal/const"
+	"linkreduction/internal/mocks"
+	"linkreduction/internal/models"
+	"linkreduction/internal/service"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	saramamocks "github.com/IBM/sarama/mocks"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// testSession — сессия группы, запоминающая отмеченные и зафиксированные смещения.
+type testSession struct {
+	ctx context.Context
+
+	mu        sync.Mutex
+	marked    int64
+	committed int64
+}
+
+func newTestSession(ctx context.Context) *testSession {
+	return &testSession{ctx: ctx, marked: -1, committed: -1}
+}
+
+func (s *testSession) Claims() map[string][]int32 {
+	return map[string][]int32{message.ShortenURLsTopic: {0}}
+}
+func (s *testSession) MemberID() string                         { return "member" }
+func (s *testSession) GenerationID() int32                      { return 1 }
+func (s *testSession) ResetOffset(string, int32, int64, string) {}
+func (s *testSession) Context() context.Context                 { return s.ctx }
+
+func (s *testSession) MarkOffset(_ string, _ int32, offset int64, _ string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.marked = offset
+}
+
+func (s *testSession) MarkMessage(msg *sarama.ConsumerMessage, _ string) {
+	s.MarkOffset(msg.Topic, msg.Partition, msg.Offset+1, "")
+}
+
+func (s *testSession) Commit() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.committed = s.marked
+}
+
+func (s *testSession) offsets() (marked, committed int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.marked, s.committed
+}
+
+// testClaim отдаёт сообщения партиции из мока sarama.
+type testClaim struct {
+	sarama.PartitionConsumer
+}
+
+func (c testClaim) Topic() string        { return message.ShortenURLsTopic }
+func (c testClaim) Partition() int32     { return 0 }
+func (c testClaim) InitialOffset() int64 { return 0 }
+
+// newTestClaim возвращает партицию с сообщениями values; закрытие партиции имитирует ребалансировку.
+func newTestClaim(t *testing.T, values ...[]byte) (testClaim, *saramamocks.PartitionConsumer) {
+	consumer := saramamocks.NewConsumer(t, nil)
+	expected := consumer.ExpectConsumePartition(message.ShortenURLsTopic, 0, sarama.OffsetOldest)
+	for _, value := range values {
+		expected.YieldMessage(&sarama.ConsumerMessage{Key: []byte("key"), Value: value})
+	}
+
+	pc, err := consumer.ConsumePartition(message.ShortenURLsTopic, 0, sarama.OffsetOldest)
+	assert.NoError(t, err)
+	return testClaim{pc}, expected
+}
+
+func newTestConsumer(producer sarama.SyncProducer, repo *mocks.LinkRepo) *Consumer {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	cache := new(mocks.LinkCache)
+	cache.On("SetShortLink", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	linkService := service.NewLinkService(context.Background(), repo, cache, nil, nil)
+	c := NewConsumer(context.Background(), producer, logger, linkService,
+		&config.Config{Kafka: config.Kafka{Retry: config.KafkaRetry{MaxAttempts: 2, InitialBackoff: time.Millisecond}}})
+	c.batchSize = 2
+	return c
+}
+
+func shortenValue(t *testing.T, shortLink string) []byte {
+	value, err := json.Marshal(message.NewShortenMessage(models.LinkURL{OriginalURL: "https://" + shortLink + ".com", ShortLink: shortLink}))
+	assert.NoError(t, err)
+	return value
+}
+
+// insertAll — ответ хранилища, сохранившего все ссылки пачки.
+func insertAll(_ context.Context, links []models.LinkURL) ([]models.InsertStatus, error) {
+	statuses := make([]models.InsertStatus, len(links))
+	for i := range statuses {
+		statuses[i] = models.InsertInserted
+	}
+	return statuses, nil
+}
+
+func TestNewConsumer_BatchSize(t *testing.T) {
+	tests := []struct {
+		name      string
+		batchSize int
+		expected  int
+	}{
+		{name: "default", expected: defaultBatchSize},
+		{name: "negative falls back to default", batchSize: -1, expected: defaultBatchSize},
+		{name: "configured", batchSize: 2000, expected: 2000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewConsumer(context.Background(), nil, logrus.New(), nil, &config.Config{Kafka: config.Kafka{BatchSize: tt.batchSize}})
+			assert.Equal(t, tt.expected, c.batchSize)
+		})
+	}
+}
+
+func TestConsumer_ConsumeClaim(t *testing.T) {
+	t.Run("consumes the whole claim and marks offsets after insert", func(t *testing.T) {
+		repo := new(mocks.LinkRepo)
+		c := newTestConsumer(nil, repo)
+		session := newTestSession(context.Background())
+		claim, pc := newTestClaim(t, shortenValue(t, "a"), shortenValue(t, "b"), shortenValue(t, "c"))
+
+		var inserted []string
+		repo.On("InsertBatch", mock.Anything, mock.Anything).Return(insertAll).Run(func(args mock.Arguments) {
+			marked, _ := session.offsets()
+			links := args.Get(1).([]models.LinkURL)
+			// Смещение пачки ещё не отмечено, пока она не записана
+			assert.Equal(t, int64(len(inserted)), max(marked, 0))
+			for _, link := range links {
+				inserted = append(inserted, link.ShortLink)
+			}
+		})
+
+		pc.AsyncClose()
+		assert.NoError(t, c.ConsumeClaim(session, claim))
+
+		assert.Equal(t, []string{"a", "b", "c"}, inserted)
+		repo.AssertNumberOfCalls(t, "InsertBatch", 2)
+		marked, committed := session.offsets()
+		assert.Equal(t, int64(3), marked)
+		assert.Equal(t, int64(3), committed)
+	})
+
+	t.Run("poison message goes to DLQ with error headers", func(t *testing.T) {
+		repo := new(mocks.LinkRepo)
+		producer := saramamocks.NewSyncProducer(t, nil)
+		c := newTestConsumer(producer, repo)
+		session := newTestSession(context.Background())
+		claim, pc := newTestClaim(t, []byte("not json"), shortenValue(t, "a"))
+
+		repo.On("InsertBatch", mock.Anything, mock.Anything).Return(insertAll).Once()
+		producer.ExpectSendMessageWithMessageCheckerFunctionAndSucceed(func(msg *sarama.ProducerMessage) error {
+			headers := make(map[string]string)
+			for _, h := range msg.Headers {
+				headers[string(h.Key)] = string(h.Value)
+			}
+			assert.Equal(t, message.ShortenURLsDLQTopic, msg.Topic)
+			assert.Contains(t, headers[HeaderDLQError], "десериализации")
+			assert.Equal(t, message.ShortenURLsTopic, headers[HeaderDLQTopic])
+			assert.Equal(t, "0", headers[HeaderDLQOffset])
+			return nil
+		})
+
+		pc.AsyncClose()
+		assert.NoError(t, c.ConsumeClaim(session, claim))
+
+		_, committed := session.offsets()
+		assert.Equal(t, int64(2), committed)
+		assert.NoError(t, producer.Close())
+	})
+
+	t.Run("rejected batch is split and only the rejected link goes to DLQ", func(t *testing.T) {
+		repo := new(mocks.LinkRepo)
+		producer := saramamocks.NewSyncProducer(t, nil)
+		c := newTestConsumer(producer, repo)
+		session := newTestSession(context.Background())
+		claim, pc := newTestClaim(t, shortenValue(t, "a"), shortenValue(t, "b"))
+
+		badRow := &pgconn.PgError{Code: "22001", Message: "value too long for type character varying(2048)"}
+		repo.On("InsertBatch", mock.Anything, mock.MatchedBy(func(links []models.LinkURL) bool {
+			return len(links) == 2
+		})).Return(nil, badRow).Once()
+		repo.On("InsertBatch", mock.Anything, []models.LinkURL{{OriginalURL: "https://a.com", ShortLink: "a", RedirectCode: 301}}).Return(insertAll).Once()
+		repo.On("InsertBatch", mock.Anything, []models.LinkURL{{OriginalURL: "https://b.com", ShortLink: "b", RedirectCode: 301}}).
+			Return(nil, badRow).Once()
+		producer.ExpectSendMessageWithMessageCheckerFunctionAndSucceed(func(msg *sarama.ProducerMessage) error {
+			value, _ := msg.Value.Encode()
+			assert.Equal(t, shortenValue(t, "b"), value)
+			return nil
+		})
+
+		pc.AsyncClose()
+		assert.NoError(t, c.ConsumeClaim(session, claim))
+
+		repo.AssertExpectations(t)
+		_, committed := session.offsets()
+		assert.Equal(t, int64(2), committed)
+		assert.NoError(t, producer.Close())
+	})
+
+	t.Run("offset is not marked when the batch is neither stored nor dead-lettered", func(t *testing.T) {
+		repo := new(mocks.LinkRepo)
+		producer := saramamocks.NewSyncProducer(t, nil)
+		c := newTestConsumer(producer, repo)
+		session := newTestSession(context.Background())
+		claim, pc := newTestClaim(t, shortenValue(t, "a"), shortenValue(t, "b"))
+
+		repo.On("InsertBatch", mock.Anything, mock.Anything).Return(nil, &pgconn.PgError{Code: "23514"})
+		producer.ExpectSendMessageAndFail(fmt.Errorf("broker down"))
+
+		pc.AsyncClose()
+		assert.Error(t, c.ConsumeClaim(session, claim))
+
+		marked, committed := session.offsets()
+		assert.Equal(t, int64(-1), marked)
+		assert.Equal(t, int64(-1), committed)
+		assert.NoError(t, producer.Close())
+	})
+
+	t.Run("unavailable database leaves the batch for redelivery without DLQ", func(t *testing.T) {
+		tests := []struct {
+			name string
+			err  error
+		}{
+			{name: "connection error", err: &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}},
+			{name: "admin shutdown", err: &pgconn.PgError{Code: "57P01"}},
+			{name: "service unavailable", err: fmt.Errorf("%w: primary недоступен", service.ErrUnavailable)},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				repo := new(mocks.LinkRepo)
+				producer := saramamocks.NewSyncProducer(t, nil)
+				c := newTestConsumer(producer, repo)
+				session := newTestSession(context.Background())
+				claim, pc := newTestClaim(t, shortenValue(t, "a"), shortenValue(t, "b"))
+
+				repo.On("InsertBatch", mock.Anything, mock.Anything).Return(nil, tt.err)
+
+				pc.AsyncClose()
+				assert.ErrorIs(t, c.ConsumeClaim(session, claim), tt.err)
+
+				// Пачка целиком повторена по политике и не разбита на отдельные ссылки
+				repo.AssertNumberOfCalls(t, "InsertBatch", 2)
+				marked, committed := session.offsets()
+				assert.Equal(t, int64(-1), marked)
+				assert.Equal(t, int64(-1), committed)
+				assert.NoError(t, producer.Close())
+			})
+		}
+	})
+
+	t.Run("pending batch is flushed when the session ends", func(t *testing.T) {
+		repo := new(mocks.LinkRepo)
+		c := newTestConsumer(nil, repo)
+		c.batchSize = 10
+		ctx, cancel := context.WithCancel(context.Background())
+		session := newTestSession(ctx)
+		claim, _ := newTestClaim(t, shortenValue(t, "a"))
+
+		inserted := make(chan struct{})
+		repo.On("InsertBatch", mock.Anything, mock.Anything).Return(insertAll).Once().Run(func(mock.Arguments) { close(inserted) })
+
+		done := make(chan error)
+		go func() { done <- c.ConsumeClaim(session, claim) }()
+
+		// Дожидаемся, пока сообщение будет прочитано, и имитируем ребалансировку
+		assert.Eventually(t, func() bool { return len(claim.Messages()) == 0 }, time.Second, time.Millisecond)
+		cancel()
+
+		select {
+		case err := <-done:
+			assert.NoError(t, err)
+		case <-time.After(time.Second):
+			t.Fatal("ConsumeClaim не завершился после отмены сессии")
+		}
+		<-inserted
+		_, committed := session.offsets()
+		assert.Equal(t, int64(1), committed)
+	})
+}
+
+func TestConsumer_Cleanup(t *testing.T) {
+	c := newTestConsumer(nil, new(mocks.LinkRepo))
+	session := newTestSession(context.Background())
+	session.MarkOffset(message.ShortenURLsTopic, 0, 5, "")
+
+	assert.NoError(t, c.Setup(session))
+	assert.NoError(t, c.Cleanup(session))
+
+	_, committed := session.offsets()
+	assert.Equal(t, int64(5), committed)
+}
@@ -0,0 +1,226 @@
+package kafka
+
+import (
+	"context"
+	"github.com/IBM/sarama"
+	"github.com/sirupsen/logrus"
+	"linkreduction/internal/config"
+	"linkreduction/internal/const"
+	"linkreduction/internal/service"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Заголовки сообщения DLQ с причиной ошибки и исходным положением сообщения.
+const (
+	HeaderDLQError     = "dlq-error"
+	HeaderDLQTopic     = "dlq-original-topic"
+	HeaderDLQPartition = "dlq-original-partition"
+	HeaderDLQOffset    = "dlq-original-offset"
+	HeaderDLQAttempts  = "dlq-attempts"
+	HeaderDLQFailedAt  = "dlq-failed-at"
+
+	dlqHeaderPrefix = "dlq-"
+)
+
+const (
+	defaultRetryAttempts       = 5
+	defaultRetryInitialBackoff = 500 * time.Millisecond
+	defaultRetryMaxBackoff     = 30 * time.Second
+)
+
+// retryPolicy задаёт повторы записи пачки с экспоненциальной задержкой.
+type retryPolicy struct {
+	attempts       int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+}
+
+func newRetryPolicy(cfg config.KafkaRetry) retryPolicy {
+	p := retryPolicy{attempts: defaultRetryAttempts, initialBackoff: defaultRetryInitialBackoff, maxBackoff: defaultRetryMaxBackoff}
+	if cfg.MaxAttempts > 0 {
+		p.attempts = cfg.MaxAttempts
+	}
+	if cfg.InitialBackoff > 0 {
+		p.initialBackoff = cfg.InitialBackoff
+	}
+	if cfg.MaxBackoff > 0 {
+		p.maxBackoff = cfg.MaxBackoff
+	}
+	return p
+}
+
+// do выполняет fn до успеха или исчерпания попыток и возвращает число сделанных попыток.
+// Ошибку, которую повтор не исправит (см. service.Retryable), fn не повторяет. Отмена
+// контекста прерывает ожидание между попытками.
+func (p retryPolicy) do(ctx context.Context, fn func() error) (int, error) {
+	backoff := p.initialBackoff
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= p.attempts || !service.Retryable(err) {
+			return attempt, err
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return attempt, err
+		}
+		backoff = min(backoff*2, p.maxBackoff)
+	}
+}
+
+// deadLetter копирует сообщение в DLQ с исходными ключом и заголовками и добавляет заголовки
+// с причиной ошибки.
+func deadLetter(msg *sarama.ConsumerMessage, topic string, reason error, attempts int) *sarama.ProducerMessage {
+	pm := forward(msg, topic)
+	pm.Headers = append(pm.Headers,
+		sarama.RecordHeader{Key: []byte(HeaderDLQError), Value: []byte(reason.Error())},
+		sarama.RecordHeader{Key: []byte(HeaderDLQTopic), Value: []byte(msg.Topic)},
+		sarama.RecordHeader{Key: []byte(HeaderDLQPartition), Value: []byte(strconv.Itoa(int(msg.Partition)))},
+		sarama.RecordHeader{Key: []byte(HeaderDLQOffset), Value: []byte(strconv.FormatInt(msg.Offset, 10))},
+		sarama.RecordHeader{Key: []byte(HeaderDLQAttempts), Value: []byte(strconv.Itoa(attempts))},
+		sarama.RecordHeader{Key: []byte(HeaderDLQFailedAt), Value: []byte(time.Now().UTC().Format(time.RFC3339))},
+	)
+	return pm
+}
+
+// forward копирует сообщение в топик topic без заголовков DLQ.
+func forward(msg *sarama.ConsumerMessage, topic string) *sarama.ProducerMessage {
+	headers := make([]sarama.RecordHeader, 0, len(msg.Headers)+6)
+	for _, h := range msg.Headers {
+		if h != nil && !strings.HasPrefix(string(h.Key), dlqHeaderPrefix) {
+			headers = append(headers, *h)
+		}
+	}
+
+	pm := &sarama.ProducerMessage{Topic: topic, Value: sarama.ByteEncoder(msg.Value), Headers: headers}
+	if msg.Key != nil {
+		pm.Key = sarama.ByteEncoder(msg.Key)
+	}
+	return pm
+}
+
+// DLQReplayer переносит сообщения из shorten-urls.dlq обратно в shorten-urls. Прочитанные
+// сообщения фиксируются в отдельной группе, поэтому повторный запуск не дублирует их.
+type DLQReplayer struct {
+	producer sarama.SyncProducer
+	logger   *logrus.Logger
+	limit    int
+	idle     time.Duration
+
+	// mu упорядочивает перенос из разных партиций, чтобы limit соблюдался точно
+	mu    sync.Mutex
+	count int
+}
+
+// NewDLQReplayer создаёт перенос не более limit сообщений (0 — без ограничения). Партиция
+// считается разобранной, если за idle из неё не пришло ни одного сообщения.
+func NewDLQReplayer(producer sarama.SyncProducer, logger *logrus.Logger, limit int, idle time.Duration) *DLQReplayer {
+	return &DLQReplayer{producer: producer, logger: logger, limit: limit, idle: idle}
+}
+
+// Replay переносит сообщения, пока в DLQ остаются новые, и возвращает их число.
+func (r *DLQReplayer) Replay(ctx context.Context, cfg *config.Config) (int, error) {
+	brokers, err := kafkaBrokers(cfg)
+	if err != nil {
+		return 0, err
+	}
+
+	consumerGroup, err := connectConsumerGroup(brokers, message.ShortenURLsDLQReplayGroup, true)
+	if err != nil {
+		return 0, err
+	}
+	defer consumerGroup.Close()
+
+	return r.replayFrom(ctx, consumerGroup)
+}
+
+// replayFrom переносит сообщения DLQ, читая их через consumerGroup.
+func (r *DLQReplayer) replayFrom(ctx context.Context, consumerGroup sarama.ConsumerGroup) (int, error) {
+	// Сессия группы завершается, как только разобрана любая из партиций, поэтому
+	// сессии повторяются, пока переносятся новые сообщения
+	for !r.done() {
+		before := r.Count()
+		if err := consumerGroup.Consume(ctx, []string{message.ShortenURLsDLQTopic}, r); err != nil {
+			return r.Count(), err
+		}
+		if ctx.Err() != nil {
+			return r.Count(), ctx.Err()
+		}
+		if r.Count() == before {
+			break
+		}
+	}
+	return r.Count(), nil
+}
+
+func (r *DLQReplayer) done() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.limit > 0 && r.count >= r.limit
+}
+
+// Count возвращает число перенесённых сообщений.
+func (r *DLQReplayer) Count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.count
+}
+
+// replay переносит одно сообщение; false означает, что лимит уже исчерпан.
+func (r *DLQReplayer) replay(session sarama.ConsumerGroupSession, msg *sarama.ConsumerMessage) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.limit > 0 && r.count >= r.limit {
+		return false, nil
+	}
+
+	if _, _, err := r.producer.SendMessage(forward(msg, message.ShortenURLsTopic)); err != nil {
+		return false, err
+	}
+	session.MarkMessage(msg, "")
+	r.count++
+	return true, nil
+}
+
+func (r *DLQReplayer) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	for {
+		select {
+		case msg, ok := <-claim.Messages():
+			if !ok {
+				return nil
+			}
+			ok, err := r.replay(session, msg)
+			if err != nil || !ok {
+				return err
+			}
+			r.logger.WithFields(logrus.Fields{
+				"partition": msg.Partition,
+				"offset":    msg.Offset,
+			}).Info("Сообщение возвращено из DLQ")
+
+			if msg.Offset+1 >= claim.HighWaterMarkOffset() {
+				return nil
+			}
+		case <-time.After(r.idle):
+			return nil
+		case <-session.Context().Done():
+			return nil
+		}
+	}
+}
+
+func (r *DLQReplayer) Setup(_ sarama.ConsumerGroupSession) error {
+	return nil
+}
+
+// Cleanup фиксирует смещения перенесённых сообщений в конце каждой сессии.
+func (r *DLQReplayer) Cleanup(session sarama.ConsumerGroupSession) error {
+	session.Commit()
+	return nil
+}
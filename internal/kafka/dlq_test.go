@@ -0,0 +1,343 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"linkreduction/internal/config"
+	"linkreduction/internal/const"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	saramamocks "github.com/IBM/sarama/mocks"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewRetryPolicy(t *testing.T) {
+	p := newRetryPolicy(config.KafkaRetry{})
+	assert.Equal(t, retryPolicy{attempts: defaultRetryAttempts, initialBackoff: defaultRetryInitialBackoff, maxBackoff: defaultRetryMaxBackoff}, p)
+
+	p = newRetryPolicy(config.KafkaRetry{MaxAttempts: 3, InitialBackoff: time.Second, MaxBackoff: time.Minute})
+	assert.Equal(t, retryPolicy{attempts: 3, initialBackoff: time.Second, maxBackoff: time.Minute}, p)
+}
+
+func TestRetryPolicy_Do(t *testing.T) {
+	p := retryPolicy{attempts: 3, initialBackoff: time.Millisecond, maxBackoff: 2 * time.Millisecond}
+
+	t.Run("success on first attempt", func(t *testing.T) {
+		attempts, err := p.do(context.Background(), func() error { return nil })
+		assert.NoError(t, err)
+		assert.Equal(t, 1, attempts)
+	})
+
+	t.Run("success after failures", func(t *testing.T) {
+		calls := 0
+		attempts, err := p.do(context.Background(), func() error {
+			calls++
+			if calls < 3 {
+				return fmt.Errorf("db down")
+			}
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 3, attempts)
+	})
+
+	t.Run("last error after all attempts", func(t *testing.T) {
+		calls := 0
+		attempts, err := p.do(context.Background(), func() error {
+			calls++
+			return fmt.Errorf("attempt %d", calls)
+		})
+		assert.EqualError(t, err, "attempt 3")
+		assert.Equal(t, 3, attempts)
+		assert.Equal(t, 3, calls)
+	})
+
+	t.Run("data error is not retried", func(t *testing.T) {
+		calls := 0
+		attempts, err := p.do(context.Background(), func() error {
+			calls++
+			return &pgconn.PgError{Code: "23514"}
+		})
+		assert.Error(t, err)
+		assert.Equal(t, 1, attempts)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("canceled context stops waiting", func(t *testing.T) {
+		slow := retryPolicy{attempts: 5, initialBackoff: time.Hour, maxBackoff: time.Hour}
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		attempts, err := slow.do(ctx, func() error { return fmt.Errorf("db down") })
+		assert.Error(t, err)
+		assert.Equal(t, 1, attempts)
+	})
+}
+
+func headerMap(headers []sarama.RecordHeader) map[string]string {
+	m := make(map[string]string, len(headers))
+	for _, h := range headers {
+		m[string(h.Key)] = string(h.Value)
+	}
+	return m
+}
+
+func TestDeadLetter(t *testing.T) {
+	msg := &sarama.ConsumerMessage{
+		Topic:     message.ShortenURLsTopic,
+		Partition: 3,
+		Offset:    42,
+		Key:       []byte("abc"),
+		Value:     []byte(`{"short_link":"abc"}`),
+		Headers: []*sarama.RecordHeader{
+			{Key: []byte("trace-id"), Value: []byte("t1")},
+			{Key: []byte(HeaderDLQError), Value: []byte("старая ошибка")},
+			nil,
+		},
+	}
+
+	before := time.Now().UTC().Truncate(time.Second)
+	pm := deadLetter(msg, message.ShortenURLsDLQTopic, fmt.Errorf("bad row"), 5)
+
+	assert.Equal(t, message.ShortenURLsDLQTopic, pm.Topic)
+	key, _ := pm.Key.Encode()
+	assert.Equal(t, []byte("abc"), key)
+	value, _ := pm.Value.Encode()
+	assert.Equal(t, msg.Value, value)
+
+	headers := headerMap(pm.Headers)
+	assert.Len(t, pm.Headers, 7, "заголовок DLQ из исходного сообщения не должен дублироваться")
+	assert.Equal(t, "t1", headers["trace-id"])
+	assert.Equal(t, "bad row", headers[HeaderDLQError])
+	assert.Equal(t, message.ShortenURLsTopic, headers[HeaderDLQTopic])
+	assert.Equal(t, "3", headers[HeaderDLQPartition])
+	assert.Equal(t, "42", headers[HeaderDLQOffset])
+	assert.Equal(t, "5", headers[HeaderDLQAttempts])
+	failedAt, err := time.Parse(time.RFC3339, headers[HeaderDLQFailedAt])
+	if assert.NoError(t, err) {
+		assert.False(t, failedAt.Before(before))
+	}
+}
+
+func TestForward(t *testing.T) {
+	msg := &sarama.ConsumerMessage{
+		Value: []byte("value"),
+		Headers: []*sarama.RecordHeader{
+			{Key: []byte("trace-id"), Value: []byte("t1")},
+			{Key: []byte(HeaderDLQError), Value: []byte("bad row")},
+			{Key: []byte(HeaderDLQAttempts), Value: []byte("5")},
+		},
+	}
+
+	pm := forward(msg, message.ShortenURLsTopic)
+
+	assert.Equal(t, message.ShortenURLsTopic, pm.Topic)
+	assert.Nil(t, pm.Key, "сообщение без ключа должно остаться без ключа")
+	assert.Equal(t, map[string]string{"trace-id": "t1"}, headerMap(pm.Headers))
+}
+
+// dlqMessage — сообщение DLQ с заголовками причины ошибки.
+func dlqMessage(value string) *sarama.ConsumerMessage {
+	return &sarama.ConsumerMessage{
+		Key:   []byte(value),
+		Value: []byte(value),
+		Headers: []*sarama.RecordHeader{
+			{Key: []byte(HeaderDLQError), Value: []byte("bad row")},
+			{Key: []byte("trace-id"), Value: []byte(value)},
+		},
+	}
+}
+
+// newDLQClaim возвращает партицию с сообщениями msgs.
+func newDLQClaim(t *testing.T, msgs ...*sarama.ConsumerMessage) (testClaim, *saramamocks.PartitionConsumer) {
+	consumer := saramamocks.NewConsumer(t, nil)
+	expected := consumer.ExpectConsumePartition(message.ShortenURLsDLQTopic, 0, sarama.OffsetOldest)
+	for _, msg := range msgs {
+		expected.YieldMessage(msg)
+	}
+
+	pc, err := consumer.ConsumePartition(message.ShortenURLsDLQTopic, 0, sarama.OffsetOldest)
+	assert.NoError(t, err)
+	return testClaim{pc}, expected
+}
+
+func newTestReplayer(producer sarama.SyncProducer, limit int) *DLQReplayer {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return NewDLQReplayer(producer, logger, limit, 10*time.Millisecond)
+}
+
+// expectReplayed ожидает отправку сообщения value в shorten-urls без заголовков DLQ.
+func expectReplayed(t *testing.T, producer *saramamocks.SyncProducer, value string) {
+	producer.ExpectSendMessageWithMessageCheckerFunctionAndSucceed(func(msg *sarama.ProducerMessage) error {
+		assert.Equal(t, message.ShortenURLsTopic, msg.Topic)
+		encoded, _ := msg.Value.Encode()
+		assert.Equal(t, value, string(encoded))
+		assert.Equal(t, map[string]string{"trace-id": value}, headerMap(msg.Headers))
+		return nil
+	})
+}
+
+func TestDLQReplayer_ConsumeClaim(t *testing.T) {
+	t.Run("moves messages up to the high water mark", func(t *testing.T) {
+		producer := saramamocks.NewSyncProducer(t, nil)
+		expectReplayed(t, producer, "a")
+		expectReplayed(t, producer, "b")
+		r := newTestReplayer(producer, 0)
+		session := newTestSession(context.Background())
+		claim, _ := newDLQClaim(t, dlqMessage("a"), dlqMessage("b"))
+
+		assert.NoError(t, r.ConsumeClaim(session, claim))
+
+		assert.Equal(t, 2, r.Count())
+		marked, _ := session.offsets()
+		assert.Equal(t, int64(2), marked)
+		assert.NoError(t, r.Cleanup(session))
+		_, committed := session.offsets()
+		assert.Equal(t, int64(2), committed)
+		assert.NoError(t, producer.Close())
+	})
+
+	t.Run("stops at the limit", func(t *testing.T) {
+		producer := saramamocks.NewSyncProducer(t, nil)
+		expectReplayed(t, producer, "a")
+		r := newTestReplayer(producer, 1)
+		session := newTestSession(context.Background())
+		claim, _ := newDLQClaim(t, dlqMessage("a"), dlqMessage("b"))
+
+		assert.NoError(t, r.ConsumeClaim(session, claim))
+
+		assert.Equal(t, 1, r.Count())
+		marked, _ := session.offsets()
+		assert.Equal(t, int64(1), marked, "сообщение сверх лимита не должно отмечаться")
+		assert.NoError(t, producer.Close())
+	})
+
+	t.Run("send failure leaves the message in DLQ", func(t *testing.T) {
+		producer := saramamocks.NewSyncProducer(t, nil)
+		producer.ExpectSendMessageAndFail(fmt.Errorf("broker down"))
+		r := newTestReplayer(producer, 0)
+		session := newTestSession(context.Background())
+		claim, _ := newDLQClaim(t, dlqMessage("a"))
+
+		assert.Error(t, r.ConsumeClaim(session, claim))
+
+		assert.Equal(t, 0, r.Count())
+		marked, _ := session.offsets()
+		assert.Equal(t, int64(-1), marked)
+		assert.NoError(t, producer.Close())
+	})
+
+	t.Run("idle partition ends the claim", func(t *testing.T) {
+		r := newTestReplayer(nil, 0)
+		session := newTestSession(context.Background())
+		claim, _ := newDLQClaim(t)
+
+		assert.NoError(t, r.ConsumeClaim(session, claim))
+		assert.Equal(t, 0, r.Count())
+	})
+}
+
+// testGroup — группа потребителей, которая в каждой сессии отдаёт обработчику очередную
+// порцию сообщений одной партиции.
+type testGroup struct {
+	t        *testing.T
+	sessions [][]*sarama.ConsumerMessage
+	consumed int
+}
+
+func (g *testGroup) Consume(ctx context.Context, _ []string, handler sarama.ConsumerGroupHandler) error {
+	var msgs []*sarama.ConsumerMessage
+	if g.consumed < len(g.sessions) {
+		msgs = g.sessions[g.consumed]
+	}
+	g.consumed++
+
+	session := newTestSession(ctx)
+	claim, _ := newDLQClaim(g.t, msgs...)
+	if err := handler.Setup(session); err != nil {
+		return err
+	}
+	if err := handler.ConsumeClaim(session, claim); err != nil {
+		return err
+	}
+	return handler.Cleanup(session)
+}
+
+func (g *testGroup) Errors() <-chan error      { return nil }
+func (g *testGroup) Close() error              { return nil }
+func (g *testGroup) Pause(map[string][]int32)  {}
+func (g *testGroup) Resume(map[string][]int32) {}
+func (g *testGroup) PauseAll()                 {}
+func (g *testGroup) ResumeAll()                {}
+
+func TestDLQReplayer_Replay(t *testing.T) {
+	t.Run("repeats sessions until no new messages", func(t *testing.T) {
+		producer := saramamocks.NewSyncProducer(t, nil)
+		expectReplayed(t, producer, "a")
+		expectReplayed(t, producer, "b")
+		expectReplayed(t, producer, "c")
+		r := newTestReplayer(producer, 0)
+		group := &testGroup{t: t, sessions: [][]*sarama.ConsumerMessage{
+			{dlqMessage("a"), dlqMessage("b")},
+			{dlqMessage("c")},
+		}}
+
+		n, err := r.replayFrom(context.Background(), group)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 3, n)
+		assert.Equal(t, 3, group.consumed, "последняя сессия без новых сообщений завершает перенос")
+		assert.NoError(t, producer.Close())
+	})
+
+	t.Run("stops once the limit is reached", func(t *testing.T) {
+		producer := saramamocks.NewSyncProducer(t, nil)
+		expectReplayed(t, producer, "a")
+		expectReplayed(t, producer, "b")
+		r := newTestReplayer(producer, 2)
+		group := &testGroup{t: t, sessions: [][]*sarama.ConsumerMessage{
+			{dlqMessage("a")},
+			{dlqMessage("b"), dlqMessage("c")},
+		}}
+
+		n, err := r.replayFrom(context.Background(), group)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, n)
+		assert.Equal(t, 2, group.consumed)
+		assert.NoError(t, producer.Close())
+	})
+
+	t.Run("send failure is returned with the count so far", func(t *testing.T) {
+		producer := saramamocks.NewSyncProducer(t, nil)
+		expectReplayed(t, producer, "a")
+		producer.ExpectSendMessageAndFail(fmt.Errorf("broker down"))
+		r := newTestReplayer(producer, 0)
+		group := &testGroup{t: t, sessions: [][]*sarama.ConsumerMessage{
+			{dlqMessage("a"), dlqMessage("b")},
+		}}
+
+		n, err := r.replayFrom(context.Background(), group)
+
+		assert.Error(t, err)
+		assert.Equal(t, 1, n)
+		assert.NoError(t, producer.Close())
+	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		r := newTestReplayer(nil, 0)
+
+		n, err := r.replayFrom(ctx, &testGroup{t: t})
+
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, 0, n)
+	})
+}
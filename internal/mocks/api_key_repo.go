@@ -0,0 +1,210 @@
+// Code generated by mockery v2.53.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	models "linkreduction/internal/models"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// APIKeyRepo is an autogenerated mock type for the APIKeyRepo type
+type APIKeyRepo struct {
+	mock.Mock
+}
+
+type APIKeyRepo_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *APIKeyRepo) EXPECT() *APIKeyRepo_Expecter {
+	return &APIKeyRepo_Expecter{mock: &_m.Mock}
+}
+
+// CreateAPIKey provides a mock function with given fields: ctx, key
+func (_m *APIKeyRepo) CreateAPIKey(ctx context.Context, key models.APIKey) (int64, error) {
+	ret := _m.Called(ctx, key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateAPIKey")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.APIKey) (int64, error)); ok {
+		return rf(ctx, key)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, models.APIKey) int64); ok {
+		r0 = rf(ctx, key)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, models.APIKey) error); ok {
+		r1 = rf(ctx, key)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// APIKeyRepo_CreateAPIKey_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateAPIKey'
+type APIKeyRepo_CreateAPIKey_Call struct {
+	*mock.Call
+}
+
+// CreateAPIKey is a helper method to define mock.On call
+//   - ctx context.Context
+//   - key models.APIKey
+func (_e *APIKeyRepo_Expecter) CreateAPIKey(ctx interface{}, key interface{}) *APIKeyRepo_CreateAPIKey_Call {
+	return &APIKeyRepo_CreateAPIKey_Call{Call: _e.mock.On("CreateAPIKey", ctx, key)}
+}
+
+func (_c *APIKeyRepo_CreateAPIKey_Call) Run(run func(ctx context.Context, key models.APIKey)) *APIKeyRepo_CreateAPIKey_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(models.APIKey))
+	})
+	return _c
+}
+
+func (_c *APIKeyRepo_CreateAPIKey_Call) Return(_a0 int64, _a1 error) *APIKeyRepo_CreateAPIKey_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *APIKeyRepo_CreateAPIKey_Call) RunAndReturn(run func(context.Context, models.APIKey) (int64, error)) *APIKeyRepo_CreateAPIKey_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// FindAPIKeyByHash provides a mock function with given fields: ctx, keyHash
+func (_m *APIKeyRepo) FindAPIKeyByHash(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	ret := _m.Called(ctx, keyHash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindAPIKeyByHash")
+	}
+
+	var r0 *models.APIKey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*models.APIKey, error)); ok {
+		return rf(ctx, keyHash)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *models.APIKey); ok {
+		r0 = rf(ctx, keyHash)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.APIKey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, keyHash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// APIKeyRepo_FindAPIKeyByHash_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'FindAPIKeyByHash'
+type APIKeyRepo_FindAPIKeyByHash_Call struct {
+	*mock.Call
+}
+
+// FindAPIKeyByHash is a helper method to define mock.On call
+//   - ctx context.Context
+//   - keyHash string
+func (_e *APIKeyRepo_Expecter) FindAPIKeyByHash(ctx interface{}, keyHash interface{}) *APIKeyRepo_FindAPIKeyByHash_Call {
+	return &APIKeyRepo_FindAPIKeyByHash_Call{Call: _e.mock.On("FindAPIKeyByHash", ctx, keyHash)}
+}
+
+func (_c *APIKeyRepo_FindAPIKeyByHash_Call) Run(run func(ctx context.Context, keyHash string)) *APIKeyRepo_FindAPIKeyByHash_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *APIKeyRepo_FindAPIKeyByHash_Call) Return(_a0 *models.APIKey, _a1 error) *APIKeyRepo_FindAPIKeyByHash_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *APIKeyRepo_FindAPIKeyByHash_Call) RunAndReturn(run func(context.Context, string) (*models.APIKey, error)) *APIKeyRepo_FindAPIKeyByHash_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RevokeAPIKey provides a mock function with given fields: ctx, id
+func (_m *APIKeyRepo) RevokeAPIKey(ctx context.Context, id int64) (bool, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeAPIKey")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) (bool, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) bool); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// APIKeyRepo_RevokeAPIKey_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RevokeAPIKey'
+type APIKeyRepo_RevokeAPIKey_Call struct {
+	*mock.Call
+}
+
+// RevokeAPIKey is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id int64
+func (_e *APIKeyRepo_Expecter) RevokeAPIKey(ctx interface{}, id interface{}) *APIKeyRepo_RevokeAPIKey_Call {
+	return &APIKeyRepo_RevokeAPIKey_Call{Call: _e.mock.On("RevokeAPIKey", ctx, id)}
+}
+
+func (_c *APIKeyRepo_RevokeAPIKey_Call) Run(run func(ctx context.Context, id int64)) *APIKeyRepo_RevokeAPIKey_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64))
+	})
+	return _c
+}
+
+func (_c *APIKeyRepo_RevokeAPIKey_Call) Return(_a0 bool, _a1 error) *APIKeyRepo_RevokeAPIKey_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *APIKeyRepo_RevokeAPIKey_Call) RunAndReturn(run func(context.Context, int64) (bool, error)) *APIKeyRepo_RevokeAPIKey_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewAPIKeyRepo creates a new instance of APIKeyRepo. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewAPIKeyRepo(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *APIKeyRepo {
+	mock := &APIKeyRepo{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,148 @@
+// Code generated by mockery v2.53.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	models "linkreduction/internal/models"
+
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// ClickRepo is an autogenerated mock type for the ClickRepo type
+type ClickRepo struct {
+	mock.Mock
+}
+
+type ClickRepo_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *ClickRepo) EXPECT() *ClickRepo_Expecter {
+	return &ClickRepo_Expecter{mock: &_m.Mock}
+}
+
+// GetStats provides a mock function with given fields: ctx, shortLink, createdAt, since, topReferrers
+func (_m *ClickRepo) GetStats(ctx context.Context, shortLink string, createdAt time.Time, since time.Time, topReferrers int) (*models.LinkStats, error) {
+	ret := _m.Called(ctx, shortLink, createdAt, since, topReferrers)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetStats")
+	}
+
+	var r0 *models.LinkStats
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time, time.Time, int) (*models.LinkStats, error)); ok {
+		return rf(ctx, shortLink, createdAt, since, topReferrers)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time, time.Time, int) *models.LinkStats); ok {
+		r0 = rf(ctx, shortLink, createdAt, since, topReferrers)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.LinkStats)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, time.Time, time.Time, int) error); ok {
+		r1 = rf(ctx, shortLink, createdAt, since, topReferrers)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ClickRepo_GetStats_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetStats'
+type ClickRepo_GetStats_Call struct {
+	*mock.Call
+}
+
+// GetStats is a helper method to define mock.On call
+//   - ctx context.Context
+//   - shortLink string
+//   - createdAt time.Time
+//   - since time.Time
+//   - topReferrers int
+func (_e *ClickRepo_Expecter) GetStats(ctx interface{}, shortLink interface{}, createdAt interface{}, since interface{}, topReferrers interface{}) *ClickRepo_GetStats_Call {
+	return &ClickRepo_GetStats_Call{Call: _e.mock.On("GetStats", ctx, shortLink, createdAt, since, topReferrers)}
+}
+
+func (_c *ClickRepo_GetStats_Call) Run(run func(ctx context.Context, shortLink string, createdAt time.Time, since time.Time, topReferrers int)) *ClickRepo_GetStats_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(time.Time), args[3].(time.Time), args[4].(int))
+	})
+	return _c
+}
+
+func (_c *ClickRepo_GetStats_Call) Return(_a0 *models.LinkStats, _a1 error) *ClickRepo_GetStats_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *ClickRepo_GetStats_Call) RunAndReturn(run func(context.Context, string, time.Time, time.Time, int) (*models.LinkStats, error)) *ClickRepo_GetStats_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// InsertClicks provides a mock function with given fields: ctx, clicks
+func (_m *ClickRepo) InsertClicks(ctx context.Context, clicks []models.Click) error {
+	ret := _m.Called(ctx, clicks)
+
+	if len(ret) == 0 {
+		panic("no return value specified for InsertClicks")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []models.Click) error); ok {
+		r0 = rf(ctx, clicks)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ClickRepo_InsertClicks_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'InsertClicks'
+type ClickRepo_InsertClicks_Call struct {
+	*mock.Call
+}
+
+// InsertClicks is a helper method to define mock.On call
+//   - ctx context.Context
+//   - clicks []models.Click
+func (_e *ClickRepo_Expecter) InsertClicks(ctx interface{}, clicks interface{}) *ClickRepo_InsertClicks_Call {
+	return &ClickRepo_InsertClicks_Call{Call: _e.mock.On("InsertClicks", ctx, clicks)}
+}
+
+func (_c *ClickRepo_InsertClicks_Call) Run(run func(ctx context.Context, clicks []models.Click)) *ClickRepo_InsertClicks_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].([]models.Click))
+	})
+	return _c
+}
+
+func (_c *ClickRepo_InsertClicks_Call) Return(_a0 error) *ClickRepo_InsertClicks_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *ClickRepo_InsertClicks_Call) RunAndReturn(run func(context.Context, []models.Click) error) *ClickRepo_InsertClicks_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewClickRepo creates a new instance of ClickRepo. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewClickRepo(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *ClickRepo {
+	mock := &ClickRepo{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
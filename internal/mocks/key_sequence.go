@@ -0,0 +1,92 @@
+// Code generated by mockery v2.53.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// KeySequence is an autogenerated mock type for the KeySequence type
+type KeySequence struct {
+	mock.Mock
+}
+
+type KeySequence_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *KeySequence) EXPECT() *KeySequence_Expecter {
+	return &KeySequence_Expecter{mock: &_m.Mock}
+}
+
+// NextKeyID provides a mock function with given fields: ctx
+func (_m *KeySequence) NextKeyID(ctx context.Context) (int64, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for NextKeyID")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (int64, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) int64); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// KeySequence_NextKeyID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'NextKeyID'
+type KeySequence_NextKeyID_Call struct {
+	*mock.Call
+}
+
+// NextKeyID is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *KeySequence_Expecter) NextKeyID(ctx interface{}) *KeySequence_NextKeyID_Call {
+	return &KeySequence_NextKeyID_Call{Call: _e.mock.On("NextKeyID", ctx)}
+}
+
+func (_c *KeySequence_NextKeyID_Call) Run(run func(ctx context.Context)) *KeySequence_NextKeyID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *KeySequence_NextKeyID_Call) Return(_a0 int64, _a1 error) *KeySequence_NextKeyID_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *KeySequence_NextKeyID_Call) RunAndReturn(run func(context.Context) (int64, error)) *KeySequence_NextKeyID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewKeySequence creates a new instance of KeySequence. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewKeySequence(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *KeySequence {
+	mock := &KeySequence{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
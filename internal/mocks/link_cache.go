@@ -4,6 +4,7 @@ package mocks
 
 import (
 	context "context"
+	models "linkreduction/internal/models"
 
 	mock "github.com/stretchr/testify/mock"
 
@@ -23,23 +24,72 @@ func (_m *LinkCache) EXPECT() *LinkCache_Expecter {
 	return &LinkCache_Expecter{mock: &_m.Mock}
 }
 
-// GetOriginalURL provides a mock function with given fields: ctx, shortLink
-func (_m *LinkCache) GetOriginalURL(ctx context.Context, shortLink string) (string, error) {
+// DeleteRedirects provides a mock function with given fields: ctx, shortLinks
+func (_m *LinkCache) DeleteRedirects(ctx context.Context, shortLinks []string) error {
+	ret := _m.Called(ctx, shortLinks)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteRedirects")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []string) error); ok {
+		r0 = rf(ctx, shortLinks)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// LinkCache_DeleteRedirects_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteRedirects'
+type LinkCache_DeleteRedirects_Call struct {
+	*mock.Call
+}
+
+// DeleteRedirects is a helper method to define mock.On call
+//   - ctx context.Context
+//   - shortLinks []string
+func (_e *LinkCache_Expecter) DeleteRedirects(ctx interface{}, shortLinks interface{}) *LinkCache_DeleteRedirects_Call {
+	return &LinkCache_DeleteRedirects_Call{Call: _e.mock.On("DeleteRedirects", ctx, shortLinks)}
+}
+
+func (_c *LinkCache_DeleteRedirects_Call) Run(run func(ctx context.Context, shortLinks []string)) *LinkCache_DeleteRedirects_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].([]string))
+	})
+	return _c
+}
+
+func (_c *LinkCache_DeleteRedirects_Call) Return(_a0 error) *LinkCache_DeleteRedirects_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *LinkCache_DeleteRedirects_Call) RunAndReturn(run func(context.Context, []string) error) *LinkCache_DeleteRedirects_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetRedirect provides a mock function with given fields: ctx, shortLink
+func (_m *LinkCache) GetRedirect(ctx context.Context, shortLink string) (*models.Redirect, error) {
 	ret := _m.Called(ctx, shortLink)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetOriginalURL")
+		panic("no return value specified for GetRedirect")
 	}
 
-	var r0 string
+	var r0 *models.Redirect
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string) (string, error)); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*models.Redirect, error)); ok {
 		return rf(ctx, shortLink)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string) string); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, string) *models.Redirect); ok {
 		r0 = rf(ctx, shortLink)
 	} else {
-		r0 = ret.Get(0).(string)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Redirect)
+		}
 	}
 
 	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
@@ -51,31 +101,31 @@ func (_m *LinkCache) GetOriginalURL(ctx context.Context, shortLink string) (stri
 	return r0, r1
 }
 
-// LinkCache_GetOriginalURL_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetOriginalURL'
-type LinkCache_GetOriginalURL_Call struct {
+// LinkCache_GetRedirect_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetRedirect'
+type LinkCache_GetRedirect_Call struct {
 	*mock.Call
 }
 
-// GetOriginalURL is a helper method to define mock.On call
+// GetRedirect is a helper method to define mock.On call
 //   - ctx context.Context
 //   - shortLink string
-func (_e *LinkCache_Expecter) GetOriginalURL(ctx interface{}, shortLink interface{}) *LinkCache_GetOriginalURL_Call {
-	return &LinkCache_GetOriginalURL_Call{Call: _e.mock.On("GetOriginalURL", ctx, shortLink)}
+func (_e *LinkCache_Expecter) GetRedirect(ctx interface{}, shortLink interface{}) *LinkCache_GetRedirect_Call {
+	return &LinkCache_GetRedirect_Call{Call: _e.mock.On("GetRedirect", ctx, shortLink)}
 }
 
-func (_c *LinkCache_GetOriginalURL_Call) Run(run func(ctx context.Context, shortLink string)) *LinkCache_GetOriginalURL_Call {
+func (_c *LinkCache_GetRedirect_Call) Run(run func(ctx context.Context, shortLink string)) *LinkCache_GetRedirect_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		run(args[0].(context.Context), args[1].(string))
 	})
 	return _c
 }
 
-func (_c *LinkCache_GetOriginalURL_Call) Return(_a0 string, _a1 error) *LinkCache_GetOriginalURL_Call {
+func (_c *LinkCache_GetRedirect_Call) Return(_a0 *models.Redirect, _a1 error) *LinkCache_GetRedirect_Call {
 	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *LinkCache_GetOriginalURL_Call) RunAndReturn(run func(context.Context, string) (string, error)) *LinkCache_GetOriginalURL_Call {
+func (_c *LinkCache_GetRedirect_Call) RunAndReturn(run func(context.Context, string) (*models.Redirect, error)) *LinkCache_GetRedirect_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -137,17 +187,17 @@ func (_c *LinkCache_GetShortLink_Call) RunAndReturn(run func(context.Context, st
 	return _c
 }
 
-// SetOriginalURL provides a mock function with given fields: ctx, shortLink, originalURL, ttl
-func (_m *LinkCache) SetOriginalURL(ctx context.Context, shortLink string, originalURL string, ttl time.Duration) error {
-	ret := _m.Called(ctx, shortLink, originalURL, ttl)
+// Invalidate provides a mock function with given fields: ctx, shortLink, originalURL
+func (_m *LinkCache) Invalidate(ctx context.Context, shortLink string, originalURL string) error {
+	ret := _m.Called(ctx, shortLink, originalURL)
 
 	if len(ret) == 0 {
-		panic("no return value specified for SetOriginalURL")
+		panic("no return value specified for Invalidate")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, string, string, time.Duration) error); ok {
-		r0 = rf(ctx, shortLink, originalURL, ttl)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, shortLink, originalURL)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -155,33 +205,81 @@ func (_m *LinkCache) SetOriginalURL(ctx context.Context, shortLink string, origi
 	return r0
 }
 
-// LinkCache_SetOriginalURL_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetOriginalURL'
-type LinkCache_SetOriginalURL_Call struct {
+// LinkCache_Invalidate_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Invalidate'
+type LinkCache_Invalidate_Call struct {
 	*mock.Call
 }
 
-// SetOriginalURL is a helper method to define mock.On call
+// Invalidate is a helper method to define mock.On call
 //   - ctx context.Context
 //   - shortLink string
 //   - originalURL string
+func (_e *LinkCache_Expecter) Invalidate(ctx interface{}, shortLink interface{}, originalURL interface{}) *LinkCache_Invalidate_Call {
+	return &LinkCache_Invalidate_Call{Call: _e.mock.On("Invalidate", ctx, shortLink, originalURL)}
+}
+
+func (_c *LinkCache_Invalidate_Call) Run(run func(ctx context.Context, shortLink string, originalURL string)) *LinkCache_Invalidate_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *LinkCache_Invalidate_Call) Return(_a0 error) *LinkCache_Invalidate_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *LinkCache_Invalidate_Call) RunAndReturn(run func(context.Context, string, string) error) *LinkCache_Invalidate_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetRedirect provides a mock function with given fields: ctx, shortLink, redirect, ttl
+func (_m *LinkCache) SetRedirect(ctx context.Context, shortLink string, redirect models.Redirect, ttl time.Duration) error {
+	ret := _m.Called(ctx, shortLink, redirect, ttl)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetRedirect")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, models.Redirect, time.Duration) error); ok {
+		r0 = rf(ctx, shortLink, redirect, ttl)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// LinkCache_SetRedirect_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetRedirect'
+type LinkCache_SetRedirect_Call struct {
+	*mock.Call
+}
+
+// SetRedirect is a helper method to define mock.On call
+//   - ctx context.Context
+//   - shortLink string
+//   - redirect models.Redirect
 //   - ttl time.Duration
-func (_e *LinkCache_Expecter) SetOriginalURL(ctx interface{}, shortLink interface{}, originalURL interface{}, ttl interface{}) *LinkCache_SetOriginalURL_Call {
-	return &LinkCache_SetOriginalURL_Call{Call: _e.mock.On("SetOriginalURL", ctx, shortLink, originalURL, ttl)}
+func (_e *LinkCache_Expecter) SetRedirect(ctx interface{}, shortLink interface{}, redirect interface{}, ttl interface{}) *LinkCache_SetRedirect_Call {
+	return &LinkCache_SetRedirect_Call{Call: _e.mock.On("SetRedirect", ctx, shortLink, redirect, ttl)}
 }
 
-func (_c *LinkCache_SetOriginalURL_Call) Run(run func(ctx context.Context, shortLink string, originalURL string, ttl time.Duration)) *LinkCache_SetOriginalURL_Call {
+func (_c *LinkCache_SetRedirect_Call) Run(run func(ctx context.Context, shortLink string, redirect models.Redirect, ttl time.Duration)) *LinkCache_SetRedirect_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(time.Duration))
+		run(args[0].(context.Context), args[1].(string), args[2].(models.Redirect), args[3].(time.Duration))
 	})
 	return _c
 }
 
-func (_c *LinkCache_SetOriginalURL_Call) Return(_a0 error) *LinkCache_SetOriginalURL_Call {
+func (_c *LinkCache_SetRedirect_Call) Return(_a0 error) *LinkCache_SetRedirect_Call {
 	_c.Call.Return(_a0)
 	return _c
 }
 
-func (_c *LinkCache_SetOriginalURL_Call) RunAndReturn(run func(context.Context, string, string, time.Duration) error) *LinkCache_SetOriginalURL_Call {
+func (_c *LinkCache_SetRedirect_Call) RunAndReturn(run func(context.Context, string, models.Redirect, time.Duration) error) *LinkCache_SetRedirect_Call {
 	_c.Call.Return(run)
 	return _c
 }
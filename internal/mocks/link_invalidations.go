@@ -0,0 +1,84 @@
+// Code generated by mockery v2.53.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// LinkInvalidations is an autogenerated mock type for the LinkInvalidations type
+type LinkInvalidations struct {
+	mock.Mock
+}
+
+type LinkInvalidations_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *LinkInvalidations) EXPECT() *LinkInvalidations_Expecter {
+	return &LinkInvalidations_Expecter{mock: &_m.Mock}
+}
+
+// Publish provides a mock function with given fields: ctx, shortLink, originalURL
+func (_m *LinkInvalidations) Publish(ctx context.Context, shortLink string, originalURL string) error {
+	ret := _m.Called(ctx, shortLink, originalURL)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Publish")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, shortLink, originalURL)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// LinkInvalidations_Publish_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Publish'
+type LinkInvalidations_Publish_Call struct {
+	*mock.Call
+}
+
+// Publish is a helper method to define mock.On call
+//   - ctx context.Context
+//   - shortLink string
+//   - originalURL string
+func (_e *LinkInvalidations_Expecter) Publish(ctx interface{}, shortLink interface{}, originalURL interface{}) *LinkInvalidations_Publish_Call {
+	return &LinkInvalidations_Publish_Call{Call: _e.mock.On("Publish", ctx, shortLink, originalURL)}
+}
+
+func (_c *LinkInvalidations_Publish_Call) Run(run func(ctx context.Context, shortLink string, originalURL string)) *LinkInvalidations_Publish_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *LinkInvalidations_Publish_Call) Return(_a0 error) *LinkInvalidations_Publish_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *LinkInvalidations_Publish_Call) RunAndReturn(run func(context.Context, string, string) error) *LinkInvalidations_Publish_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewLinkInvalidations creates a new instance of LinkInvalidations. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewLinkInvalidations(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *LinkInvalidations {
+	mock := &LinkInvalidations{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -22,17 +22,17 @@ func (_m *LinkRepo) EXPECT() *LinkRepo_Expecter {
 	return &LinkRepo_Expecter{mock: &_m.Mock}
 }
 
-// DeleteOldLinks provides a mock function with given fields: ctx, threshold
-func (_m *LinkRepo) DeleteOldLinks(ctx context.Context, threshold string) error {
-	ret := _m.Called(ctx, threshold)
+// Delete provides a mock function with given fields: ctx, shortLink
+func (_m *LinkRepo) Delete(ctx context.Context, shortLink string) error {
+	ret := _m.Called(ctx, shortLink)
 
 	if len(ret) == 0 {
-		panic("no return value specified for DeleteOldLinks")
+		panic("no return value specified for Delete")
 	}
 
 	var r0 error
 	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
-		r0 = rf(ctx, threshold)
+		r0 = rf(ctx, shortLink)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -40,52 +40,100 @@ func (_m *LinkRepo) DeleteOldLinks(ctx context.Context, threshold string) error
 	return r0
 }
 
-// LinkRepo_DeleteOldLinks_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteOldLinks'
-type LinkRepo_DeleteOldLinks_Call struct {
+// LinkRepo_Delete_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Delete'
+type LinkRepo_Delete_Call struct {
 	*mock.Call
 }
 
-// DeleteOldLinks is a helper method to define mock.On call
+// Delete is a helper method to define mock.On call
 //   - ctx context.Context
-//   - threshold string
-func (_e *LinkRepo_Expecter) DeleteOldLinks(ctx interface{}, threshold interface{}) *LinkRepo_DeleteOldLinks_Call {
-	return &LinkRepo_DeleteOldLinks_Call{Call: _e.mock.On("DeleteOldLinks", ctx, threshold)}
+//   - shortLink string
+func (_e *LinkRepo_Expecter) Delete(ctx interface{}, shortLink interface{}) *LinkRepo_Delete_Call {
+	return &LinkRepo_Delete_Call{Call: _e.mock.On("Delete", ctx, shortLink)}
 }
 
-func (_c *LinkRepo_DeleteOldLinks_Call) Run(run func(ctx context.Context, threshold string)) *LinkRepo_DeleteOldLinks_Call {
+func (_c *LinkRepo_Delete_Call) Run(run func(ctx context.Context, shortLink string)) *LinkRepo_Delete_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		run(args[0].(context.Context), args[1].(string))
 	})
 	return _c
 }
 
-func (_c *LinkRepo_DeleteOldLinks_Call) Return(_a0 error) *LinkRepo_DeleteOldLinks_Call {
+func (_c *LinkRepo_Delete_Call) Return(_a0 error) *LinkRepo_Delete_Call {
 	_c.Call.Return(_a0)
 	return _c
 }
 
-func (_c *LinkRepo_DeleteOldLinks_Call) RunAndReturn(run func(context.Context, string) error) *LinkRepo_DeleteOldLinks_Call {
+func (_c *LinkRepo_Delete_Call) RunAndReturn(run func(context.Context, string) error) *LinkRepo_Delete_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteExpiredLinks provides a mock function with given fields: ctx
+func (_m *LinkRepo) DeleteExpiredLinks(ctx context.Context) error {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteExpiredLinks")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// LinkRepo_DeleteExpiredLinks_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteExpiredLinks'
+type LinkRepo_DeleteExpiredLinks_Call struct {
+	*mock.Call
+}
+
+// DeleteExpiredLinks is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *LinkRepo_Expecter) DeleteExpiredLinks(ctx interface{}) *LinkRepo_DeleteExpiredLinks_Call {
+	return &LinkRepo_DeleteExpiredLinks_Call{Call: _e.mock.On("DeleteExpiredLinks", ctx)}
+}
+
+func (_c *LinkRepo_DeleteExpiredLinks_Call) Run(run func(ctx context.Context)) *LinkRepo_DeleteExpiredLinks_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *LinkRepo_DeleteExpiredLinks_Call) Return(_a0 error) *LinkRepo_DeleteExpiredLinks_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *LinkRepo_DeleteExpiredLinks_Call) RunAndReturn(run func(context.Context) error) *LinkRepo_DeleteExpiredLinks_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
 // FindByOriginalURL provides a mock function with given fields: ctx, originalURL
-func (_m *LinkRepo) FindByOriginalURL(ctx context.Context, originalURL string) (string, error) {
+func (_m *LinkRepo) FindByOriginalURL(ctx context.Context, originalURL string) (*models.LinkURL, error) {
 	ret := _m.Called(ctx, originalURL)
 
 	if len(ret) == 0 {
 		panic("no return value specified for FindByOriginalURL")
 	}
 
-	var r0 string
+	var r0 *models.LinkURL
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string) (string, error)); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*models.LinkURL, error)); ok {
 		return rf(ctx, originalURL)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string) string); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, string) *models.LinkURL); ok {
 		r0 = rf(ctx, originalURL)
 	} else {
-		r0 = ret.Get(0).(string)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.LinkURL)
+		}
 	}
 
 	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
@@ -116,12 +164,12 @@ func (_c *LinkRepo_FindByOriginalURL_Call) Run(run func(ctx context.Context, ori
 	return _c
 }
 
-func (_c *LinkRepo_FindByOriginalURL_Call) Return(_a0 string, _a1 error) *LinkRepo_FindByOriginalURL_Call {
+func (_c *LinkRepo_FindByOriginalURL_Call) Return(_a0 *models.LinkURL, _a1 error) *LinkRepo_FindByOriginalURL_Call {
 	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *LinkRepo_FindByOriginalURL_Call) RunAndReturn(run func(context.Context, string) (string, error)) *LinkRepo_FindByOriginalURL_Call {
+func (_c *LinkRepo_FindByOriginalURL_Call) RunAndReturn(run func(context.Context, string) (*models.LinkURL, error)) *LinkRepo_FindByOriginalURL_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -183,70 +231,93 @@ func (_c *LinkRepo_FindByShortLink_Call) RunAndReturn(run func(context.Context,
 	return _c
 }
 
-// Insert provides a mock function with given fields: ctx, originalURL, shortLink
-func (_m *LinkRepo) Insert(ctx context.Context, originalURL string, shortLink string) error {
-	ret := _m.Called(ctx, originalURL, shortLink)
+// FindLink provides a mock function with given fields: ctx, shortLink
+func (_m *LinkRepo) FindLink(ctx context.Context, shortLink string) (*models.LinkURL, error) {
+	ret := _m.Called(ctx, shortLink)
 
 	if len(ret) == 0 {
-		panic("no return value specified for Insert")
+		panic("no return value specified for FindLink")
 	}
 
-	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
-		r0 = rf(ctx, originalURL, shortLink)
+	var r0 *models.LinkURL
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*models.LinkURL, error)); ok {
+		return rf(ctx, shortLink)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *models.LinkURL); ok {
+		r0 = rf(ctx, shortLink)
 	} else {
-		r0 = ret.Error(0)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.LinkURL)
+		}
 	}
 
-	return r0
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, shortLink)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
-// LinkRepo_Insert_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Insert'
-type LinkRepo_Insert_Call struct {
+// LinkRepo_FindLink_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'FindLink'
+type LinkRepo_FindLink_Call struct {
 	*mock.Call
 }
 
-// Insert is a helper method to define mock.On call
+// FindLink is a helper method to define mock.On call
 //   - ctx context.Context
-//   - originalURL string
 //   - shortLink string
-func (_e *LinkRepo_Expecter) Insert(ctx interface{}, originalURL interface{}, shortLink interface{}) *LinkRepo_Insert_Call {
-	return &LinkRepo_Insert_Call{Call: _e.mock.On("Insert", ctx, originalURL, shortLink)}
+func (_e *LinkRepo_Expecter) FindLink(ctx interface{}, shortLink interface{}) *LinkRepo_FindLink_Call {
+	return &LinkRepo_FindLink_Call{Call: _e.mock.On("FindLink", ctx, shortLink)}
 }
 
-func (_c *LinkRepo_Insert_Call) Run(run func(ctx context.Context, originalURL string, shortLink string)) *LinkRepo_Insert_Call {
+func (_c *LinkRepo_FindLink_Call) Run(run func(ctx context.Context, shortLink string)) *LinkRepo_FindLink_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(string), args[2].(string))
+		run(args[0].(context.Context), args[1].(string))
 	})
 	return _c
 }
 
-func (_c *LinkRepo_Insert_Call) Return(_a0 error) *LinkRepo_Insert_Call {
-	_c.Call.Return(_a0)
+func (_c *LinkRepo_FindLink_Call) Return(_a0 *models.LinkURL, _a1 error) *LinkRepo_FindLink_Call {
+	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *LinkRepo_Insert_Call) RunAndReturn(run func(context.Context, string, string) error) *LinkRepo_Insert_Call {
+func (_c *LinkRepo_FindLink_Call) RunAndReturn(run func(context.Context, string) (*models.LinkURL, error)) *LinkRepo_FindLink_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
 // InsertBatch provides a mock function with given fields: ctx, links
-func (_m *LinkRepo) InsertBatch(ctx context.Context, links []models.LinkURL) error {
+func (_m *LinkRepo) InsertBatch(ctx context.Context, links []models.LinkURL) ([]models.InsertStatus, error) {
 	ret := _m.Called(ctx, links)
 
 	if len(ret) == 0 {
 		panic("no return value specified for InsertBatch")
 	}
 
-	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, []models.LinkURL) error); ok {
+	var r0 []models.InsertStatus
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []models.LinkURL) ([]models.InsertStatus, error)); ok {
+		return rf(ctx, links)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []models.LinkURL) []models.InsertStatus); ok {
 		r0 = rf(ctx, links)
 	} else {
-		r0 = ret.Error(0)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.InsertStatus)
+		}
 	}
 
-	return r0
+	if rf, ok := ret.Get(1).(func(context.Context, []models.LinkURL) error); ok {
+		r1 = rf(ctx, links)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
 // LinkRepo_InsertBatch_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'InsertBatch'
@@ -268,12 +339,129 @@ func (_c *LinkRepo_InsertBatch_Call) Run(run func(ctx context.Context, links []m
 	return _c
 }
 
-func (_c *LinkRepo_InsertBatch_Call) Return(_a0 error) *LinkRepo_InsertBatch_Call {
+func (_c *LinkRepo_InsertBatch_Call) Return(_a0 []models.InsertStatus, _a1 error) *LinkRepo_InsertBatch_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *LinkRepo_InsertBatch_Call) RunAndReturn(run func(context.Context, []models.LinkURL) ([]models.InsertStatus, error)) *LinkRepo_InsertBatch_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SaveLinks provides a mock function with given fields: ctx, links, outbox
+func (_m *LinkRepo) SaveLinks(ctx context.Context, links []models.LinkURL, outbox bool) ([]models.LinkURL, []models.InsertStatus, error) {
+	ret := _m.Called(ctx, links, outbox)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveLinks")
+	}
+
+	var r0 []models.LinkURL
+	var r1 []models.InsertStatus
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, []models.LinkURL, bool) ([]models.LinkURL, []models.InsertStatus, error)); ok {
+		return rf(ctx, links, outbox)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []models.LinkURL, bool) []models.LinkURL); ok {
+		r0 = rf(ctx, links, outbox)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.LinkURL)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []models.LinkURL, bool) []models.InsertStatus); ok {
+		r1 = rf(ctx, links, outbox)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).([]models.InsertStatus)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, []models.LinkURL, bool) error); ok {
+		r2 = rf(ctx, links, outbox)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// LinkRepo_SaveLinks_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SaveLinks'
+type LinkRepo_SaveLinks_Call struct {
+	*mock.Call
+}
+
+// SaveLinks is a helper method to define mock.On call
+//   - ctx context.Context
+//   - links []models.LinkURL
+//   - outbox bool
+func (_e *LinkRepo_Expecter) SaveLinks(ctx interface{}, links interface{}, outbox interface{}) *LinkRepo_SaveLinks_Call {
+	return &LinkRepo_SaveLinks_Call{Call: _e.mock.On("SaveLinks", ctx, links, outbox)}
+}
+
+func (_c *LinkRepo_SaveLinks_Call) Run(run func(ctx context.Context, links []models.LinkURL, outbox bool)) *LinkRepo_SaveLinks_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].([]models.LinkURL), args[2].(bool))
+	})
+	return _c
+}
+
+func (_c *LinkRepo_SaveLinks_Call) Return(_a0 []models.LinkURL, _a1 []models.InsertStatus, _a2 error) *LinkRepo_SaveLinks_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *LinkRepo_SaveLinks_Call) RunAndReturn(run func(context.Context, []models.LinkURL, bool) ([]models.LinkURL, []models.InsertStatus, error)) *LinkRepo_SaveLinks_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdateOriginalURL provides a mock function with given fields: ctx, shortLink, originalURL
+func (_m *LinkRepo) UpdateOriginalURL(ctx context.Context, shortLink string, originalURL string) error {
+	ret := _m.Called(ctx, shortLink, originalURL)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateOriginalURL")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, shortLink, originalURL)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// LinkRepo_UpdateOriginalURL_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateOriginalURL'
+type LinkRepo_UpdateOriginalURL_Call struct {
+	*mock.Call
+}
+
+// UpdateOriginalURL is a helper method to define mock.On call
+//   - ctx context.Context
+//   - shortLink string
+//   - originalURL string
+func (_e *LinkRepo_Expecter) UpdateOriginalURL(ctx interface{}, shortLink interface{}, originalURL interface{}) *LinkRepo_UpdateOriginalURL_Call {
+	return &LinkRepo_UpdateOriginalURL_Call{Call: _e.mock.On("UpdateOriginalURL", ctx, shortLink, originalURL)}
+}
+
+func (_c *LinkRepo_UpdateOriginalURL_Call) Run(run func(ctx context.Context, shortLink string, originalURL string)) *LinkRepo_UpdateOriginalURL_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *LinkRepo_UpdateOriginalURL_Call) Return(_a0 error) *LinkRepo_UpdateOriginalURL_Call {
 	_c.Call.Return(_a0)
 	return _c
 }
 
-func (_c *LinkRepo_InsertBatch_Call) RunAndReturn(run func(context.Context, []models.LinkURL) error) *LinkRepo_InsertBatch_Call {
+func (_c *LinkRepo_UpdateOriginalURL_Call) RunAndReturn(run func(context.Context, string, string) error) *LinkRepo_UpdateOriginalURL_Call {
 	_c.Call.Return(run)
 	return _c
 }
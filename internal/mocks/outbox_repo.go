@@ -0,0 +1,95 @@
+// Code generated by mockery v2.53.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	models "linkreduction/internal/models"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// OutboxRepo is an autogenerated mock type for the OutboxRepo type
+type OutboxRepo struct {
+	mock.Mock
+}
+
+type OutboxRepo_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *OutboxRepo) EXPECT() *OutboxRepo_Expecter {
+	return &OutboxRepo_Expecter{mock: &_m.Mock}
+}
+
+// ProcessOutbox provides a mock function with given fields: ctx, limit, publish
+func (_m *OutboxRepo) ProcessOutbox(ctx context.Context, limit int, publish func([]models.OutboxMessage) error) (int, error) {
+	ret := _m.Called(ctx, limit, publish)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ProcessOutbox")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, func([]models.OutboxMessage) error) (int, error)); ok {
+		return rf(ctx, limit, publish)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, func([]models.OutboxMessage) error) int); ok {
+		r0 = rf(ctx, limit, publish)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, func([]models.OutboxMessage) error) error); ok {
+		r1 = rf(ctx, limit, publish)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// OutboxRepo_ProcessOutbox_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ProcessOutbox'
+type OutboxRepo_ProcessOutbox_Call struct {
+	*mock.Call
+}
+
+// ProcessOutbox is a helper method to define mock.On call
+//   - ctx context.Context
+//   - limit int
+//   - publish func([]models.OutboxMessage) error
+func (_e *OutboxRepo_Expecter) ProcessOutbox(ctx interface{}, limit interface{}, publish interface{}) *OutboxRepo_ProcessOutbox_Call {
+	return &OutboxRepo_ProcessOutbox_Call{Call: _e.mock.On("ProcessOutbox", ctx, limit, publish)}
+}
+
+func (_c *OutboxRepo_ProcessOutbox_Call) Run(run func(ctx context.Context, limit int, publish func([]models.OutboxMessage) error)) *OutboxRepo_ProcessOutbox_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(func([]models.OutboxMessage) error))
+	})
+	return _c
+}
+
+func (_c *OutboxRepo_ProcessOutbox_Call) Return(_a0 int, _a1 error) *OutboxRepo_ProcessOutbox_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *OutboxRepo_ProcessOutbox_Call) RunAndReturn(run func(context.Context, int, func([]models.OutboxMessage) error) (int, error)) *OutboxRepo_ProcessOutbox_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewOutboxRepo creates a new instance of OutboxRepo. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewOutboxRepo(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *OutboxRepo {
+	mock := &OutboxRepo{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,104 @@
+// Code generated by mockery v2.53.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// RateLimitStore is an autogenerated mock type for the RateLimitStore type
+type RateLimitStore struct {
+	mock.Mock
+}
+
+type RateLimitStore_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *RateLimitStore) EXPECT() *RateLimitStore_Expecter {
+	return &RateLimitStore_Expecter{mock: &_m.Mock}
+}
+
+// Allow provides a mock function with given fields: ctx, key, limit, window
+func (_m *RateLimitStore) Allow(ctx context.Context, key string, limit int, window time.Duration) (bool, time.Duration, error) {
+	ret := _m.Called(ctx, key, limit, window)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Allow")
+	}
+
+	var r0 bool
+	var r1 time.Duration
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int, time.Duration) (bool, time.Duration, error)); ok {
+		return rf(ctx, key, limit, window)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, int, time.Duration) bool); ok {
+		r0 = rf(ctx, key, limit, window)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, int, time.Duration) time.Duration); ok {
+		r1 = rf(ctx, key, limit, window)
+	} else {
+		r1 = ret.Get(1).(time.Duration)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, int, time.Duration) error); ok {
+		r2 = rf(ctx, key, limit, window)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// RateLimitStore_Allow_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Allow'
+type RateLimitStore_Allow_Call struct {
+	*mock.Call
+}
+
+// Allow is a helper method to define mock.On call
+//   - ctx context.Context
+//   - key string
+//   - limit int
+//   - window time.Duration
+func (_e *RateLimitStore_Expecter) Allow(ctx interface{}, key interface{}, limit interface{}, window interface{}) *RateLimitStore_Allow_Call {
+	return &RateLimitStore_Allow_Call{Call: _e.mock.On("Allow", ctx, key, limit, window)}
+}
+
+func (_c *RateLimitStore_Allow_Call) Run(run func(ctx context.Context, key string, limit int, window time.Duration)) *RateLimitStore_Allow_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(int), args[3].(time.Duration))
+	})
+	return _c
+}
+
+func (_c *RateLimitStore_Allow_Call) Return(_a0 bool, _a1 time.Duration, _a2 error) *RateLimitStore_Allow_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *RateLimitStore_Allow_Call) RunAndReturn(run func(context.Context, string, int, time.Duration) (bool, time.Duration, error)) *RateLimitStore_Allow_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewRateLimitStore creates a new instance of RateLimitStore. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewRateLimitStore(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *RateLimitStore {
+	mock := &RateLimitStore{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
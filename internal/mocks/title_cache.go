@@ -0,0 +1,151 @@
+// Code generated by mockery v2.53.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// TitleCache is an autogenerated mock type for the TitleCache type
+type TitleCache struct {
+	mock.Mock
+}
+
+type TitleCache_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *TitleCache) EXPECT() *TitleCache_Expecter {
+	return &TitleCache_Expecter{mock: &_m.Mock}
+}
+
+// GetTitle provides a mock function with given fields: ctx, pageURL
+func (_m *TitleCache) GetTitle(ctx context.Context, pageURL string) (string, bool, error) {
+	ret := _m.Called(ctx, pageURL)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTitle")
+	}
+
+	var r0 string
+	var r1 bool
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (string, bool, error)); ok {
+		return rf(ctx, pageURL)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) string); ok {
+		r0 = rf(ctx, pageURL)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) bool); ok {
+		r1 = rf(ctx, pageURL)
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string) error); ok {
+		r2 = rf(ctx, pageURL)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// TitleCache_GetTitle_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetTitle'
+type TitleCache_GetTitle_Call struct {
+	*mock.Call
+}
+
+// GetTitle is a helper method to define mock.On call
+//   - ctx context.Context
+//   - pageURL string
+func (_e *TitleCache_Expecter) GetTitle(ctx interface{}, pageURL interface{}) *TitleCache_GetTitle_Call {
+	return &TitleCache_GetTitle_Call{Call: _e.mock.On("GetTitle", ctx, pageURL)}
+}
+
+func (_c *TitleCache_GetTitle_Call) Run(run func(ctx context.Context, pageURL string)) *TitleCache_GetTitle_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *TitleCache_GetTitle_Call) Return(title string, ok bool, err error) *TitleCache_GetTitle_Call {
+	_c.Call.Return(title, ok, err)
+	return _c
+}
+
+func (_c *TitleCache_GetTitle_Call) RunAndReturn(run func(context.Context, string) (string, bool, error)) *TitleCache_GetTitle_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetTitle provides a mock function with given fields: ctx, pageURL, title, ttl
+func (_m *TitleCache) SetTitle(ctx context.Context, pageURL string, title string, ttl time.Duration) error {
+	ret := _m.Called(ctx, pageURL, title, ttl)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetTitle")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, time.Duration) error); ok {
+		r0 = rf(ctx, pageURL, title, ttl)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// TitleCache_SetTitle_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetTitle'
+type TitleCache_SetTitle_Call struct {
+	*mock.Call
+}
+
+// SetTitle is a helper method to define mock.On call
+//   - ctx context.Context
+//   - pageURL string
+//   - title string
+//   - ttl time.Duration
+func (_e *TitleCache_Expecter) SetTitle(ctx interface{}, pageURL interface{}, title interface{}, ttl interface{}) *TitleCache_SetTitle_Call {
+	return &TitleCache_SetTitle_Call{Call: _e.mock.On("SetTitle", ctx, pageURL, title, ttl)}
+}
+
+func (_c *TitleCache_SetTitle_Call) Run(run func(ctx context.Context, pageURL string, title string, ttl time.Duration)) *TitleCache_SetTitle_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(time.Duration))
+	})
+	return _c
+}
+
+func (_c *TitleCache_SetTitle_Call) Return(_a0 error) *TitleCache_SetTitle_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *TitleCache_SetTitle_Call) RunAndReturn(run func(context.Context, string, string, time.Duration) error) *TitleCache_SetTitle_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewTitleCache creates a new instance of TitleCache. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewTitleCache(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *TitleCache {
+	mock := &TitleCache{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
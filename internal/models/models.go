@@ -1,6 +1,117 @@
 package models
 
+import "time"
+
 type LinkURL struct {
 	OriginalURL string
 	ShortLink   string
+	// Custom отмечает ссылки с собственными параметрами (псевдоним, срок жизни):
+	// они не переиспользуются для других запросов с тем же URL.
+	Custom bool
+	// ExpiresAt — момент истечения ссылки, nil для бессрочных ссылок.
+	ExpiresAt *time.Time
+	CreatedAt time.Time
+	// OwnerID — владелец ссылки, пустой для анонимных ссылок.
+	OwnerID string
+	// RedirectCode — HTTP-код перенаправления: 301, 302, 307 или 308.
+	RedirectCode int
+	// PasswordHash — bcrypt-хэш пароля ссылки, пустой для ссылок без пароля.
+	PasswordHash string
+	// Interstitial — перед переходом всегда показывается страница предпросмотра.
+	Interstitial bool
+}
+
+// InsertStatus — результат сохранения одной ссылки из пакета.
+type InsertStatus string
+
+const (
+	InsertInserted InsertStatus = "inserted"
+	// InsertExisting — та же ссылка уже сохранена, например при повторной доставке сообщения.
+	InsertExisting InsertStatus = "existing"
+	// InsertConflict — ключ или общая ссылка на тот же URL заняты другой ссылкой: ссылка не сохранена.
+	InsertConflict InsertStatus = "conflict"
+	// InsertExpired — срок действия ссылки истёк до сохранения: ссылка не сохранена.
+	InsertExpired InsertStatus = "expired"
+)
+
+// Persisted сообщает, есть ли ссылка в хранилище после вставки.
+func (s InsertStatus) Persisted() bool {
+	return s == InsertInserted || s == InsertExisting
+}
+
+// Redirect — данные, необходимые для перехода по короткой ссылке; хранятся в кэше.
+type Redirect struct {
+	URL  string `json:"url"`
+	Code int    `json:"code"`
+	// PasswordHash хранится в кэше вместе с адресом, чтобы кэш не позволял обойти проверку пароля.
+	PasswordHash string `json:"password_hash,omitempty"`
+	Interstitial bool   `json:"interstitial,omitempty"`
+	// ExpiresAt — срок действия ссылки: по нему кэш процесса ограничивает время жизни записи,
+	// полученной из нижележащего кэша.
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	// NotFound отмечает отрицательную запись кэша: ссылки с таким ключом нет в базе.
+	NotFound bool `json:"not_found,omitempty"`
+}
+
+func (r Redirect) Protected() bool {
+	return r.PasswordHash != ""
+}
+
+// Expired сообщает, истёк ли срок действия ссылки к моменту now.
+func (r Redirect) Expired(now time.Time) bool {
+	return r.ExpiresAt != nil && !now.Before(*r.ExpiresAt)
+}
+
+// Expired сообщает, истёк ли срок действия ссылки к моменту now.
+func (l LinkURL) Expired(now time.Time) bool {
+	return l.ExpiresAt != nil && !now.Before(*l.ExpiresAt)
+}
+
+// OutboxMessage — сообщение Kafka, записанное в одной транзакции с изменением данных
+// и ожидающее отправки.
+type OutboxMessage struct {
+	ID        int64
+	Topic     string
+	Key       string
+	Payload   []byte
+	CreatedAt time.Time
+}
+
+// Click — один переход по короткой ссылке.
+type Click struct {
+	ShortLink string
+	ClickedAt time.Time
+	Referrer  string
+	UserAgent string
+	// IPHash — солёный SHA-256 от IP клиента, сам адрес не хранится.
+	IPHash string
+}
+
+// LinkStats — агрегированная статистика переходов по ссылке.
+type LinkStats struct {
+	ShortLink      string           `json:"short_link"`
+	TotalClicks    int64            `json:"total_clicks"`
+	UniqueVisitors int64            `json:"unique_visitors"`
+	Daily          []DailyClicks    `json:"daily"`
+	TopReferrers   []ReferrerClicks `json:"top_referrers"`
+}
+
+type DailyClicks struct {
+	Date   string `json:"date"`
+	Clicks int64  `json:"clicks"`
+}
+
+type ReferrerClicks struct {
+	Referrer string `json:"referrer"`
+	Clicks   int64  `json:"clicks"`
+}
+
+// APIKey — выпущенный владельцу ключ доступа к API, в открытом виде не хранится.
+type APIKey struct {
+	ID        int64
+	OwnerID   string
+	Name      string
+	KeyHash   string
+	CreatedAt time.Time
+	RevokedAt *time.Time
 }
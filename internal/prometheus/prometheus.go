@@ -10,6 +10,17 @@ import (
 type PrometheusMetrics struct {
 	CreateShortLinkTotal *prometheus.CounterVec
 	RedirectTotal        *prometheus.CounterVec
+	ClickEventsTotal     *prometheus.CounterVec
+	RateLimitedTotal     *prometheus.CounterVec
+	OutboxMessagesTotal  *prometheus.CounterVec
+	// LocalCacheTotal считает обращения к кэшу процесса по виду записи (redirect, shorten) и результату (hit, miss).
+	LocalCacheTotal          *prometheus.CounterVec
+	LocalCacheEvictionsTotal prometheus.Counter
+	// CacheErrorsTotal считает сбои кэша, при которых запрос обслужен без него, по операциям.
+	CacheErrorsTotal *prometheus.CounterVec
+	RedisCircuitOpen prometheus.Gauge
+	// BatchLinksTotal считает ссылки из Kafka по результату сохранения (inserted, existing, conflict, expired).
+	BatchLinksTotal *prometheus.CounterVec
 }
 
 func InitPrometheus() *PrometheusMetrics {
@@ -39,10 +50,72 @@ func InitPrometheus() *PrometheusMetrics {
 			},
 			[]string{"status", "reason"},
 		),
+		ClickEventsTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "shortener_click_events_total",
+				Help: "Total number of recorded click event batches and dropped click events",
+			},
+			[]string{"status"},
+		),
+		RateLimitedTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "shortener_rate_limited_total",
+				Help: "Total number of requests rejected by the rate limiter",
+			},
+			[]string{"scope"},
+		),
+		OutboxMessagesTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "shortener_outbox_messages_total",
+				Help: "Total number of outbox messages published to Kafka and failed publish attempts",
+			},
+			[]string{"status"},
+		),
+		LocalCacheTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "shortener_local_cache_requests_total",
+				Help: "Total number of in-process link cache lookups by kind and result",
+			},
+			[]string{"kind", "result"},
+		),
+		LocalCacheEvictionsTotal: prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Name: "shortener_local_cache_evictions_total",
+				Help: "Total number of entries evicted from the in-process link cache because it was full",
+			},
+		),
+		CacheErrorsTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "shortener_cache_errors_total",
+				Help: "Total number of failed cache operations served without the cache",
+			},
+			[]string{"op"},
+		),
+		RedisCircuitOpen: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "shortener_redis_circuit_open",
+				Help: "1 while the Redis circuit breaker is open and Redis commands are skipped",
+			},
+		),
+		BatchLinksTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "shortener_batch_links_total",
+				Help: "Total number of links from Kafka batches by insert outcome",
+			},
+			[]string{"status"},
+		),
 	}
 
 	prometheus.MustRegister(metrics.CreateShortLinkTotal)
 	prometheus.MustRegister(metrics.RedirectTotal)
+	prometheus.MustRegister(metrics.ClickEventsTotal)
+	prometheus.MustRegister(metrics.RateLimitedTotal)
+	prometheus.MustRegister(metrics.OutboxMessagesTotal)
+	prometheus.MustRegister(metrics.LocalCacheTotal)
+	prometheus.MustRegister(metrics.LocalCacheEvictionsTotal)
+	prometheus.MustRegister(metrics.CacheErrorsTotal)
+	prometheus.MustRegister(metrics.RedisCircuitOpen)
+	prometheus.MustRegister(metrics.BatchLinksTotal)
 
 	return metrics
 }
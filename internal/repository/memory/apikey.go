@@ -0,0 +1,57 @@
+package memory
+
+import (
+	"context"
+	"linkreduction/internal/models"
+	"sync"
+	"time"
+)
+
+// APIKey хранит API-ключи в памяти процесса; используется, когда Postgres не настроен.
+type APIKey struct {
+	mu     sync.RWMutex
+	keys   map[int64]models.APIKey
+	byHash map[string]int64
+	seq    int64
+}
+
+func NewAPIKeyRepository() *APIKey {
+	return &APIKey{keys: make(map[int64]models.APIKey), byHash: make(map[string]int64)}
+}
+
+func (r *APIKey) CreateAPIKey(_ context.Context, key models.APIKey) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.seq++
+	key.ID = r.seq
+	r.keys[key.ID] = key
+	r.byHash[key.KeyHash] = key.ID
+	return key.ID, nil
+}
+
+func (r *APIKey) FindAPIKeyByHash(_ context.Context, keyHash string) (*models.APIKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	id, ok := r.byHash[keyHash]
+	if !ok {
+		return nil, nil
+	}
+	key := r.keys[id]
+	return &key, nil
+}
+
+func (r *APIKey) RevokeAPIKey(_ context.Context, id int64) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key, ok := r.keys[id]
+	if !ok || key.RevokedAt != nil {
+		return false, nil
+	}
+	now := time.Now()
+	key.RevokedAt = &now
+	r.keys[id] = key
+	return true, nil
+}
@@ -0,0 +1,32 @@
+package memory
+
+import (
+	"context"
+	"linkreduction/internal/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIKey_Revoke(t *testing.T) {
+	ctx := context.Background()
+	repo := NewAPIKeyRepository()
+
+	id, err := repo.CreateAPIKey(ctx, models.APIKey{OwnerID: "alice", Name: "ci", KeyHash: "hash"})
+	assert.NoError(t, err)
+
+	key, err := repo.FindAPIKeyByHash(ctx, "hash")
+	if assert.NoError(t, err) && assert.NotNil(t, key) {
+		assert.Equal(t, id, key.ID)
+		assert.Nil(t, key.RevokedAt)
+	}
+
+	revoked, err := repo.RevokeAPIKey(ctx, id)
+	assert.NoError(t, err)
+	assert.True(t, revoked)
+	revoked, _ = repo.RevokeAPIKey(ctx, id)
+	assert.False(t, revoked, "повторный отзыв ничего не меняет")
+
+	key, _ = repo.FindAPIKeyByHash(ctx, "hash")
+	assert.NotNil(t, key.RevokedAt)
+}
@@ -0,0 +1,215 @@
+package memory
+
+import (
+	"container/list"
+	"context"
+	"linkreduction/internal/models"
+	initprometheus "linkreduction/internal/prometheus"
+	"linkreduction/internal/service"
+	"sync"
+	"time"
+)
+
+const (
+	defaultLocalCacheTTL = 30 * time.Second
+
+	kindRedirect = "redirect"
+	kindShorten  = "shorten"
+)
+
+type cacheEntry struct {
+	key       string
+	shortLink string
+	redirect  models.Redirect
+	expiresAt time.Time
+}
+
+// LinkCache — ограниченный по размеру LRU-кэш процесса перед другим LinkCache (обычно Redis).
+// Записи живут не дольше ttl: так ограничивается устаревание, если сообщение об инвалидации
+// до экземпляра не дошло.
+type LinkCache struct {
+	next          service.LinkCache
+	invalidations service.LinkInvalidations
+	metrics       *initprometheus.PrometheusMetrics
+	maxEntries    int
+	ttl           time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	order   *list.List
+}
+
+// NewLinkCache оборачивает next. invalidations может быть nil — тогда сброс кэша
+// не рассылается другим экземплярам.
+func NewLinkCache(next service.LinkCache, invalidations service.LinkInvalidations, metrics *initprometheus.PrometheusMetrics,
+	maxEntries int, ttl time.Duration) *LinkCache {
+	if ttl <= 0 {
+		ttl = defaultLocalCacheTTL
+	}
+	return &LinkCache{
+		next:          next,
+		invalidations: invalidations,
+		metrics:       metrics,
+		maxEntries:    maxEntries,
+		ttl:           ttl,
+		entries:       make(map[string]*list.Element),
+		order:         list.New(),
+	}
+}
+
+func (c *LinkCache) GetShortLink(ctx context.Context, originalURL string) (string, error) {
+	if entry, ok := c.get(kindShorten, kindShorten+":"+originalURL); ok {
+		return entry.shortLink, nil
+	}
+
+	// Оставшийся срок записи нижележащего кэша неизвестен, поэтому в памяти сохраняются только
+	// записи из SetShortLink: иначе ключ истёкшей ссылки переживал бы её срок
+	return c.next.GetShortLink(ctx, originalURL)
+}
+
+func (c *LinkCache) SetShortLink(ctx context.Context, originalURL, shortLink string, ttl time.Duration) error {
+	if err := c.next.SetShortLink(ctx, originalURL, shortLink, ttl); err != nil {
+		return err
+	}
+	c.put(cacheEntry{key: kindShorten + ":" + originalURL, shortLink: shortLink}, ttl)
+	return nil
+}
+
+func (c *LinkCache) GetRedirect(ctx context.Context, shortLink string) (*models.Redirect, error) {
+	if entry, ok := c.get(kindRedirect, kindRedirect+":"+shortLink); ok {
+		redirect := entry.redirect
+		return &redirect, nil
+	}
+
+	// Отрицательные записи живут только в нижележащем кэше: созданная ссылка сразу видна всем экземплярам
+	redirect, err := c.next.GetRedirect(ctx, shortLink)
+	if err != nil || redirect == nil || redirect.NotFound {
+		return redirect, err
+	}
+	ttl := c.ttl
+	if redirect.ExpiresAt != nil {
+		ttl = min(ttl, time.Until(*redirect.ExpiresAt))
+	}
+	c.put(cacheEntry{key: kindRedirect + ":" + shortLink, redirect: *redirect}, ttl)
+	return redirect, nil
+}
+
+func (c *LinkCache) SetRedirect(ctx context.Context, shortLink string, redirect models.Redirect, ttl time.Duration) error {
+	if err := c.next.SetRedirect(ctx, shortLink, redirect, ttl); err != nil || redirect.NotFound {
+		return err
+	}
+	c.put(cacheEntry{key: kindRedirect + ":" + shortLink, redirect: redirect}, ttl)
+	return nil
+}
+
+func (c *LinkCache) DeleteRedirects(ctx context.Context, shortLinks []string) error {
+	c.mu.Lock()
+	for _, shortLink := range shortLinks {
+		c.remove(kindRedirect + ":" + shortLink)
+	}
+	c.mu.Unlock()
+
+	return c.next.DeleteRedirects(ctx, shortLinks)
+}
+
+// Invalidate сбрасывает записи в нижележащем кэше и в памяти, затем рассылает сброс остальным экземплярам.
+func (c *LinkCache) Invalidate(ctx context.Context, shortLink, originalURL string) error {
+	c.Evict(shortLink, originalURL)
+	if err := c.next.Invalidate(ctx, shortLink, originalURL); err != nil {
+		return err
+	}
+	if c.invalidations == nil {
+		return nil
+	}
+	return c.invalidations.Publish(ctx, shortLink, originalURL)
+}
+
+// Evict удаляет записи ссылки только из памяти процесса; вызывается при получении сообщения об инвалидации.
+func (c *LinkCache) Evict(shortLink, originalURL string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if shortLink != "" {
+		c.remove(kindRedirect + ":" + shortLink)
+	}
+	if originalURL != "" {
+		c.remove(kindShorten + ":" + originalURL)
+	}
+}
+
+// Purge очищает кэш процесса целиком, например после переподключения к каналу инвалидации,
+// когда часть сообщений могла быть пропущена.
+func (c *LinkCache) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries = make(map[string]*list.Element)
+	c.order.Init()
+}
+
+// Len возвращает число записей в кэше, включая ещё не удалённые истёкшие.
+func (c *LinkCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+func (c *LinkCache) get(kind, key string) (cacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if ok && time.Now().After(elem.Value.(*cacheEntry).expiresAt) {
+		c.remove(key)
+		ok = false
+	}
+	c.observe(kind, ok)
+	if !ok {
+		return cacheEntry{}, false
+	}
+	c.order.MoveToFront(elem)
+	return *elem.Value.(*cacheEntry), true
+}
+
+// put сохраняет запись не дольше ttl кэша; при переполнении вытесняется давно не использованная запись.
+func (c *LinkCache) put(entry cacheEntry, ttl time.Duration) {
+	if c.maxEntries <= 0 || ttl <= 0 {
+		return
+	}
+	entry.expiresAt = time.Now().Add(min(ttl, c.ttl))
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[entry.key]; ok {
+		*elem.Value.(*cacheEntry) = entry
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	c.entries[entry.key] = c.order.PushFront(&entry)
+	for c.order.Len() > c.maxEntries {
+		c.remove(c.order.Back().Value.(*cacheEntry).key)
+		if c.metrics != nil && c.metrics.LocalCacheEvictionsTotal != nil {
+			c.metrics.LocalCacheEvictionsTotal.Inc()
+		}
+	}
+}
+
+func (c *LinkCache) remove(key string) {
+	if elem, ok := c.entries[key]; ok {
+		c.order.Remove(elem)
+		delete(c.entries, key)
+	}
+}
+
+func (c *LinkCache) observe(kind string, hit bool) {
+	if c.metrics == nil || c.metrics.LocalCacheTotal == nil {
+		return
+	}
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	c.metrics.LocalCacheTotal.WithLabelValues(kind, result).Inc()
+}
@@ -0,0 +1,227 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"linkreduction/internal/mocks"
+	"linkreduction/internal/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestLinkCache_GetRedirect(t *testing.T) {
+	ctx := context.Background()
+	redirect := &models.Redirect{URL: "https://a.com", Code: 301}
+
+	t.Run("hit is served from memory", func(t *testing.T) {
+		next := new(mocks.LinkCache)
+		next.On("GetRedirect", mock.Anything, "a1").Return(redirect, nil).Once()
+		cache := NewLinkCache(next, nil, nil, 10, time.Minute)
+
+		for range 3 {
+			got, err := cache.GetRedirect(ctx, "a1")
+			assert.NoError(t, err)
+			assert.Equal(t, redirect, got)
+		}
+		next.AssertExpectations(t)
+	})
+
+	t.Run("miss and error are not cached", func(t *testing.T) {
+		next := new(mocks.LinkCache)
+		next.On("GetRedirect", mock.Anything, "a1").Return(nil, nil).Once()
+		next.On("GetRedirect", mock.Anything, "a1").Return(nil, fmt.Errorf("redis down")).Once()
+		next.On("GetRedirect", mock.Anything, "a1").Return(redirect, nil).Once()
+		cache := NewLinkCache(next, nil, nil, 10, time.Minute)
+
+		got, err := cache.GetRedirect(ctx, "a1")
+		assert.NoError(t, err)
+		assert.Nil(t, got)
+
+		_, err = cache.GetRedirect(ctx, "a1")
+		assert.Error(t, err)
+
+		got, err = cache.GetRedirect(ctx, "a1")
+		assert.NoError(t, err)
+		assert.Equal(t, redirect, got)
+		next.AssertExpectations(t)
+	})
+
+	t.Run("entry expires after ttl", func(t *testing.T) {
+		next := new(mocks.LinkCache)
+		next.On("GetRedirect", mock.Anything, "a1").Return(redirect, nil).Twice()
+		cache := NewLinkCache(next, nil, nil, 10, time.Millisecond)
+
+		_, _ = cache.GetRedirect(ctx, "a1")
+		time.Sleep(5 * time.Millisecond)
+		_, _ = cache.GetRedirect(ctx, "a1")
+		next.AssertExpectations(t)
+	})
+
+	t.Run("entry from next tier lives no longer than the link", func(t *testing.T) {
+		expiresAt := time.Now().Add(20 * time.Millisecond)
+		shortLived := &models.Redirect{URL: "https://a.com", Code: 301, ExpiresAt: &expiresAt}
+		next := new(mocks.LinkCache)
+		next.On("GetRedirect", mock.Anything, "a1").Return(shortLived, nil).Once()
+		next.On("GetRedirect", mock.Anything, "a1").Return(nil, nil).Once()
+		cache := NewLinkCache(next, nil, nil, 10, time.Minute)
+
+		got, err := cache.GetRedirect(ctx, "a1")
+		assert.NoError(t, err)
+		assert.Equal(t, shortLived, got)
+
+		time.Sleep(30 * time.Millisecond)
+		got, err = cache.GetRedirect(ctx, "a1")
+		assert.NoError(t, err)
+		assert.Nil(t, got, "истёкшая ссылка не должна отдаваться из памяти")
+		next.AssertExpectations(t)
+	})
+
+	t.Run("expired entry from next tier is not stored", func(t *testing.T) {
+		expiresAt := time.Now().Add(-time.Second)
+		expired := &models.Redirect{URL: "https://a.com", Code: 301, ExpiresAt: &expiresAt}
+		next := new(mocks.LinkCache)
+		next.On("GetRedirect", mock.Anything, "a1").Return(expired, nil)
+		cache := NewLinkCache(next, nil, nil, 10, time.Minute)
+
+		_, _ = cache.GetRedirect(ctx, "a1")
+		assert.Equal(t, 0, cache.Len())
+	})
+
+	t.Run("returned redirect cannot modify the cached one", func(t *testing.T) {
+		next := new(mocks.LinkCache)
+		next.On("SetRedirect", mock.Anything, "a1", *redirect, time.Minute).Return(nil)
+		cache := NewLinkCache(next, nil, nil, 10, time.Minute)
+
+		assert.NoError(t, cache.SetRedirect(ctx, "a1", *redirect, time.Minute))
+		got, _ := cache.GetRedirect(ctx, "a1")
+		got.URL = "https://evil.com"
+
+		got, _ = cache.GetRedirect(ctx, "a1")
+		assert.Equal(t, "https://a.com", got.URL)
+	})
+}
+
+func TestLinkCache_NegativeEntries(t *testing.T) {
+	ctx := context.Background()
+	missing := models.Redirect{NotFound: true}
+
+	t.Run("negative entries are not kept in memory", func(t *testing.T) {
+		next := new(mocks.LinkCache)
+		next.On("SetRedirect", mock.Anything, "probe", missing, time.Second).Return(nil).Once()
+		next.On("GetRedirect", mock.Anything, "probe").Return(&missing, nil).Twice()
+		cache := NewLinkCache(next, nil, nil, 10, time.Minute)
+
+		assert.NoError(t, cache.SetRedirect(ctx, "probe", missing, time.Second))
+		for range 2 {
+			got, err := cache.GetRedirect(ctx, "probe")
+			assert.NoError(t, err)
+			assert.True(t, got.NotFound)
+		}
+		assert.Equal(t, 0, cache.Len())
+		next.AssertExpectations(t)
+	})
+
+	t.Run("DeleteRedirects drops local and underlying entries", func(t *testing.T) {
+		next := new(mocks.LinkCache)
+		next.On("SetRedirect", mock.Anything, "a1", models.Redirect{URL: "https://a.com"}, time.Minute).Return(nil)
+		next.On("DeleteRedirects", mock.Anything, []string{"a1", "b1"}).Return(nil).Once()
+		cache := NewLinkCache(next, nil, nil, 10, time.Minute)
+
+		assert.NoError(t, cache.SetRedirect(ctx, "a1", models.Redirect{URL: "https://a.com"}, time.Minute))
+		assert.NoError(t, cache.DeleteRedirects(ctx, []string{"a1", "b1"}))
+		assert.Equal(t, 0, cache.Len())
+		next.AssertExpectations(t)
+	})
+}
+
+func TestLinkCache_GetShortLink(t *testing.T) {
+	ctx := context.Background()
+	next := new(mocks.LinkCache)
+	next.On("GetShortLink", mock.Anything, "https://a.com").Return("a1", nil).Twice()
+	cache := NewLinkCache(next, nil, nil, 10, time.Minute)
+
+	// Срок записи нижележащего кэша неизвестен, поэтому каждое чтение идёт в него
+	for range 2 {
+		shortLink, err := cache.GetShortLink(ctx, "https://a.com")
+		assert.NoError(t, err)
+		assert.Equal(t, "a1", shortLink)
+	}
+	assert.Equal(t, 0, cache.Len())
+	next.AssertExpectations(t)
+}
+
+func TestLinkCache_SizeLimit(t *testing.T) {
+	ctx := context.Background()
+	next := new(mocks.LinkCache)
+	next.On("SetShortLink", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	next.On("GetShortLink", mock.Anything, "https://b.com").Return("", nil).Once()
+	cache := NewLinkCache(next, nil, nil, 2, time.Minute)
+
+	assert.NoError(t, cache.SetShortLink(ctx, "https://a.com", "a1", time.Minute))
+	assert.NoError(t, cache.SetShortLink(ctx, "https://b.com", "b1", time.Minute))
+	// Обращение делает a.com недавно использованной, поэтому вытесняется b.com
+	shortLink, _ := cache.GetShortLink(ctx, "https://a.com")
+	assert.Equal(t, "a1", shortLink)
+	assert.NoError(t, cache.SetShortLink(ctx, "https://c.com", "c1", time.Minute))
+
+	assert.Equal(t, 2, cache.Len())
+	shortLink, _ = cache.GetShortLink(ctx, "https://b.com")
+	assert.Empty(t, shortLink)
+	shortLink, _ = cache.GetShortLink(ctx, "https://c.com")
+	assert.Equal(t, "c1", shortLink)
+	next.AssertExpectations(t)
+}
+
+func TestLinkCache_Invalidate(t *testing.T) {
+	ctx := context.Background()
+	redirect := models.Redirect{URL: "https://a.com", Code: 301}
+
+	t.Run("evicts locally and publishes to other instances", func(t *testing.T) {
+		next := new(mocks.LinkCache)
+		invalidations := new(mocks.LinkInvalidations)
+		next.On("SetRedirect", mock.Anything, "a1", redirect, time.Minute).Return(nil)
+		next.On("Invalidate", mock.Anything, "a1", "https://a.com").Return(nil)
+		next.On("GetRedirect", mock.Anything, "a1").Return(nil, nil)
+		invalidations.On("Publish", mock.Anything, "a1", "https://a.com").Return(nil).Once()
+		cache := NewLinkCache(next, invalidations, nil, 10, time.Minute)
+
+		assert.NoError(t, cache.SetRedirect(ctx, "a1", redirect, time.Minute))
+		assert.NoError(t, cache.Invalidate(ctx, "a1", "https://a.com"))
+
+		got, err := cache.GetRedirect(ctx, "a1")
+		assert.NoError(t, err)
+		assert.Nil(t, got)
+		invalidations.AssertExpectations(t)
+	})
+
+	t.Run("publish failure is returned", func(t *testing.T) {
+		next := new(mocks.LinkCache)
+		invalidations := new(mocks.LinkInvalidations)
+		next.On("Invalidate", mock.Anything, "a1", "https://a.com").Return(nil)
+		invalidations.On("Publish", mock.Anything, "a1", "https://a.com").Return(fmt.Errorf("redis down"))
+		cache := NewLinkCache(next, invalidations, nil, 10, time.Minute)
+
+		assert.Error(t, cache.Invalidate(ctx, "a1", "https://a.com"))
+	})
+
+	t.Run("evict and purge drop only local entries", func(t *testing.T) {
+		next := new(mocks.LinkCache)
+		next.On("SetRedirect", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+		next.On("SetShortLink", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+		cache := NewLinkCache(next, nil, nil, 10, time.Minute)
+
+		assert.NoError(t, cache.SetRedirect(ctx, "a1", redirect, time.Minute))
+		assert.NoError(t, cache.SetShortLink(ctx, "https://a.com", "a1", time.Minute))
+		assert.NoError(t, cache.SetRedirect(ctx, "b1", redirect, time.Minute))
+
+		cache.Evict("a1", "https://a.com")
+		assert.Equal(t, 1, cache.Len())
+
+		cache.Purge()
+		assert.Equal(t, 0, cache.Len())
+		next.AssertNotCalled(t, "Invalidate", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,76 @@
+package memory
+
+import (
+	"context"
+	"linkreduction/internal/models"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Click хранит переходы в памяти процесса; используется, когда Postgres не настроен.
+type Click struct {
+	mu     sync.RWMutex
+	clicks map[string][]models.Click
+}
+
+func NewClickRepository() *Click {
+	return &Click{clicks: make(map[string][]models.Click)}
+}
+
+func (r *Click) InsertClicks(_ context.Context, clicks []models.Click) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, click := range clicks {
+		r.clicks[click.ShortLink] = append(r.clicks[click.ShortLink], click)
+	}
+	return nil
+}
+
+// GetStats считает статистику так же, как postgres.Click: дни без переходов попадают в ряд с нулём.
+func (r *Click) GetStats(_ context.Context, shortLink string, createdAt, since time.Time, topReferrers int) (*models.LinkStats, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stats := &models.LinkStats{
+		ShortLink:    shortLink,
+		Daily:        []models.DailyClicks{},
+		TopReferrers: []models.ReferrerClicks{},
+	}
+	visitors := make(map[string]struct{})
+	daily := make(map[string]int64)
+	referrers := make(map[string]int64)
+	for _, click := range r.clicks[shortLink] {
+		if click.ClickedAt.Before(createdAt) {
+			continue
+		}
+		stats.TotalClicks++
+		if click.IPHash != "" {
+			visitors[click.IPHash] = struct{}{}
+		}
+		daily[click.ClickedAt.UTC().Format(time.DateOnly)]++
+		if !click.ClickedAt.Before(since) && click.Referrer != "" {
+			referrers[click.Referrer]++
+		}
+	}
+	stats.UniqueVisitors = int64(len(visitors))
+
+	today := time.Now().UTC().Format(time.DateOnly)
+	for day := since.UTC(); day.Format(time.DateOnly) <= today; day = day.AddDate(0, 0, 1) {
+		date := day.Format(time.DateOnly)
+		stats.Daily = append(stats.Daily, models.DailyClicks{Date: date, Clicks: daily[date]})
+	}
+
+	for referrer, clicks := range referrers {
+		stats.TopReferrers = append(stats.TopReferrers, models.ReferrerClicks{Referrer: referrer, Clicks: clicks})
+	}
+	sort.Slice(stats.TopReferrers, func(i, j int) bool {
+		a, b := stats.TopReferrers[i], stats.TopReferrers[j]
+		return a.Clicks > b.Clicks || (a.Clicks == b.Clicks && a.Referrer < b.Referrer)
+	})
+	if len(stats.TopReferrers) > topReferrers {
+		stats.TopReferrers = stats.TopReferrers[:topReferrers]
+	}
+	return stats, nil
+}
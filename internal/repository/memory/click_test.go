@@ -0,0 +1,55 @@
+package memory
+
+import (
+	"context"
+	"linkreduction/internal/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClick_GetStats(t *testing.T) {
+	ctx := context.Background()
+	repo := NewClickRepository()
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	since := today.AddDate(0, 0, -2)
+	createdAt := since.AddDate(0, 0, -7)
+
+	assert.NoError(t, repo.InsertClicks(ctx, []models.Click{
+		{ShortLink: "a1", ClickedAt: today.Add(time.Hour), Referrer: "https://b.com", IPHash: "ip1"},
+		{ShortLink: "a1", ClickedAt: today.Add(2 * time.Hour), Referrer: "https://c.com", IPHash: "ip1"},
+		{ShortLink: "a1", ClickedAt: today.Add(3 * time.Hour), Referrer: "https://c.com", IPHash: "ip2"},
+		{ShortLink: "a1", ClickedAt: since.AddDate(0, 0, -1), Referrer: "https://old.com"},
+		{ShortLink: "b1", ClickedAt: today},
+	}))
+
+	stats, err := repo.GetStats(ctx, "a1", createdAt, since, 1)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, int64(4), stats.TotalClicks)
+	assert.Equal(t, int64(2), stats.UniqueVisitors)
+	assert.Equal(t, []models.DailyClicks{
+		{Date: since.Format(time.DateOnly)},
+		{Date: since.AddDate(0, 0, 1).Format(time.DateOnly)},
+		{Date: today.Format(time.DateOnly), Clicks: 3},
+	}, stats.Daily)
+	assert.Equal(t, []models.ReferrerClicks{{Referrer: "https://c.com", Clicks: 2}}, stats.TopReferrers)
+
+	// Ключ достался новой ссылке: переходы по прежней не учитываются.
+	stats, err = repo.GetStats(ctx, "a1", today.Add(2*time.Hour), since, 5)
+	if assert.NoError(t, err) {
+		assert.Equal(t, int64(2), stats.TotalClicks)
+		assert.Equal(t, int64(2), stats.UniqueVisitors)
+		assert.Equal(t, int64(2), stats.Daily[2].Clicks)
+		assert.Equal(t, []models.ReferrerClicks{{Referrer: "https://c.com", Clicks: 2}}, stats.TopReferrers)
+	}
+
+	stats, err = repo.GetStats(ctx, "missing", createdAt, since, 5)
+	if assert.NoError(t, err) {
+		assert.Zero(t, stats.TotalClicks)
+		assert.Len(t, stats.Daily, 3)
+		assert.Empty(t, stats.TopReferrers)
+	}
+}
@@ -0,0 +1,211 @@
+package memory
+
+import (
+	"context"
+	"linkreduction/internal/models"
+	"sync"
+	"time"
+)
+
+// Link хранит ссылки в памяти процесса. Подходит для локального запуска и тестов:
+// данные не переживают перезапуск и не разделяются между экземплярами.
+type Link struct {
+	mu sync.RWMutex
+	// links — ссылки по короткому ключу
+	links map[string]models.LinkURL
+	// generated — ключи общих (не custom) ссылок по исходному URL
+	generated map[string]string
+	seq       int64
+}
+
+func NewLinkRepository() *Link {
+	return &Link{links: make(map[string]models.LinkURL), generated: make(map[string]string)}
+}
+
+func (r *Link) FindByOriginalURL(_ context.Context, originalURL string) (*models.LinkURL, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	shortLink, ok := r.generated[originalURL]
+	if !ok || r.links[shortLink].Expired(time.Now()) {
+		return nil, nil
+	}
+	link := r.links[shortLink]
+	return &link, nil
+}
+
+func (r *Link) FindByShortLink(_ context.Context, shortLink string) (string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.links[shortLink].OriginalURL, nil
+}
+
+func (r *Link) FindLink(_ context.Context, shortLink string) (*models.LinkURL, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	link, ok := r.links[shortLink]
+	if !ok {
+		return nil, nil
+	}
+	return &link, nil
+}
+
+// SaveLinks сохраняет ссылки. Общая ссылка с истёкшим сроком заменяется, действующая
+// возвращается вместо новой (InsertExisting); ссылка с занятым ключом не сохраняется
+// (InsertConflict). Outbox не поддерживается.
+func (r *Link) SaveLinks(_ context.Context, links []models.LinkURL, _ bool) ([]models.LinkURL, []models.InsertStatus, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	saved := make([]models.LinkURL, len(links))
+	statuses := make([]models.InsertStatus, len(links))
+	staged := make(map[string]models.LinkURL, len(links))
+	replaced := make(map[string]struct{})
+	generated := make(map[string]string)
+
+	lookup := func(shortLink string) (models.LinkURL, bool) {
+		if link, ok := staged[shortLink]; ok {
+			return link, true
+		}
+		if _, ok := replaced[shortLink]; ok {
+			return models.LinkURL{}, false
+		}
+		link, ok := r.links[shortLink]
+		return link, ok
+	}
+
+	for i, link := range links {
+		saved[i] = link
+		// Ключ общей ссылки с истёкшим сроком освобождается, только если новая ссылка сохраняется
+		var expiredKey string
+		if !link.Custom {
+			existingKey, ok := generated[link.OriginalURL]
+			if !ok {
+				existingKey, ok = r.generated[link.OriginalURL]
+			}
+			if existing, found := lookup(existingKey); ok && found {
+				if !existing.Expired(now) {
+					saved[i].ShortLink, saved[i].ExpiresAt = existing.ShortLink, existing.ExpiresAt
+					statuses[i] = models.InsertExisting
+					continue
+				}
+				expiredKey = existingKey
+			}
+		}
+
+		if _, taken := lookup(link.ShortLink); taken && link.ShortLink != expiredKey {
+			statuses[i] = models.InsertConflict
+			continue
+		}
+		if expiredKey != "" {
+			replaced[expiredKey] = struct{}{}
+			delete(staged, expiredKey)
+		}
+
+		link.CreatedAt = now
+		staged[link.ShortLink] = link
+		if !link.Custom {
+			generated[link.OriginalURL] = link.ShortLink
+		}
+		saved[i] = link
+		statuses[i] = models.InsertInserted
+	}
+
+	for shortLink := range replaced {
+		delete(r.links, shortLink)
+	}
+	for shortLink, link := range staged {
+		r.links[shortLink] = link
+	}
+	for originalURL, shortLink := range generated {
+		r.generated[originalURL] = shortLink
+	}
+	return saved, statuses, nil
+}
+
+// InsertBatch идемпотентно сохраняет ссылки: ссылки с занятым ключом или уже существующая
+// общая ссылка на тот же URL пропускаются.
+func (r *Link) InsertBatch(_ context.Context, links []models.LinkURL) ([]models.InsertStatus, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	statuses := make([]models.InsertStatus, len(links))
+	for i, link := range links {
+		if stored, ok := r.links[link.ShortLink]; ok {
+			statuses[i] = models.InsertConflict
+			if stored.OriginalURL == link.OriginalURL {
+				statuses[i] = models.InsertExisting
+			}
+			continue
+		}
+		if !link.Custom {
+			if _, ok := r.generated[link.OriginalURL]; ok {
+				statuses[i] = models.InsertConflict
+				continue
+			}
+			r.generated[link.OriginalURL] = link.ShortLink
+		}
+		link.CreatedAt = now
+		r.links[link.ShortLink] = link
+		statuses[i] = models.InsertInserted
+	}
+	return statuses, nil
+}
+
+// UpdateOriginalURL меняет адрес назначения ссылки; перенаправленная ссылка становится custom.
+func (r *Link) UpdateOriginalURL(_ context.Context, shortLink, originalURL string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	link, ok := r.links[shortLink]
+	if !ok {
+		return nil
+	}
+	r.forget(link)
+	link.OriginalURL = originalURL
+	link.Custom = true
+	r.links[shortLink] = link
+	return nil
+}
+
+func (r *Link) Delete(_ context.Context, shortLink string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if link, ok := r.links[shortLink]; ok {
+		r.forget(link)
+		delete(r.links, shortLink)
+	}
+	return nil
+}
+
+func (r *Link) DeleteExpiredLinks(_ context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for shortLink, link := range r.links {
+		if link.Expired(now) {
+			r.forget(link)
+			delete(r.links, shortLink)
+		}
+	}
+	return nil
+}
+
+func (r *Link) NextKeyID(_ context.Context) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.seq++
+	return r.seq, nil
+}
+
+// forget удаляет ссылку из индекса общих ссылок.
+func (r *Link) forget(link models.LinkURL) {
+	if !link.Custom && r.generated[link.OriginalURL] == link.ShortLink {
+		delete(r.generated, link.OriginalURL)
+	}
+}
@@ -0,0 +1,13 @@
+package memory
+
+import (
+	"linkreduction/internal/repository/repotest"
+	"linkreduction/internal/service"
+	"testing"
+)
+
+func TestLink_Conformance(t *testing.T) {
+	repotest.LinkRepo(t, func(t *testing.T) service.LinkRepo {
+		return NewLinkRepository()
+	})
+}
@@ -0,0 +1,47 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"linkreduction/internal/models"
+)
+
+type APIKey struct {
+	db *pgxpool.Pool
+}
+
+func NewPostgresAPIKeyRepository(db *pgxpool.Pool) *APIKey {
+	return &APIKey{db: db}
+}
+
+func (r *APIKey) CreateAPIKey(ctx context.Context, key models.APIKey) (int64, error) {
+	var id int64
+	err := r.db.QueryRow(ctx,
+		"INSERT INTO api_keys (owner_id, name, key_hash, created_at) VALUES ($1, $2, $3, $4) RETURNING id",
+		key.OwnerID, key.Name, key.KeyHash, key.CreatedAt).Scan(&id)
+	return id, err
+}
+
+func (r *APIKey) FindAPIKeyByHash(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	var key models.APIKey
+	err := r.db.QueryRow(ctx,
+		"SELECT id, owner_id, name, key_hash, created_at, revoked_at FROM api_keys WHERE key_hash = $1", keyHash).
+		Scan(&key.ID, &key.OwnerID, &key.Name, &key.KeyHash, &key.CreatedAt, &key.RevokedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (r *APIKey) RevokeAPIKey(ctx context.Context, id int64) (bool, error) {
+	tag, err := r.db.Exec(ctx, "UPDATE api_keys SET revoked_at = NOW() WHERE id = $1 AND revoked_at IS NULL", id)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
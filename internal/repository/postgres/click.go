@@ -0,0 +1,116 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"linkreduction/internal/models"
+	"slices"
+	"strings"
+	"time"
+)
+
+type Click struct {
+	db *pgxpool.Pool
+}
+
+func NewPostgresClickRepository(db *pgxpool.Pool) *Click {
+	return &Click{db: db}
+}
+
+func (r *Click) InsertClicks(ctx context.Context, clicks []models.Click) error {
+	if len(clicks) == 0 {
+		return nil
+	}
+
+	const batchSize = 100
+
+	for batch := range slices.Chunk(clicks, batchSize) {
+
+		query := `INSERT INTO link_clicks (short_link, clicked_at, referrer, user_agent, ip_hash) VALUES %s`
+		placeholders := make([]string, 0, len(batch))
+		values := make([]interface{}, 0, len(batch)*5)
+
+		for j, click := range batch {
+			placeholders = append(placeholders, fmt.Sprintf("($%d, $%d, $%d, $%d, $%d)", j*5+1, j*5+2, j*5+3, j*5+4, j*5+5))
+			values = append(values, click.ShortLink, click.ClickedAt, click.Referrer, click.UserAgent, click.IPHash)
+		}
+
+		query = fmt.Sprintf(query, strings.Join(placeholders, ","))
+
+		if _, err := r.db.Exec(ctx, query, values...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *Click) GetStats(ctx context.Context, shortLink string, createdAt, since time.Time, topReferrers int) (*models.LinkStats, error) {
+	stats := &models.LinkStats{
+		ShortLink:    shortLink,
+		Daily:        []models.DailyClicks{},
+		TopReferrers: []models.ReferrerClicks{},
+	}
+
+	err := r.db.QueryRow(ctx,
+		`SELECT COUNT(*), COUNT(DISTINCT NULLIF(ip_hash, '')) FROM link_clicks WHERE short_link = $1 AND clicked_at >= $2`,
+		shortLink, createdAt).
+		Scan(&stats.TotalClicks, &stats.UniqueVisitors)
+	if err != nil {
+		return nil, err
+	}
+
+	// Дни без переходов тоже попадают в ряд с нулевым значением
+	rows, err := r.db.Query(ctx, `
+		SELECT to_char(d, 'YYYY-MM-DD'), COUNT(c.id)
+		FROM generate_series($2::date, (NOW() AT TIME ZONE 'UTC')::date, INTERVAL '1 day') AS d
+		LEFT JOIN link_clicks c
+			ON c.short_link = $1 AND (c.clicked_at AT TIME ZONE 'UTC')::date = d::date AND c.clicked_at >= $3
+		GROUP BY d
+		ORDER BY d`, shortLink, since.UTC().Format(time.DateOnly), createdAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var day models.DailyClicks
+		if err := rows.Scan(&day.Date, &day.Clicks); err != nil {
+			return nil, err
+		}
+		stats.Daily = append(stats.Daily, day)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	refRows, err := r.db.Query(ctx, `
+		SELECT referrer, COUNT(*) AS clicks
+		FROM link_clicks
+		WHERE short_link = $1 AND clicked_at >= $2 AND referrer <> ''
+		GROUP BY referrer
+		ORDER BY clicks DESC, referrer
+		LIMIT $3`, shortLink, maxTime(since, createdAt), topReferrers)
+	if err != nil {
+		return nil, err
+	}
+	defer refRows.Close()
+
+	for refRows.Next() {
+		var ref models.ReferrerClicks
+		if err := refRows.Scan(&ref.Referrer, &ref.Clicks); err != nil {
+			return nil, err
+		}
+		stats.TopReferrers = append(stats.TopReferrers, ref)
+	}
+
+	return stats, refRows.Err()
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
@@ -2,77 +2,320 @@ package postgres
 
 import (
 	"context"
-	"database/sql"
 	"errors"
 	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"linkreduction/internal/const"
 	"linkreduction/internal/models"
-	"slices"
-	"strings"
+	"time"
 )
 
+// copyThreshold — размер пакета, начиная с которого ссылки загружаются через COPY во временную
+// таблицу; меньшие пакеты передаются массивами в одном запросе.
+const copyThreshold = 1000
+
 type Link struct {
-	db *sql.DB
+	db       *pgxpool.Pool
+	replicas *Replicas
 }
 
-func NewPostgresLinkRepository(db *sql.DB) *Link {
-	return &Link{db: db}
+// NewPostgresLinkRepository создаёт хранилище ссылок. replicas может быть nil — тогда всё читается с primary.
+func NewPostgresLinkRepository(db *pgxpool.Pool, replicas *Replicas) *Link {
+	return &Link{db: db, replicas: replicas}
 }
 
-func (r *Link) FindByOriginalURL(ctx context.Context, originalURL string) (string, error) {
-	var shortLink string
-	err := r.db.QueryRowContext(ctx, "SELECT short_link FROM links WHERE link = $1", originalURL).Scan(&shortLink)
-	if errors.Is(err, sql.ErrNoRows) {
-		return "", nil
+// queryReplica выполняет чтение на реплике. При ошибке или отсутствии строки запрос повторяется
+// на primary: ссылка могла быть создана, но ещё не дойти до реплики.
+func (r *Link) queryReplica(ctx context.Context, query string, args []any, dest ...any) error {
+	if replica := r.replicas.pick(); replica != nil {
+		err := replica.QueryRow(ctx, query, args...).Scan(dest...)
+		if err == nil || ctx.Err() != nil {
+			return err
+		}
+	}
+	return r.db.QueryRow(ctx, query, args...).Scan(dest...)
+}
+
+// FindByOriginalURL и FindByShortLink проверяют существование ссылки перед созданием и обычно
+// не находят её, поэтому читают только primary: на реплике промах стоил бы второго запроса.
+func (r *Link) FindByOriginalURL(ctx context.Context, originalURL string) (*models.LinkURL, error) {
+	link := models.LinkURL{OriginalURL: originalURL}
+	err := r.db.QueryRow(ctx, "SELECT short_link, expires_at FROM links WHERE link = $1 AND NOT custom AND (expires_at IS NULL OR expires_at > NOW())",
+		originalURL).Scan(&link.ShortLink, &link.ExpiresAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
 	}
-	return shortLink, err
+	return &link, nil
 }
 
 func (r *Link) FindByShortLink(ctx context.Context, shortLink string) (string, error) {
 	var originalURL string
-	err := r.db.QueryRowContext(ctx, "SELECT link FROM links WHERE short_link = $1", shortLink).Scan(&originalURL)
-	if errors.Is(err, sql.ErrNoRows) {
+	err := r.db.QueryRow(ctx, "SELECT link FROM links WHERE short_link = $1", shortLink).Scan(&originalURL)
+	if errors.Is(err, pgx.ErrNoRows) {
 		return "", nil
 	}
 	return originalURL, err
 }
 
-func (r *Link) Insert(ctx context.Context, originalURL, shortLink string) error {
-	_, err := r.db.ExecContext(ctx, "INSERT INTO links (link, short_link) VALUES ($1, $2) ON CONFLICT (link) DO NOTHING", originalURL, shortLink)
-	return err
+// FindLink читает ссылку для перенаправления и управления ею, поэтому идёт через реплики.
+func (r *Link) FindLink(ctx context.Context, shortLink string) (*models.LinkURL, error) {
+	var link models.LinkURL
+	err := r.queryReplica(ctx, `SELECT link, short_link, custom, expires_at, created_at, COALESCE(owner_id, ''), redirect_code,
+		COALESCE(password_hash, ''), interstitial FROM links WHERE short_link = $1`, []any{shortLink},
+		&link.OriginalURL, &link.ShortLink, &link.Custom, &link.ExpiresAt, &link.CreatedAt, &link.OwnerID, &link.RedirectCode,
+		&link.PasswordHash, &link.Interstitial)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &link, nil
 }
 
-func (r *Link) InsertBatch(ctx context.Context, links []models.LinkURL) error {
-	if len(links) == 0 {
-		return nil
+// onConflictGenerated заменяет общую ссылку на тот же URL, только если её срок действия уже истёк.
+const onConflictGenerated = `ON CONFLICT (link) WHERE NOT custom DO UPDATE
+	SET short_link = EXCLUDED.short_link, expires_at = EXCLUDED.expires_at, redirect_code = EXCLUDED.redirect_code, created_at = NOW()
+	WHERE links.expires_at IS NOT NULL AND links.expires_at <= NOW()`
+
+const insertLink = `INSERT INTO links (link, short_link, custom, expires_at, owner_id, redirect_code, password_hash, interstitial)
+	VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6, NULLIF($7, ''), $8) `
+
+// SaveLinks сохраняет ссылки в одной транзакции и, если outbox установлен, записывает в ту же
+// транзакцию сообщения о них для отправки в Kafka. Если общая ссылка на тот же URL уже существует,
+// возвращается она со статусом InsertExisting, а сообщение не записывается. Каждая ссылка вставляется
+// в своей точке сохранения, поэтому занятый ключ отклоняет только её (InsertConflict).
+func (r *Link) SaveLinks(ctx context.Context, links []models.LinkURL, outbox bool) ([]models.LinkURL, []models.InsertStatus, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	saved := make([]models.LinkURL, len(links))
+	statuses := make([]models.InsertStatus, len(links))
+	for i, link := range links {
+		status, err := saveLink(ctx, tx, &link, outbox)
+		if err != nil {
+			return nil, nil, err
+		}
+		saved[i], statuses[i] = link, status
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, nil, err
+	}
+	return saved, statuses, nil
+}
+
+// saveLink вставляет ссылку в точке сохранения внутри tx: нарушение уникальности ключа
+// откатывает только её.
+func saveLink(ctx context.Context, tx pgx.Tx, link *models.LinkURL, outbox bool) (models.InsertStatus, error) {
+	sp, err := tx.Begin(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = sp.Rollback(ctx) }()
+
+	err = sp.QueryRow(ctx, insertLink+onConflictGenerated+" RETURNING short_link",
+		link.OriginalURL, link.ShortLink, link.Custom, link.ExpiresAt, link.OwnerID, link.RedirectCode, link.PasswordHash,
+		link.Interstitial).Scan(&link.ShortLink)
+	if isShortLinkTaken(err) {
+		return models.InsertConflict, nil
 	}
 
-	const batchSize = 10
+	status := models.InsertInserted
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		// Действующая общая ссылка уже создана параллельным запросом
+		if err := sp.QueryRow(ctx, "SELECT short_link, expires_at FROM links WHERE link = $1 AND NOT custom",
+			link.OriginalURL).Scan(&link.ShortLink, &link.ExpiresAt); err != nil {
+			return "", fmt.Errorf("ошибка чтения существующей ссылки: %w", err)
+		}
+		status = models.InsertExisting
+	case err != nil:
+		return "", err
+	case outbox:
+		if err := insertOutbox(ctx, sp, message.ShortenURLsTopic, link.ShortLink, message.NewShortenMessage(*link)); err != nil {
+			return "", err
+		}
+	}
+
+	if err := sp.Commit(ctx); err != nil {
+		return "", err
+	}
+	return status, nil
+}
+
+// isShortLinkTaken сообщает, что вставка нарушила уникальность ключа: его заняли после проверки в сервисе.
+func isShortLinkTaken(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == "links_short_link_key"
+}
+
+// linkColumns — столбцы, из которых пакет ссылок вставляется в links.
+const linkColumns = "link, short_link, custom, expires_at, owner_id, redirect_code, password_hash, interstitial"
 
-	for batch := range slices.Chunk(links, batchSize) {
+// insertFrom вставляет ссылки из источника %s и возвращает вставленные. Ссылки с занятым ключом
+// или общая ссылка на URL, для которого она уже есть, пропускаются.
+const insertFrom = `INSERT INTO links (` + linkColumns + `)
+	SELECT link, short_link, custom, expires_at, NULLIF(owner_id, ''), redirect_code, NULLIF(password_hash, ''), interstitial FROM %s
+	ON CONFLICT DO NOTHING RETURNING short_link, link`
 
-		query := `INSERT INTO links (link, short_link) VALUES %s ON CONFLICT (link) DO NOTHING`
-		placeholders := make([]string, 0, len(batch))
-		values := make([]interface{}, 0, len(batch)*2)
+const unnestSource = `unnest($1::text[], $2::text[], $3::boolean[], $4::timestamptz[], $5::text[], $6::smallint[], $7::text[],
+	$8::boolean[]) AS batch (` + linkColumns + `)`
 
-		for j, link := range batch {
-			placeholders = append(placeholders, fmt.Sprintf("($%d, $%d)", j*2+1, j*2+2))
-			values = append(values, link.OriginalURL, link.ShortLink)
+// InsertBatch сохраняет ссылки из Kafka в одной транзакции и возвращает результат для каждой.
+// Вставка идемпотентна: повторная доставка сообщения возвращает InsertExisting и ничего не меняет.
+func (r *Link) InsertBatch(ctx context.Context, links []models.LinkURL) ([]models.InsertStatus, error) {
+	return r.insertBatch(ctx, links, len(links) >= copyThreshold)
+}
+
+func (r *Link) insertBatch(ctx context.Context, links []models.LinkURL, useCopy bool) ([]models.InsertStatus, error) {
+	if len(links) == 0 {
+		return nil, nil
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var rows pgx.Rows
+	if useCopy {
+		if err := copyToImport(ctx, tx, links); err != nil {
+			return nil, err
 		}
+		rows, err = tx.Query(ctx, fmt.Sprintf(insertFrom, "links_import"))
+	} else {
+		rows, err = tx.Query(ctx, fmt.Sprintf(insertFrom, unnestSource), unnestArgs(links)...)
+	}
+	if err != nil {
+		return nil, err
+	}
+	inserted := make(map[string]string, len(links))
+	var shortLink, originalURL string
+	if _, err := pgx.ForEachRow(rows, []any{&shortLink, &originalURL}, func() error {
+		inserted[shortLink] = originalURL
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	statuses, err := insertStatuses(ctx, tx, links, inserted)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return statuses, nil
+}
+
+func unnestArgs(links []models.LinkURL) []any {
+	var (
+		originalURLs  = make([]string, len(links))
+		shortLinks    = make([]string, len(links))
+		custom        = make([]bool, len(links))
+		expiresAt     = make([]*time.Time, len(links))
+		ownerIDs      = make([]string, len(links))
+		redirectCodes = make([]int16, len(links))
+		passwordHash  = make([]string, len(links))
+		interstitial  = make([]bool, len(links))
+	)
+	for i, link := range links {
+		originalURLs[i], shortLinks[i], custom[i], expiresAt[i] = link.OriginalURL, link.ShortLink, link.Custom, link.ExpiresAt
+		ownerIDs[i], redirectCodes[i] = link.OwnerID, int16(link.RedirectCode)
+		passwordHash[i], interstitial[i] = link.PasswordHash, link.Interstitial
+	}
+	return []any{originalURLs, shortLinks, custom, expiresAt, ownerIDs, redirectCodes, passwordHash, interstitial}
+}
 
-		query = fmt.Sprintf(query, strings.Join(placeholders, ","))
+// copyToImport загружает пакет через COPY во временную таблицу links_import, которая удаляется
+// при завершении транзакции. COPY не поддерживает ON CONFLICT, поэтому в links ссылки переносятся отдельным запросом.
+func copyToImport(ctx context.Context, tx pgx.Tx, links []models.LinkURL) error {
+	if _, err := tx.Exec(ctx, `CREATE TEMP TABLE links_import (link TEXT, short_link TEXT, custom BOOLEAN,
+		expires_at TIMESTAMPTZ, owner_id TEXT, redirect_code SMALLINT, password_hash TEXT, interstitial BOOLEAN) ON COMMIT DROP`); err != nil {
+		return err
+	}
+	columns := []string{"link", "short_link", "custom", "expires_at", "owner_id", "redirect_code", "password_hash", "interstitial"}
+	_, err := tx.CopyFrom(ctx, pgx.Identifier{"links_import"}, columns, pgx.CopyFromSlice(len(links), func(i int) ([]any, error) {
+		link := links[i]
+		return []any{link.OriginalURL, link.ShortLink, link.Custom, link.ExpiresAt, link.OwnerID, int16(link.RedirectCode),
+			link.PasswordHash, link.Interstitial}, nil
+	}))
+	return err
+}
 
-		_, err := r.db.ExecContext(ctx, query, values...)
+// insertStatuses определяет результат для каждой ссылки пакета. Не вставленная ссылка считается
+// сохранённой ранее, если ключ в базе указывает на тот же URL; иначе это конфликт.
+func insertStatuses(ctx context.Context, tx pgx.Tx, links []models.LinkURL, inserted map[string]string) ([]models.InsertStatus, error) {
+	stored := make(map[string]string, len(links))
+	var skipped []string
+	for _, link := range links {
+		if originalURL, ok := inserted[link.ShortLink]; ok {
+			stored[link.ShortLink] = originalURL
+		} else {
+			skipped = append(skipped, link.ShortLink)
+		}
+	}
+	if len(skipped) > 0 {
+		rows, err := tx.Query(ctx, "SELECT short_link, link FROM links WHERE short_link = ANY($1)", skipped)
 		if err != nil {
-			continue
+			return nil, err
 		}
+		var shortLink, originalURL string
+		if _, err := pgx.ForEachRow(rows, []any{&shortLink, &originalURL}, func() error {
+			stored[shortLink] = originalURL
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+	}
 
+	statuses := make([]models.InsertStatus, len(links))
+	for i, link := range links {
+		originalURL, ok := stored[link.ShortLink]
+		switch {
+		case !ok || originalURL != link.OriginalURL:
+			statuses[i] = models.InsertConflict
+		case inserted[link.ShortLink] == link.OriginalURL:
+			// Повтор той же ссылки внутри пакета вставлен один раз
+			statuses[i] = models.InsertInserted
+			delete(inserted, link.ShortLink)
+		default:
+			statuses[i] = models.InsertExisting
+		}
 	}
+	return statuses, nil
+}
 
-	return nil
+// UpdateOriginalURL меняет адрес назначения ссылки. Перенаправленная ссылка больше не считается
+// общей для нового URL, поэтому помечается как custom.
+func (r *Link) UpdateOriginalURL(ctx context.Context, shortLink, originalURL string) error {
+	_, err := r.db.Exec(ctx, "UPDATE links SET link = $2, custom = TRUE WHERE short_link = $1", shortLink, originalURL)
+	return err
+}
+
+func (r *Link) Delete(ctx context.Context, shortLink string) error {
+	_, err := r.db.Exec(ctx, "DELETE FROM links WHERE short_link = $1", shortLink)
+	return err
+}
+
+func (r *Link) NextKeyID(ctx context.Context) (int64, error) {
+	var id int64
+	err := r.db.QueryRow(ctx, "SELECT nextval('links_key_seq')").Scan(&id)
+	return id, err
 }
 
-func (r *Link) DeleteOldLinks(ctx context.Context, threshold string) error {
-	_, err := r.db.ExecContext(ctx, "DELETE FROM links WHERE created_at < NOW() - INTERVAL $1", threshold)
+func (r *Link) DeleteExpiredLinks(ctx context.Context) error {
+	_, err := r.db.Exec(ctx, "DELETE FROM links WHERE expires_at IS NOT NULL AND expires_at <= NOW()")
 	if err != nil {
 		return err
 	}
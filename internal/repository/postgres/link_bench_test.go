@@ -0,0 +1,86 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"linkreduction/internal/models"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func batchLinks(prefix string, n int) []models.LinkURL {
+	expiresAt := time.Now().Add(time.Hour)
+	links := make([]models.LinkURL, n)
+	for i := range links {
+		links[i] = models.LinkURL{
+			OriginalURL:  fmt.Sprintf("https://example.com/%s/%d", prefix, i),
+			ShortLink:    fmt.Sprintf("%s%d", prefix, i),
+			ExpiresAt:    &expiresAt,
+			OwnerID:      "owner",
+			RedirectCode: 302,
+		}
+	}
+	return links
+}
+
+// insertBatchValues — прежняя вставка пакета запросами INSERT ... VALUES по 10 ссылок, оставлена для сравнения.
+func insertBatchValues(ctx context.Context, db *pgxpool.Pool, links []models.LinkURL) error {
+	const batchSize = 10
+
+	for batch := range slices.Chunk(links, batchSize) {
+		query := `INSERT INTO links (link, short_link, custom, expires_at, owner_id, redirect_code, password_hash, interstitial) VALUES %s
+			ON CONFLICT DO NOTHING`
+		placeholders := make([]string, 0, len(batch))
+		values := make([]interface{}, 0, len(batch)*8)
+
+		for j, link := range batch {
+			n := j * 8
+			placeholders = append(placeholders, fmt.Sprintf("($%d, $%d, $%d, $%d, NULLIF($%d, ''), $%d, NULLIF($%d, ''), $%d)",
+				n+1, n+2, n+3, n+4, n+5, n+6, n+7, n+8))
+			values = append(values, link.OriginalURL, link.ShortLink, link.Custom, link.ExpiresAt, link.OwnerID, link.RedirectCode,
+				link.PasswordHash, link.Interstitial)
+		}
+
+		if _, err := db.Exec(ctx, fmt.Sprintf(query, strings.Join(placeholders, ",")), values...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// BenchmarkInsertBatch сравнивает прежнюю вставку с unnest и COPY на пакетах разного размера:
+// go test -bench InsertBatch -benchtime 20x ./internal/repository/postgres
+func BenchmarkInsertBatch(b *testing.B) {
+	db := testPool(b)
+	repo := NewPostgresLinkRepository(db, nil)
+	ctx := context.Background()
+
+	paths := []struct {
+		name   string
+		insert func(links []models.LinkURL) error
+	}{
+		{"values", func(links []models.LinkURL) error { return insertBatchValues(ctx, db, links) }},
+		{"unnest", func(links []models.LinkURL) error { _, err := repo.insertBatch(ctx, links, false); return err }},
+		{"copy", func(links []models.LinkURL) error { _, err := repo.insertBatch(ctx, links, true); return err }},
+	}
+
+	for _, size := range []int{100, 1000, 10000} {
+		links := batchLinks("b", size)
+		for _, path := range paths {
+			b.Run(fmt.Sprintf("%s/%d", path.name, size), func(b *testing.B) {
+				for range b.N {
+					b.StopTimer()
+					truncateLinks(b, db)
+					b.StartTimer()
+
+					if err := path.insert(links); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"linkreduction/internal/models"
+)
+
+type Outbox struct {
+	db *pgxpool.Pool
+}
+
+func NewPostgresOutboxRepository(db *pgxpool.Pool) *Outbox {
+	return &Outbox{db: db}
+}
+
+// insertOutbox записывает сообщение в outbox в рамках транзакции изменения данных.
+func insertOutbox(ctx context.Context, tx pgx.Tx, topic, key string, msg any) error {
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("ошибка сериализации сообщения: %w", err)
+	}
+	_, err = tx.Exec(ctx, "INSERT INTO links_outbox (topic, key, payload) VALUES ($1, $2, $3)", topic, key, payload)
+	return err
+}
+
+// ProcessOutbox блокирует до limit самых старых сообщений, передаёт их publish и удаляет после
+// успешной отправки. Сообщения, заблокированные другим экземпляром, пропускаются; при ошибке
+// publish сообщения остаются в outbox до следующей попытки.
+func (r *Outbox) ProcessOutbox(ctx context.Context, limit int, publish func([]models.OutboxMessage) error) (int, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	rows, err := tx.Query(ctx, `SELECT id, topic, key, payload, created_at FROM links_outbox
+		ORDER BY id LIMIT $1 FOR UPDATE SKIP LOCKED`, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	var messages []models.OutboxMessage
+	ids := make([]int64, 0, limit)
+	for rows.Next() {
+		var msg models.OutboxMessage
+		if err := rows.Scan(&msg.ID, &msg.Topic, &msg.Key, &msg.Payload, &msg.CreatedAt); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		messages = append(messages, msg)
+		ids = append(ids, msg.ID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	if len(messages) == 0 {
+		return 0, nil
+	}
+
+	if err := publish(messages); err != nil {
+		return 0, err
+	}
+
+	if _, err := tx.Exec(ctx, "DELETE FROM links_outbox WHERE id = ANY($1)", ids); err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+	return len(messages), nil
+}
@@ -0,0 +1,124 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sirupsen/logrus"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultMaxReplicaLag        = 5 * time.Second
+	defaultReplicaCheckInterval = 5 * time.Second
+)
+
+// replicaLagQuery возвращает отставание реплики в секундах. Если реплика применила всё полученное,
+// отставание нулевое: время последней транзакции не растёт, пока на primary нет записей. Реплика без
+// потока WAL от primary возвращает NULL: иначе она считалась бы догнавшей, сколько бы ни отстала.
+// Статус приёмника WAL виден только ролям с правами pg_read_all_stats.
+const replicaLagQuery = `SELECT CASE WHEN NOT pg_is_in_recovery() THEN 0
+	WHEN NOT EXISTS (SELECT 1 FROM pg_stat_wal_receiver WHERE status = 'streaming') THEN NULL
+	WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+	ELSE COALESCE(EXTRACT(EPOCH FROM NOW() - pg_last_xact_replay_timestamp()), 0) END`
+
+type replica struct {
+	db      *pgxpool.Pool
+	healthy atomic.Bool
+	// checked меняется только в Check: первая неудачная проверка тоже журналируется
+	checked bool
+}
+
+// Replicas — реплики для чтения ссылок. Реплика, которая не отвечает или отстаёт от primary
+// больше maxLag, не используется до следующей успешной проверки.
+type Replicas struct {
+	replicas []*replica
+	maxLag   time.Duration
+	next     atomic.Uint64
+}
+
+// NewReplicas создаёт набор реплик. До первой проверки Check реплики не используются.
+func NewReplicas(dbs []*pgxpool.Pool, maxLag time.Duration) *Replicas {
+	if maxLag <= 0 {
+		maxLag = defaultMaxReplicaLag
+	}
+	replicas := make([]*replica, len(dbs))
+	for i, db := range dbs {
+		replicas[i] = &replica{db: db}
+	}
+	return &Replicas{replicas: replicas, maxLag: maxLag}
+}
+
+// pick выбирает доступную реплику по кругу; nil — читать с primary.
+func (r *Replicas) pick() *pgxpool.Pool {
+	if r == nil {
+		return nil
+	}
+	for range r.replicas {
+		candidate := r.replicas[(r.next.Add(1)-1)%uint64(len(r.replicas))]
+		if candidate.healthy.Load() {
+			return candidate.db
+		}
+	}
+	return nil
+}
+
+// Check измеряет отставание каждой реплики и отмечает, можно ли читать с неё.
+func (r *Replicas) Check(ctx context.Context, logger *logrus.Logger) {
+	for i, replica := range r.replicas {
+		err := r.checkLag(ctx, replica.db)
+		healthy := err == nil
+		if healthy != replica.healthy.Swap(healthy) || !replica.checked {
+			replica.checked = true
+			if healthy {
+				logger.WithField("replica", i).Info("Реплика Postgres используется для чтения")
+			} else {
+				logger.WithError(err).WithField("replica", i).Warn("Реплика Postgres исключена из чтения")
+			}
+		}
+	}
+}
+
+func (r *Replicas) checkLag(ctx context.Context, db *pgxpool.Pool) error {
+	ctx, cancel := context.WithTimeout(ctx, r.maxLag)
+	defer cancel()
+
+	var lag *float64
+	if err := db.QueryRow(ctx, replicaLagQuery).Scan(&lag); err != nil {
+		return err
+	}
+	if lag == nil {
+		return errors.New("реплика не получает WAL от primary")
+	}
+	if time.Duration(*lag*float64(time.Second)) > r.maxLag {
+		return errors.New("отставание реплики превышает допустимое")
+	}
+	return nil
+}
+
+// Watch проверяет реплики сразу и затем раз в interval до отмены ctx.
+func (r *Replicas) Watch(ctx context.Context, interval time.Duration, logger *logrus.Logger) {
+	if interval <= 0 {
+		interval = defaultReplicaCheckInterval
+	}
+	r.Check(ctx, logger)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			r.Check(ctx, logger)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (r *Replicas) Close() {
+	for _, replica := range r.replicas {
+		replica.db.Close()
+	}
+}
@@ -0,0 +1,42 @@
+package postgres
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReplicas_Pick(t *testing.T) {
+	var dbs []*pgxpool.Pool
+	for range 3 {
+		db, err := pgxpool.New(context.Background(), "host=replica sslmode=disable")
+		if !assert.NoError(t, err) {
+			return
+		}
+		dbs = append(dbs, db)
+	}
+	replicas := NewReplicas(dbs, 0)
+	defer replicas.Close()
+
+	t.Run("nil replicas read from primary", func(t *testing.T) {
+		var none *Replicas
+		assert.Nil(t, none.pick())
+	})
+
+	t.Run("unchecked replicas are not used", func(t *testing.T) {
+		assert.Nil(t, replicas.pick())
+	})
+
+	t.Run("healthy replicas are used in turn", func(t *testing.T) {
+		replicas.replicas[0].healthy.Store(true)
+		replicas.replicas[2].healthy.Store(true)
+
+		picked := map[*pgxpool.Pool]int{}
+		for range 4 {
+			picked[replicas.pick()]++
+		}
+		assert.Equal(t, map[*pgxpool.Pool]int{dbs[0]: 2, dbs[2]: 2}, picked)
+	})
+}
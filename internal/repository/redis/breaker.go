@@ -0,0 +1,103 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+	initprometheus "linkreduction/internal/prometheus"
+	"sync"
+	"time"
+)
+
+const (
+	defaultBreakerFailures = 5
+	defaultBreakerCooldown = 10 * time.Second
+)
+
+// ErrCircuitOpen возвращается без обращения к Redis, пока автомат защиты разомкнут.
+var ErrCircuitOpen = errors.New("Redis недоступен: автомат защиты разомкнут")
+
+// Breaker — автомат защиты для команд Redis. После failures ошибок подряд он размыкается,
+// и команды сразу завершаются ErrCircuitOpen, не дожидаясь таймаутов. Через cooldown
+// одна пробная команда проверяет, восстановилось ли соединение.
+type Breaker struct {
+	failures int
+	cooldown time.Duration
+	metrics  *initprometheus.PrometheusMetrics
+	logger   *logrus.Logger
+
+	mu       sync.Mutex
+	errors   int
+	open     bool
+	probing  bool
+	openedAt time.Time
+}
+
+func NewBreaker(failures int, cooldown time.Duration, metrics *initprometheus.PrometheusMetrics, logger *logrus.Logger) *Breaker {
+	if failures <= 0 {
+		failures = defaultBreakerFailures
+	}
+	if cooldown <= 0 {
+		cooldown = defaultBreakerCooldown
+	}
+	return &Breaker{failures: failures, cooldown: cooldown, metrics: metrics, logger: logger}
+}
+
+// Do выполняет команду fn, если автомат замкнут. Отсутствие ключа и отмена запроса клиентом
+// не считаются сбоем Redis. Nil Breaker выполняет fn без защиты.
+func (b *Breaker) Do(fn func() error) error {
+	if b == nil {
+		return fn()
+	}
+	if !b.allow() {
+		return ErrCircuitOpen
+	}
+
+	err := fn()
+	b.done(err == nil || errors.Is(err, redis.Nil) || errors.Is(err, context.Canceled), err)
+	return err
+}
+
+func (b *Breaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.open {
+		return true
+	}
+	if b.probing || time.Since(b.openedAt) < b.cooldown {
+		return false
+	}
+	b.probing = true
+	return true
+}
+
+func (b *Breaker) done(ok bool, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if ok {
+		if b.open {
+			b.logger.Info("Соединение с Redis восстановлено")
+		}
+		b.errors, b.open, b.probing = 0, false, false
+		b.setState(0)
+		return
+	}
+
+	b.errors++
+	if b.probing || (!b.open && b.errors >= b.failures) {
+		if !b.open {
+			b.logger.WithError(err).Warnf("Redis недоступен, обращения приостановлены на %s", b.cooldown)
+		}
+		b.open, b.probing, b.openedAt = true, false, time.Now()
+		b.setState(1)
+	}
+}
+
+func (b *Breaker) setState(open float64) {
+	if b.metrics != nil && b.metrics.RedisCircuitOpen != nil {
+		b.metrics.RedisCircuitOpen.Set(open)
+	}
+}
@@ -0,0 +1,67 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBreaker(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	down := fmt.Errorf("connection refused")
+
+	t.Run("opens after consecutive failures and skips commands", func(t *testing.T) {
+		b := NewBreaker(2, time.Hour, nil, logger)
+		calls := 0
+		fail := func() error { calls++; return down }
+
+		assert.ErrorIs(t, b.Do(fail), down)
+		assert.ErrorIs(t, b.Do(fail), down)
+		assert.ErrorIs(t, b.Do(fail), ErrCircuitOpen)
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("missing keys and cancelled requests are not failures", func(t *testing.T) {
+		b := NewBreaker(1, time.Hour, nil, logger)
+
+		assert.ErrorIs(t, b.Do(func() error { return redis.Nil }), redis.Nil)
+		assert.ErrorIs(t, b.Do(func() error { return context.Canceled }), context.Canceled)
+		assert.NoError(t, b.Do(func() error { return nil }))
+	})
+
+	t.Run("success resets the failure count", func(t *testing.T) {
+		b := NewBreaker(2, time.Hour, nil, logger)
+
+		_ = b.Do(func() error { return down })
+		_ = b.Do(func() error { return nil })
+		_ = b.Do(func() error { return down })
+		assert.NoError(t, b.Do(func() error { return nil }))
+	})
+
+	t.Run("probe after cooldown closes or reopens the circuit", func(t *testing.T) {
+		b := NewBreaker(1, 10*time.Millisecond, nil, logger)
+
+		_ = b.Do(func() error { return down })
+		assert.ErrorIs(t, b.Do(func() error { return nil }), ErrCircuitOpen)
+
+		time.Sleep(15 * time.Millisecond)
+		assert.ErrorIs(t, b.Do(func() error { return down }), down, "пробная команда выполняется")
+		assert.ErrorIs(t, b.Do(func() error { return nil }), ErrCircuitOpen, "неудачная проба снова размыкает автомат")
+
+		time.Sleep(15 * time.Millisecond)
+		assert.NoError(t, b.Do(func() error { return nil }))
+		assert.NoError(t, b.Do(func() error { return nil }))
+	})
+
+	t.Run("nil breaker runs commands unprotected", func(t *testing.T) {
+		var b *Breaker
+		assert.ErrorIs(t, b.Do(func() error { return down }), down)
+	})
+}
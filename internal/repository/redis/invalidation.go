@@ -0,0 +1,65 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+)
+
+// InvalidationChannel — канал pub/sub, через который экземпляры сервиса сбрасывают кэши процесса.
+const InvalidationChannel = "links:invalidate"
+
+type invalidation struct {
+	ShortLink   string `json:"short_link"`
+	OriginalURL string `json:"original_url"`
+}
+
+type Invalidations struct {
+	client redis.UniversalClient
+	logger *logrus.Logger
+}
+
+func NewInvalidations(client redis.UniversalClient, logger *logrus.Logger) *Invalidations {
+	return &Invalidations{client: client, logger: logger}
+}
+
+func (i *Invalidations) Publish(ctx context.Context, shortLink, originalURL string) error {
+	payload, err := json.Marshal(invalidation{ShortLink: shortLink, OriginalURL: originalURL})
+	if err != nil {
+		return err
+	}
+	return i.client.Publish(ctx, InvalidationChannel, payload).Err()
+}
+
+// Listen получает сообщения об инвалидации до отмены ctx и вызывает evict для каждой ссылки.
+// purge вызывается при каждой (пере)подписке: пока соединения не было, сообщения могли потеряться.
+func (i *Invalidations) Listen(ctx context.Context, evict func(shortLink, originalURL string), purge func()) {
+	pubsub := i.client.Subscribe(ctx, InvalidationChannel)
+	defer func() { _ = pubsub.Close() }()
+
+	messages := pubsub.ChannelWithSubscriptions()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-messages:
+			if !ok {
+				return
+			}
+			switch msg := msg.(type) {
+			case *redis.Subscription:
+				if msg.Kind == "subscribe" {
+					purge()
+				}
+			case *redis.Message:
+				var inv invalidation
+				if err := json.Unmarshal([]byte(msg.Payload), &inv); err != nil {
+					i.logger.WithError(err).Warn("Некорректное сообщение об инвалидации кэша")
+					continue
+				}
+				evict(inv.ShortLink, inv.OriginalURL)
+			}
+		}
+	}
+}
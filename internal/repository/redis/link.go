@@ -2,51 +2,158 @@ package redis
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"github.com/redis/go-redis/v9"
 	"github.com/sirupsen/logrus"
+	"linkreduction/internal/models"
+	"math"
+	"math/rand/v2"
 	"time"
 )
 
+// earlyRefreshDelta — оценка времени перечитывания ссылки из базы для досрочного обновления записей.
+const earlyRefreshDelta = 5 * time.Second
+
+// Link — кэш ссылок в Redis. Команды проходят через автомат защиты breaker: при недоступном
+// Redis ошибки возвращаются сразу, и сервис работает с базой напрямую.
 type Link struct {
-	client *redis.Client
-	logger *logrus.Logger
+	client  redis.UniversalClient
+	breaker *Breaker
+	logger  *logrus.Logger
+	random  func() float64
+}
+
+func NewLink(client redis.UniversalClient, breaker *Breaker, logger *logrus.Logger) *Link {
+	return &Link{client: client, breaker: breaker, logger: logger, random: rand.Float64}
 }
 
-func NewLink(client *redis.Client, logger *logrus.Logger) *Link {
-	return &Link{client: client, logger: logger}
+// refreshEarly решает, обновить ли запись до истечения ttl (XFetch): вероятность растёт по мере
+// приближения к истечению, поэтому при большом потоке запросов запись обновляет один из них,
+// а не все одновременно после её удаления. r — случайное число из [0, 1).
+func refreshEarly(ttl, delta time.Duration, r float64) bool {
+	if ttl <= 0 {
+		return false
+	}
+	return float64(ttl) <= -float64(delta)*math.Log(r)
 }
 
 func (c *Link) GetShortLink(ctx context.Context, originalURL string) (string, error) {
-	cacheKey := "shorten:" + originalURL
-	result, err := c.client.Get(ctx, cacheKey).Result()
-	if errors.Is(err, redis.Nil) || err != nil {
+	var result string
+	err := c.breaker.Do(func() (err error) {
+		result, err = c.client.Get(ctx, "shorten:"+originalURL).Result()
+		return err
+	})
+	if errors.Is(err, redis.Nil) {
 		return "", nil
 	}
-	return result, nil
+	return result, err
 }
 
 func (c *Link) SetShortLink(ctx context.Context, originalURL, shortLink string, ttl time.Duration) error {
-	cacheKey := "shorten:" + originalURL
-	if err := c.client.Set(ctx, cacheKey, shortLink, ttl).Err(); err != nil {
+	return c.write("set_short_link", func() error {
+		return c.client.Set(ctx, "shorten:"+originalURL, shortLink, ttl).Err()
+	})
+}
+
+func (c *Link) GetRedirect(ctx context.Context, shortLink string) (*models.Redirect, error) {
+	cacheKey := "redirect:" + shortLink
+	var get *redis.StringCmd
+	var ttl *redis.DurationCmd
+	err := c.breaker.Do(func() error {
+		pipe := c.client.Pipeline()
+		get = pipe.Get(ctx, cacheKey)
+		ttl = pipe.PTTL(ctx, cacheKey)
+		_, err := pipe.Exec(ctx)
 		return err
+	})
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
 	}
-	return nil
+	if err != nil {
+		return nil, err
+	}
+
+	// Записи старого формата (только URL) считаются промахом и перезаписываются
+	var redirect models.Redirect
+	if err := json.Unmarshal([]byte(get.Val()), &redirect); err != nil || (redirect.URL == "" && !redirect.NotFound) {
+		return nil, nil
+	}
+	// Один из запросов к популярной ссылке обновляет запись заранее, пока остальные ещё читают кэш
+	if refreshEarly(ttl.Val(), earlyRefreshDelta, c.random()) {
+		return nil, nil
+	}
+	return &redirect, nil
 }
 
-func (c *Link) GetOriginalURL(ctx context.Context, shortLink string) (string, error) {
-	cacheKey := "redirect:" + shortLink
-	result, err := c.client.Get(ctx, cacheKey).Result()
-	if errors.Is(err, redis.Nil) || err != nil {
-		return "", nil
+func (c *Link) SetRedirect(ctx context.Context, shortLink string, redirect models.Redirect, ttl time.Duration) error {
+	value, err := json.Marshal(redirect)
+	if err != nil {
+		return err
 	}
-	return result, nil
+	return c.write("set_redirect", func() error {
+		return c.client.Set(ctx, "redirect:"+shortLink, value, ttl).Err()
+	})
 }
 
-func (c *Link) SetOriginalURL(ctx context.Context, shortLink, originalURL string, ttl time.Duration) error {
-	cacheKey := "redirect:" + shortLink
-	if err := c.client.Set(ctx, cacheKey, originalURL, ttl).Err(); err != nil {
+func (c *Link) DeleteRedirects(ctx context.Context, shortLinks []string) error {
+	if len(shortLinks) == 0 {
+		return nil
+	}
+	keys := make([]string, len(shortLinks))
+	for i, shortLink := range shortLinks {
+		keys[i] = "redirect:" + shortLink
+	}
+	return c.write("delete_redirects", func() error {
+		return c.del(ctx, keys...)
+	})
+}
+
+func (c *Link) GetTitle(ctx context.Context, pageURL string) (string, bool, error) {
+	var result string
+	err := c.breaker.Do(func() (err error) {
+		result, err = c.client.Get(ctx, "title:"+pageURL).Result()
 		return err
+	})
+	if errors.Is(err, redis.Nil) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return result, true, nil
+}
+
+func (c *Link) SetTitle(ctx context.Context, pageURL, title string, ttl time.Duration) error {
+	return c.write("set_title", func() error {
+		return c.client.Set(ctx, "title:"+pageURL, title, ttl).Err()
+	})
+}
+
+func (c *Link) Invalidate(ctx context.Context, shortLink, originalURL string) error {
+	return c.write("invalidate", func() error {
+		return c.del(ctx, "redirect:"+shortLink, "shorten:"+originalURL)
+	})
+}
+
+// del удаляет ключи отдельными командами в одном конвейере: в Redis Cluster ключи лежат
+// в разных слотах, и DEL с несколькими ключами отклоняется с ошибкой CROSSSLOT.
+func (c *Link) del(ctx context.Context, keys ...string) error {
+	_, err := c.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, key := range keys {
+			pipe.Del(ctx, key)
+		}
+		return nil
+	})
+	return err
+}
+
+// write выполняет команду записи через автомат защиты. Сбои журналируются здесь, пока автомат
+// замкнут; после размыкания о недоступности Redis сообщает сам автомат.
+func (c *Link) write(op string, fn func() error) error {
+	err := c.breaker.Do(fn)
+	if err != nil && !errors.Is(err, ErrCircuitOpen) {
+		c.logger.WithError(err).WithField("op", op).Warn("Ошибка записи в кэш Redis")
 	}
-	return nil
+	return err
 }
@@ -0,0 +1,30 @@
+package redis
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRefreshEarly(t *testing.T) {
+	const delta = 5 * time.Second
+
+	assert.False(t, refreshEarly(-1, delta, 0.5), "запись без срока не обновляется досрочно")
+	assert.False(t, refreshEarly(time.Hour, delta, 0.5))
+	assert.True(t, refreshEarly(time.Second, delta, 0.5))
+	assert.True(t, refreshEarly(time.Hour, delta, 0), "вероятность обновления никогда не нулевая")
+
+	// Чем ближе истечение, тем чаще запись обновляется досрочно
+	share := func(ttl time.Duration) float64 {
+		n := 0
+		for i := 1; i < 1000; i++ {
+			if refreshEarly(ttl, delta, float64(i)/1000) {
+				n++
+			}
+		}
+		return float64(n) / 1000
+	}
+	assert.Less(t, share(30*time.Second), share(10*time.Second))
+	assert.Less(t, share(10*time.Second), share(time.Second))
+}
@@ -0,0 +1,62 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"math/rand/v2"
+	"time"
+)
+
+// slidingWindowScript хранит отметки запросов в отсортированном множестве и атомарно
+// удаляет устаревшие, проверяет лимит и добавляет новый запрос.
+// Возвращает {1, 0}, если запрос разрешён, и {0, мс до освобождения окна} иначе.
+var slidingWindowScript = redis.NewScript(`
+local key = KEYS[1]
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+
+redis.call('ZREMRANGEBYSCORE', key, '-inf', now - window)
+if redis.call('ZCARD', key) < limit then
+	redis.call('ZADD', key, now, ARGV[4])
+	redis.call('PEXPIRE', key, window)
+	return {1, 0}
+end
+
+local oldest = redis.call('ZRANGE', key, 0, 0, 'WITHSCORES')
+local retry = window
+if oldest[2] then
+	retry = tonumber(oldest[2]) + window - now
+end
+return {0, retry}
+`)
+
+type RateLimit struct {
+	client  redis.UniversalClient
+	breaker *Breaker
+}
+
+func NewRateLimit(client redis.UniversalClient, breaker *Breaker) *RateLimit {
+	return &RateLimit{client: client, breaker: breaker}
+}
+
+func (r *RateLimit) Allow(ctx context.Context, key string, limit int, window time.Duration) (bool, time.Duration, error) {
+	now := time.Now()
+	member := fmt.Sprintf("%d-%d", now.UnixNano(), rand.Uint32())
+
+	var res []int64
+	err := r.breaker.Do(func() (err error) {
+		res, err = slidingWindowScript.Run(ctx, r.client, []string{key},
+			now.UnixMilli(), window.Milliseconds(), limit, member).Int64Slice()
+		return err
+	})
+	if err != nil {
+		return false, 0, err
+	}
+	if len(res) != 2 {
+		return false, 0, fmt.Errorf("неожиданный ответ скрипта лимита: %v", res)
+	}
+
+	return res[0] == 1, time.Duration(res[1]) * time.Millisecond, nil
+}
@@ -0,0 +1,288 @@
+// Package repotest содержит общий набор проверок, который должна проходить каждая реализация
+// service.LinkRepo.
+package repotest
+
+import (
+	"context"
+	"linkreduction/internal/models"
+	"linkreduction/internal/service"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// LinkRepo проверяет реализацию service.LinkRepo. newRepo вызывается для каждого подтеста
+// и должен возвращать пустое хранилище.
+func LinkRepo(t *testing.T, newRepo func(t *testing.T) service.LinkRepo) {
+	ctx := context.Background()
+	past := time.Now().Add(-time.Hour).UTC()
+	future := time.Now().Add(time.Hour).UTC().Truncate(time.Millisecond)
+
+	t.Run("SaveLinks stores links with all fields", func(t *testing.T) {
+		repo := newRepo(t)
+
+		saved, statuses, err := repo.SaveLinks(ctx, []models.LinkURL{
+			{OriginalURL: "https://a.com", ShortLink: "a1", RedirectCode: 301},
+			{OriginalURL: "https://b.com", ShortLink: "b1", Custom: true, ExpiresAt: &future, OwnerID: "team",
+				RedirectCode: 302, PasswordHash: "hash", Interstitial: true},
+		}, false)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, []string{"a1", "b1"}, []string{saved[0].ShortLink, saved[1].ShortLink})
+		assert.Equal(t, []models.InsertStatus{models.InsertInserted, models.InsertInserted}, statuses)
+
+		link, err := repo.FindLink(ctx, "b1")
+		if !assert.NoError(t, err) || !assert.NotNil(t, link) {
+			return
+		}
+		assert.Equal(t, "https://b.com", link.OriginalURL)
+		assert.True(t, link.Custom)
+		assert.Equal(t, "team", link.OwnerID)
+		assert.Equal(t, 302, link.RedirectCode)
+		assert.Equal(t, "hash", link.PasswordHash)
+		assert.True(t, link.Interstitial)
+		if assert.NotNil(t, link.ExpiresAt) {
+			assert.WithinDuration(t, future, *link.ExpiresAt, time.Millisecond)
+		}
+		assert.False(t, link.CreatedAt.IsZero())
+
+		link, err = repo.FindLink(ctx, "a1")
+		if assert.NoError(t, err) && assert.NotNil(t, link) {
+			assert.Nil(t, link.ExpiresAt)
+			assert.Empty(t, link.OwnerID)
+			assert.Empty(t, link.PasswordHash)
+		}
+
+		originalURL, err := repo.FindByShortLink(ctx, "a1")
+		assert.NoError(t, err)
+		assert.Equal(t, "https://a.com", originalURL)
+
+		shared, err := repo.FindByOriginalURL(ctx, "https://a.com")
+		if assert.NoError(t, err) && assert.NotNil(t, shared) {
+			assert.Equal(t, "a1", shared.ShortLink)
+		}
+
+		shared, err = repo.FindByOriginalURL(ctx, "https://b.com")
+		assert.NoError(t, err)
+		assert.Nil(t, shared, "custom-ссылка не должна находиться по URL")
+	})
+
+	t.Run("FindByOriginalURL returns the stored expiry", func(t *testing.T) {
+		repo := newRepo(t)
+
+		_, _, err := repo.SaveLinks(ctx, []models.LinkURL{{OriginalURL: "https://a.com", ShortLink: "a1", ExpiresAt: &future, RedirectCode: 301}}, false)
+		assert.NoError(t, err)
+
+		shared, err := repo.FindByOriginalURL(ctx, "https://a.com")
+		if assert.NoError(t, err) && assert.NotNil(t, shared) && assert.NotNil(t, shared.ExpiresAt) {
+			assert.Equal(t, "a1", shared.ShortLink)
+			assert.True(t, future.Equal(*shared.ExpiresAt))
+		}
+	})
+
+	t.Run("missing links", func(t *testing.T) {
+		repo := newRepo(t)
+
+		link, err := repo.FindLink(ctx, "nope")
+		assert.NoError(t, err)
+		assert.Nil(t, link)
+
+		originalURL, err := repo.FindByShortLink(ctx, "nope")
+		assert.NoError(t, err)
+		assert.Empty(t, originalURL)
+
+		shared, err := repo.FindByOriginalURL(ctx, "https://nope.com")
+		assert.NoError(t, err)
+		assert.Nil(t, shared)
+	})
+
+	t.Run("SaveLinks returns the existing shared link", func(t *testing.T) {
+		repo := newRepo(t)
+
+		_, _, err := repo.SaveLinks(ctx, []models.LinkURL{{OriginalURL: "https://a.com", ShortLink: "a1", RedirectCode: 301}}, false)
+		assert.NoError(t, err)
+
+		saved, statuses, err := repo.SaveLinks(ctx, []models.LinkURL{{OriginalURL: "https://a.com", ShortLink: "a2", RedirectCode: 301}}, false)
+		if assert.NoError(t, err) {
+			assert.Equal(t, "a1", saved[0].ShortLink)
+			assert.Equal(t, []models.InsertStatus{models.InsertExisting}, statuses)
+		}
+
+		originalURL, err := repo.FindByShortLink(ctx, "a2")
+		assert.NoError(t, err)
+		assert.Empty(t, originalURL)
+	})
+
+	t.Run("SaveLinks replaces an expired shared link", func(t *testing.T) {
+		repo := newRepo(t)
+
+		_, err := repo.InsertBatch(ctx, []models.LinkURL{{OriginalURL: "https://a.com", ShortLink: "old", ExpiresAt: &past, RedirectCode: 301}})
+		assert.NoError(t, err)
+
+		shared, err := repo.FindByOriginalURL(ctx, "https://a.com")
+		assert.NoError(t, err)
+		assert.Nil(t, shared, "истёкшая ссылка не должна переиспользоваться")
+
+		saved, _, err := repo.SaveLinks(ctx, []models.LinkURL{{OriginalURL: "https://a.com", ShortLink: "new", RedirectCode: 301}}, false)
+		if assert.NoError(t, err) {
+			assert.Equal(t, "new", saved[0].ShortLink)
+		}
+
+		shared, err = repo.FindByOriginalURL(ctx, "https://a.com")
+		if assert.NoError(t, err) && assert.NotNil(t, shared) {
+			assert.Equal(t, "new", shared.ShortLink)
+		}
+
+		link, err := repo.FindLink(ctx, "old")
+		assert.NoError(t, err)
+		assert.Nil(t, link)
+	})
+
+	t.Run("SaveLinks rejects only the link with a taken key", func(t *testing.T) {
+		repo := newRepo(t)
+
+		_, err := repo.InsertBatch(ctx, []models.LinkURL{
+			{OriginalURL: "https://a.com", ShortLink: "taken", Custom: true, RedirectCode: 301},
+			{OriginalURL: "https://d.com", ShortLink: "old", ExpiresAt: &past, RedirectCode: 301},
+		})
+		assert.NoError(t, err)
+
+		saved, statuses, err := repo.SaveLinks(ctx, []models.LinkURL{
+			{OriginalURL: "https://b.com", ShortLink: "b1", RedirectCode: 301},
+			{OriginalURL: "https://c.com", ShortLink: "taken", Custom: true, RedirectCode: 301},
+			{OriginalURL: "https://d.com", ShortLink: "taken", RedirectCode: 301},
+			{OriginalURL: "https://e.com", ShortLink: "e1", Custom: true, RedirectCode: 301},
+		}, false)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, []models.InsertStatus{models.InsertInserted, models.InsertConflict, models.InsertConflict, models.InsertInserted},
+			statuses)
+		assert.Equal(t, "taken", saved[1].ShortLink)
+
+		for _, shortLink := range []string{"b1", "e1"} {
+			link, err := repo.FindLink(ctx, shortLink)
+			assert.NoError(t, err)
+			assert.NotNil(t, link, "ссылка %s должна сохраниться несмотря на конфликт другой ссылки", shortLink)
+		}
+
+		originalURL, err := repo.FindByShortLink(ctx, "taken")
+		assert.NoError(t, err)
+		assert.Equal(t, "https://a.com", originalURL)
+
+		link, err := repo.FindLink(ctx, "old")
+		assert.NoError(t, err)
+		assert.NotNil(t, link, "истёкшая общая ссылка заменяется, только если новая сохранена")
+	})
+
+	t.Run("InsertBatch is idempotent", func(t *testing.T) {
+		repo := newRepo(t)
+		links := []models.LinkURL{
+			{OriginalURL: "https://a.com", ShortLink: "a1", RedirectCode: 301},
+			{OriginalURL: "https://b.com", ShortLink: "b1", Custom: true, RedirectCode: 302},
+		}
+
+		statuses, err := repo.InsertBatch(ctx, links)
+		assert.NoError(t, err)
+		assert.Equal(t, []models.InsertStatus{models.InsertInserted, models.InsertInserted}, statuses)
+
+		statuses, err = repo.InsertBatch(ctx, links)
+		assert.NoError(t, err)
+		assert.Equal(t, []models.InsertStatus{models.InsertExisting, models.InsertExisting}, statuses)
+
+		statuses, err = repo.InsertBatch(ctx, []models.LinkURL{
+			{OriginalURL: "https://other.com", ShortLink: "a1", RedirectCode: 301},
+			{OriginalURL: "https://a.com", ShortLink: "a2", RedirectCode: 301},
+			{OriginalURL: "https://c.com", ShortLink: "c1", RedirectCode: 301},
+			{OriginalURL: "https://c.com", ShortLink: "c1", RedirectCode: 301},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []models.InsertStatus{models.InsertConflict, models.InsertConflict, models.InsertInserted, models.InsertExisting},
+			statuses)
+
+		statuses, err = repo.InsertBatch(ctx, nil)
+		assert.NoError(t, err)
+		assert.Empty(t, statuses)
+
+		originalURL, err := repo.FindByShortLink(ctx, "a1")
+		assert.NoError(t, err)
+		assert.Equal(t, "https://a.com", originalURL)
+
+		link, err := repo.FindLink(ctx, "a2")
+		assert.NoError(t, err)
+		assert.Nil(t, link)
+	})
+
+	t.Run("UpdateOriginalURL makes the link custom", func(t *testing.T) {
+		repo := newRepo(t)
+
+		_, _, err := repo.SaveLinks(ctx, []models.LinkURL{{OriginalURL: "https://a.com", ShortLink: "a1", RedirectCode: 301}}, false)
+		assert.NoError(t, err)
+		assert.NoError(t, repo.UpdateOriginalURL(ctx, "a1", "https://b.com"))
+
+		link, err := repo.FindLink(ctx, "a1")
+		if assert.NoError(t, err) && assert.NotNil(t, link) {
+			assert.Equal(t, "https://b.com", link.OriginalURL)
+			assert.True(t, link.Custom)
+		}
+
+		shared, err := repo.FindByOriginalURL(ctx, "https://a.com")
+		assert.NoError(t, err)
+		assert.Nil(t, shared)
+
+		saved, _, err := repo.SaveLinks(ctx, []models.LinkURL{{OriginalURL: "https://a.com", ShortLink: "a2", RedirectCode: 301}}, false)
+		if assert.NoError(t, err) {
+			assert.Equal(t, "a2", saved[0].ShortLink)
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		repo := newRepo(t)
+
+		_, _, err := repo.SaveLinks(ctx, []models.LinkURL{{OriginalURL: "https://a.com", ShortLink: "a1", RedirectCode: 301}}, false)
+		assert.NoError(t, err)
+		assert.NoError(t, repo.Delete(ctx, "a1"))
+		assert.NoError(t, repo.Delete(ctx, "a1"))
+
+		link, err := repo.FindLink(ctx, "a1")
+		assert.NoError(t, err)
+		assert.Nil(t, link)
+
+		shared, err := repo.FindByOriginalURL(ctx, "https://a.com")
+		assert.NoError(t, err)
+		assert.Nil(t, shared)
+	})
+
+	t.Run("DeleteExpiredLinks removes only expired links", func(t *testing.T) {
+		repo := newRepo(t)
+
+		_, err := repo.InsertBatch(ctx, []models.LinkURL{
+			{OriginalURL: "https://a.com", ShortLink: "expired", ExpiresAt: &past, RedirectCode: 301},
+			{OriginalURL: "https://b.com", ShortLink: "live", ExpiresAt: &future, RedirectCode: 301},
+			{OriginalURL: "https://c.com", ShortLink: "forever", Custom: true, RedirectCode: 301},
+		})
+		assert.NoError(t, err)
+		assert.NoError(t, repo.DeleteExpiredLinks(ctx))
+
+		for shortLink, exists := range map[string]bool{"expired": false, "live": true, "forever": true} {
+			link, err := repo.FindLink(ctx, shortLink)
+			assert.NoError(t, err)
+			assert.Equal(t, exists, link != nil, shortLink)
+		}
+	})
+
+	t.Run("NextKeyID is monotonic", func(t *testing.T) {
+		sequence, ok := newRepo(t).(service.KeySequence)
+		if !ok {
+			t.Skip("хранилище не поддерживает последовательность ключей")
+		}
+
+		first, err := sequence.NextKeyID(ctx)
+		assert.NoError(t, err)
+		second, err := sequence.NextKeyID(ctx)
+		assert.NoError(t, err)
+		assert.Greater(t, second, first)
+	})
+}
@@ -0,0 +1,257 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/mattn/go-sqlite3"
+	"linkreduction/internal/models"
+	"strings"
+	"time"
+)
+
+// schema повторяет таблицу links из миграций Postgres. Время хранится в наносекундах Unix,
+// чтобы сравнение сроков действия не зависело от формата строк.
+const schema = `
+CREATE TABLE IF NOT EXISTS links
+(
+    id            INTEGER PRIMARY KEY AUTOINCREMENT,
+    link          TEXT    NOT NULL,
+    short_link    TEXT    NOT NULL UNIQUE,
+    custom        BOOLEAN NOT NULL DEFAULT FALSE,
+    expires_at    INTEGER,
+    created_at    INTEGER NOT NULL,
+    owner_id      TEXT,
+    redirect_code INTEGER NOT NULL DEFAULT 301,
+    password_hash TEXT,
+    interstitial  BOOLEAN NOT NULL DEFAULT FALSE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS links_link_generated_key ON links (link) WHERE NOT custom;
+
+CREATE TABLE IF NOT EXISTS links_key_seq
+(
+    id INTEGER PRIMARY KEY AUTOINCREMENT
+);`
+
+type Link struct {
+	db *sql.DB
+}
+
+// Open открывает базу SQLite по пути path и создаёт таблицы; ":memory:" — база в памяти процесса.
+// Запись в SQLite однопоточная, поэтому пул ограничен одним соединением.
+func Open(path string) (*sql.DB, error) {
+	db, err := sql.Open("sqlite3", fmt.Sprintf("file:%s?_busy_timeout=5000&_foreign_keys=on", path))
+	if err != nil {
+		return nil, fmt.Errorf("ошибка открытия базы SQLite: %w", err)
+	}
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(schema); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("ошибка создания схемы SQLite: %w", err)
+	}
+	return db, nil
+}
+
+func NewSQLiteLinkRepository(db *sql.DB) *Link {
+	return &Link{db: db}
+}
+
+func (r *Link) FindByOriginalURL(ctx context.Context, originalURL string) (*models.LinkURL, error) {
+	link := models.LinkURL{OriginalURL: originalURL}
+	var expiresAt sql.NullInt64
+	err := r.db.QueryRowContext(ctx, "SELECT short_link, expires_at FROM links WHERE link = ? AND NOT custom AND (expires_at IS NULL OR expires_at > ?)",
+		originalURL, now()).Scan(&link.ShortLink, &expiresAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	link.ExpiresAt = fromNullUnix(expiresAt)
+	return &link, nil
+}
+
+func (r *Link) FindByShortLink(ctx context.Context, shortLink string) (string, error) {
+	var originalURL string
+	err := r.db.QueryRowContext(ctx, "SELECT link FROM links WHERE short_link = ?", shortLink).Scan(&originalURL)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	return originalURL, err
+}
+
+func (r *Link) FindLink(ctx context.Context, shortLink string) (*models.LinkURL, error) {
+	var link models.LinkURL
+	var expiresAt sql.NullInt64
+	var createdAt int64
+	err := r.db.QueryRowContext(ctx, `SELECT link, short_link, custom, expires_at, created_at, COALESCE(owner_id, ''), redirect_code,
+		COALESCE(password_hash, ''), interstitial FROM links WHERE short_link = ?`, shortLink).
+		Scan(&link.OriginalURL, &link.ShortLink, &link.Custom, &expiresAt, &createdAt, &link.OwnerID, &link.RedirectCode,
+			&link.PasswordHash, &link.Interstitial)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	link.CreatedAt = time.Unix(0, createdAt).UTC()
+	link.ExpiresAt = fromNullUnix(expiresAt)
+	return &link, nil
+}
+
+// onConflictGenerated заменяет общую ссылку на тот же URL, только если её срок действия уже истёк.
+const onConflictGenerated = `ON CONFLICT (link) WHERE NOT custom DO UPDATE
+	SET short_link = excluded.short_link, expires_at = excluded.expires_at, redirect_code = excluded.redirect_code,
+		created_at = excluded.created_at
+	WHERE links.expires_at IS NOT NULL AND links.expires_at <= excluded.created_at`
+
+const insertLink = `INSERT INTO links (link, short_link, custom, expires_at, created_at, owner_id, redirect_code, password_hash, interstitial)
+	VALUES (?, ?, ?, ?, ?, NULLIF(?, ''), ?, NULLIF(?, ''), ?) `
+
+// SaveLinks сохраняет ссылки в одной транзакции. Если общая ссылка на тот же URL уже существует,
+// возвращается она со статусом InsertExisting. Ссылка с занятым ключом получает InsertConflict:
+// SQLite откатывает только нарушивший ограничение запрос, остальные ссылки сохраняются.
+// Outbox не поддерживается: сообщения о ссылках в Kafka не отправляются.
+func (r *Link) SaveLinks(ctx context.Context, links []models.LinkURL, _ bool) ([]models.LinkURL, []models.InsertStatus, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback()
+
+	createdAt := now()
+	saved := make([]models.LinkURL, len(links))
+	statuses := make([]models.InsertStatus, len(links))
+	for i, link := range links {
+		statuses[i] = models.InsertInserted
+		err := tx.QueryRowContext(ctx, insertLink+onConflictGenerated+" RETURNING short_link",
+			link.OriginalURL, link.ShortLink, link.Custom, toNullUnix(link.ExpiresAt), createdAt, link.OwnerID, link.RedirectCode,
+			link.PasswordHash, link.Interstitial).Scan(&link.ShortLink)
+		if errors.Is(err, sql.ErrNoRows) {
+			// Действующая общая ссылка уже создана параллельным запросом
+			var expiresAt sql.NullInt64
+			if err := tx.QueryRowContext(ctx, "SELECT short_link, expires_at FROM links WHERE link = ? AND NOT custom",
+				link.OriginalURL).Scan(&link.ShortLink, &expiresAt); err != nil {
+				return nil, nil, fmt.Errorf("ошибка чтения существующей ссылки: %w", err)
+			}
+			link.ExpiresAt = fromNullUnix(expiresAt)
+			statuses[i] = models.InsertExisting
+		} else if isShortLinkTaken(err) {
+			statuses[i] = models.InsertConflict
+		} else if err != nil {
+			return nil, nil, err
+		}
+		saved[i] = link
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, nil, err
+	}
+	return saved, statuses, nil
+}
+
+// isShortLinkTaken сообщает, что вставка нарушила уникальность ключа.
+func isShortLinkTaken(err error) bool {
+	var sqliteErr sqlite3.Error
+	return errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique &&
+		strings.Contains(sqliteErr.Error(), "links.short_link")
+}
+
+// InsertBatch идемпотентно сохраняет ссылки в одной транзакции: уже сохранённые ссылки пропускаются.
+func (r *Link) InsertBatch(ctx context.Context, links []models.LinkURL) ([]models.InsertStatus, error) {
+	if len(links) == 0 {
+		return nil, nil
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	createdAt := now()
+	statuses := make([]models.InsertStatus, len(links))
+	for i, link := range links {
+		res, err := tx.ExecContext(ctx, insertLink+"ON CONFLICT DO NOTHING",
+			link.OriginalURL, link.ShortLink, link.Custom, toNullUnix(link.ExpiresAt), createdAt, link.OwnerID, link.RedirectCode,
+			link.PasswordHash, link.Interstitial)
+		if err != nil {
+			return nil, err
+		}
+		if n, err := res.RowsAffected(); err != nil {
+			return nil, err
+		} else if n > 0 {
+			statuses[i] = models.InsertInserted
+			continue
+		}
+
+		var stored string
+		err = tx.QueryRowContext(ctx, "SELECT link FROM links WHERE short_link = ?", link.ShortLink).Scan(&stored)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+		statuses[i] = models.InsertConflict
+		if stored == link.OriginalURL {
+			statuses[i] = models.InsertExisting
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return statuses, nil
+}
+
+// UpdateOriginalURL меняет адрес назначения ссылки; перенаправленная ссылка становится custom.
+func (r *Link) UpdateOriginalURL(ctx context.Context, shortLink, originalURL string) error {
+	_, err := r.db.ExecContext(ctx, "UPDATE links SET link = ?, custom = TRUE WHERE short_link = ?", originalURL, shortLink)
+	return err
+}
+
+func (r *Link) Delete(ctx context.Context, shortLink string) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM links WHERE short_link = ?", shortLink)
+	return err
+}
+
+func (r *Link) DeleteExpiredLinks(ctx context.Context) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM links WHERE expires_at IS NOT NULL AND expires_at <= ?", now())
+	return err
+}
+
+func (r *Link) NextKeyID(ctx context.Context) (int64, error) {
+	res, err := r.db.ExecContext(ctx, "INSERT INTO links_key_seq DEFAULT VALUES")
+	if err != nil {
+		return 0, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	// AUTOINCREMENT не выдаёт номера повторно, поэтому хранить достаточно последний
+	if _, err := r.db.ExecContext(ctx, "DELETE FROM links_key_seq WHERE id < ?", id); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+func now() int64 {
+	return time.Now().UnixNano()
+}
+
+func toNullUnix(t *time.Time) sql.NullInt64 {
+	if t == nil {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: t.UnixNano(), Valid: true}
+}
+
+func fromNullUnix(v sql.NullInt64) *time.Time {
+	if !v.Valid {
+		return nil
+	}
+	t := time.Unix(0, v.Int64).UTC()
+	return &t
+}
@@ -0,0 +1,19 @@
+package sqlite
+
+import (
+	"linkreduction/internal/repository/repotest"
+	"linkreduction/internal/service"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLink_Conformance(t *testing.T) {
+	repotest.LinkRepo(t, func(t *testing.T) service.LinkRepo {
+		db, err := Open(filepath.Join(t.TempDir(), "links.db"))
+		assert.NoError(t, err)
+		t.Cleanup(func() { _ = db.Close() })
+		return NewSQLiteLinkRepository(db)
+	})
+}
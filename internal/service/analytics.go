@@ -0,0 +1,192 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/IBM/sarama"
+	"github.com/sirupsen/logrus"
+	"linkreduction/internal/const"
+	"linkreduction/internal/models"
+	initprometheus "linkreduction/internal/prometheus"
+	"strings"
+	"time"
+)
+
+const (
+	clickQueueSize     = 1024
+	clickBatchSize     = 100
+	clickFlushInterval = 5 * time.Second
+
+	defaultStatsDays   = 30
+	maxStatsDays       = 365
+	statsTopReferrers  = 10
+	maxReferrerLength  = 1024
+	maxUserAgentLength = 512
+)
+
+// Analytics собирает переходы по коротким ссылкам и строит по ним статистику.
+// Переходы записываются асинхронно: через Kafka, если она доступна, иначе напрямую в базу.
+type Analytics struct {
+	ctx      context.Context
+	repo     ClickRepo
+	producer sarama.SyncProducer
+	metrics  *initprometheus.PrometheusMetrics
+	ipSalt   string
+	clicks   chan models.Click
+}
+
+func NewAnalytics(ctx context.Context, repo ClickRepo, producer sarama.SyncProducer, metrics *initprometheus.PrometheusMetrics, ipSalt string) *Analytics {
+	return &Analytics{
+		ctx:      ctx,
+		repo:     repo,
+		producer: producer,
+		metrics:  metrics,
+		ipSalt:   ipSalt,
+		clicks:   make(chan models.Click, clickQueueSize),
+	}
+}
+
+// RecordClick ставит переход в очередь на запись и не блокирует обработку редиректа.
+// При переполнении очереди событие отбрасывается.
+func (a *Analytics) RecordClick(shortLink, referrer, userAgent, clientIP string) {
+	click := models.Click{
+		ShortLink: shortLink,
+		ClickedAt: time.Now().UTC(),
+		Referrer:  truncate(referrer, maxReferrerLength),
+		UserAgent: truncate(userAgent, maxUserAgentLength),
+		IPHash:    a.hashIP(clientIP),
+	}
+
+	select {
+	case a.clicks <- click:
+	default:
+		a.countClick("dropped")
+	}
+}
+
+// Run отправляет накопленные переходы до отмены контекста.
+func (a *Analytics) Run(logger *logrus.Logger) {
+	ticker := time.NewTicker(clickFlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]models.Click, 0, clickBatchSize)
+	flush := func(ctx context.Context) {
+		if len(batch) == 0 {
+			return
+		}
+		if err := a.publish(ctx, batch); err != nil {
+			logger.WithFields(logrus.Fields{
+				"batch_size": len(batch),
+			}).Error("Ошибка записи переходов: ", err)
+			a.countClick("error")
+		} else {
+			a.countClick("success")
+		}
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case click := <-a.clicks:
+			batch = append(batch, click)
+			if len(batch) >= clickBatchSize {
+				flush(a.ctx)
+			}
+		case <-ticker.C:
+			flush(a.ctx)
+		case <-a.ctx.Done():
+			// Дописываем то, что успело попасть в очередь до остановки
+			for {
+				select {
+				case click := <-a.clicks:
+					batch = append(batch, click)
+				default:
+					flush(context.Background())
+					return
+				}
+			}
+		}
+	}
+}
+
+func (a *Analytics) publish(ctx context.Context, batch []models.Click) error {
+	if a.producer == nil {
+		return a.repo.InsertClicks(ctx, batch)
+	}
+
+	msgs := make([]*sarama.ProducerMessage, 0, len(batch))
+	for _, click := range batch {
+		messageBytes, err := json.Marshal(message.ClickMessage{
+			ShortLink: click.ShortLink,
+			ClickedAt: click.ClickedAt,
+			Referrer:  click.Referrer,
+			UserAgent: click.UserAgent,
+			IPHash:    click.IPHash,
+		})
+		if err != nil {
+			return fmt.Errorf("ошибка сериализации перехода: %w", err)
+		}
+		msgs = append(msgs, &sarama.ProducerMessage{
+			Topic: message.ClicksTopic,
+			Key:   sarama.StringEncoder(click.ShortLink),
+			Value: sarama.ByteEncoder(messageBytes),
+		})
+	}
+
+	if err := a.producer.SendMessages(msgs); err != nil {
+		return fmt.Errorf("kafka send error: %w", err)
+	}
+	return nil
+}
+
+// InsertClicks сохраняет пачку переходов, полученную из Kafka.
+func (a *Analytics) InsertClicks(ctx context.Context, batch []models.Click) error {
+	if len(batch) == 0 {
+		return nil
+	}
+	if err := a.repo.InsertClicks(ctx, batch); err != nil {
+		return fmt.Errorf("ошибка сохранения переходов: %w", err)
+	}
+	return nil
+}
+
+// Stats возвращает статистику переходов по ссылке за последние days дней; переходы по прежней
+// ссылке с тем же ключом, созданной до link, не учитываются.
+func (a *Analytics) Stats(ctx context.Context, link *models.LinkURL, days int) (*models.LinkStats, error) {
+	if days <= 0 {
+		days = defaultStatsDays
+	}
+	days = min(days, maxStatsDays)
+
+	since := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -(days - 1))
+
+	stats, err := a.repo.GetStats(ctx, link.ShortLink, link.CreatedAt, since, statsTopReferrers)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения статистики: %w", err)
+	}
+	return stats, nil
+}
+
+func (a *Analytics) hashIP(ip string) string {
+	if ip == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(a.ipSalt + ip))
+	return hex.EncodeToString(sum[:])
+}
+
+func (a *Analytics) countClick(status string) {
+	if a.metrics != nil && a.metrics.ClickEventsTotal != nil {
+		a.metrics.ClickEventsTotal.WithLabelValues(status).Inc()
+	}
+}
+
+func truncate(value string, limit int) string {
+	if len(value) > limit {
+		return strings.ToValidUTF8(value[:limit], "")
+	}
+	return value
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"linkreduction/internal/mocks"
+	"linkreduction/internal/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAnalytics_RecordClick(t *testing.T) {
+	analytics := NewAnalytics(context.Background(), new(mocks.ClickRepo), nil, nil, "salt")
+
+	analytics.RecordClick("abc123", "https://ref.com", "curl/8.0", "10.0.0.1")
+
+	click := <-analytics.clicks
+	assert.Equal(t, "abc123", click.ShortLink)
+	assert.Equal(t, "https://ref.com", click.Referrer)
+	assert.Equal(t, "curl/8.0", click.UserAgent)
+	assert.Len(t, click.IPHash, 64)
+	assert.NotContains(t, click.IPHash, "10.0.0.1")
+	assert.Equal(t, analytics.hashIP("10.0.0.1"), click.IPHash)
+	assert.NotEqual(t, NewAnalytics(context.Background(), nil, nil, nil, "other").hashIP("10.0.0.1"), click.IPHash)
+}
+
+func TestAnalytics_RecordClickDropsWhenQueueIsFull(t *testing.T) {
+	analytics := NewAnalytics(context.Background(), new(mocks.ClickRepo), nil, nil, "")
+
+	for i := 0; i < clickQueueSize+10; i++ {
+		analytics.RecordClick("abc123", "", "", "")
+	}
+
+	assert.Len(t, analytics.clicks, clickQueueSize)
+}
+
+func TestAnalytics_RunWritesToRepoWithoutKafka(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	repo := new(mocks.ClickRepo)
+	analytics := NewAnalytics(ctx, repo, nil, nil, "")
+
+	done := make(chan struct{})
+	repo.On("InsertClicks", mock.Anything, mock.MatchedBy(func(clicks []models.Click) bool {
+		return len(clicks) == 1 && clicks[0].ShortLink == "abc123"
+	})).Return(nil).Run(func(mock.Arguments) { close(done) })
+
+	go analytics.Run(nil)
+	analytics.RecordClick("abc123", "", "", "")
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("переход не был записан")
+	}
+	repo.AssertExpectations(t)
+}
+
+func TestAnalytics_Stats(t *testing.T) {
+	tests := []struct {
+		name        string
+		days        int
+		expectDays  int
+		repoErr     error
+		expectError bool
+	}{
+		{name: "default period", days: 0, expectDays: defaultStatsDays},
+		{name: "custom period", days: 7, expectDays: 7},
+		{name: "period is clamped", days: 10000, expectDays: maxStatsDays},
+		{name: "repo error", days: 7, expectDays: 7, repoErr: fmt.Errorf("db error"), expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(mocks.ClickRepo)
+			analytics := NewAnalytics(context.Background(), repo, nil, nil, "")
+
+			since := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -(tt.expectDays - 1))
+			var stats *models.LinkStats
+			if tt.repoErr == nil {
+				stats = &models.LinkStats{ShortLink: "abc123", TotalClicks: 3}
+			}
+			link := &models.LinkURL{ShortLink: "abc123", CreatedAt: time.Now().Add(-time.Hour)}
+			repo.On("GetStats", mock.Anything, "abc123", link.CreatedAt, since, statsTopReferrers).Return(stats, tt.repoErr)
+
+			result, err := analytics.Stats(context.Background(), link, tt.days)
+			if tt.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, stats, result)
+			}
+			repo.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"linkreduction/internal/models"
+	"strings"
+	"time"
+)
+
+const (
+	apiKeyPrefix      = "lr_"
+	apiKeyRandomBytes = 32
+	maxOwnerIDLength  = 64
+)
+
+// ErrUnauthorized возвращается для отсутствующего, неизвестного или отозванного API-ключа.
+var ErrUnauthorized = &Error{Code: "unauthorized", Message: "неверный или отозванный API-ключ"}
+
+// ErrAPIKeyNotFound возвращается при отзыве несуществующего или уже отозванного ключа.
+var ErrAPIKeyNotFound = newError(ErrNotFound, "api_key_not_found", "API-ключ не найден или уже отозван")
+
+// APIKeys выпускает и проверяет API-ключи. В базе хранится только SHA-256 от ключа,
+// сам ключ показывается один раз при создании.
+type APIKeys struct {
+	repo APIKeyRepo
+}
+
+func NewAPIKeys(repo APIKeyRepo) *APIKeys {
+	return &APIKeys{repo: repo}
+}
+
+// Create выпускает новый ключ владельца и возвращает его в открытом виде.
+func (a *APIKeys) Create(ctx context.Context, ownerID, name string) (string, *models.APIKey, error) {
+	ownerID = strings.TrimSpace(ownerID)
+	if ownerID == "" || len(ownerID) > maxOwnerIDLength {
+		return "", nil, wrapf(ErrInvalidInput, "идентификатор владельца должен содержать от 1 до %d символов", maxOwnerIDLength)
+	}
+
+	random := make([]byte, apiKeyRandomBytes)
+	if _, err := rand.Read(random); err != nil {
+		return "", nil, fmt.Errorf("ошибка генерации API-ключа: %w", err)
+	}
+	plain := apiKeyPrefix + hex.EncodeToString(random)
+
+	key := &models.APIKey{
+		OwnerID:   ownerID,
+		Name:      name,
+		KeyHash:   hashAPIKey(plain),
+		CreatedAt: time.Now().UTC(),
+	}
+	id, err := a.repo.CreateAPIKey(ctx, *key)
+	if err != nil {
+		return "", nil, fmt.Errorf("ошибка сохранения API-ключа: %w", err)
+	}
+	key.ID = id
+
+	return plain, key, nil
+}
+
+// Authenticate возвращает ключ по его открытому значению.
+func (a *APIKeys) Authenticate(ctx context.Context, plain string) (*models.APIKey, error) {
+	if !strings.HasPrefix(plain, apiKeyPrefix) {
+		return nil, ErrUnauthorized
+	}
+
+	key, err := a.repo.FindAPIKeyByHash(ctx, hashAPIKey(plain))
+	if err != nil {
+		return nil, fmt.Errorf("ошибка проверки API-ключа: %w", err)
+	}
+	if key == nil || key.RevokedAt != nil {
+		return nil, ErrUnauthorized
+	}
+	return key, nil
+}
+
+// Revoke отзывает ключ по идентификатору.
+func (a *APIKeys) Revoke(ctx context.Context, id int64) error {
+	revoked, err := a.repo.RevokeAPIKey(ctx, id)
+	if err != nil {
+		return fmt.Errorf("ошибка отзыва API-ключа: %w", err)
+	}
+	if !revoked {
+		return wrapf(ErrAPIKeyNotFound, "%d", id)
+	}
+	return nil
+}
+
+func hashAPIKey(plain string) string {
+	sum := sha256.Sum256([]byte(plain))
+	return hex.EncodeToString(sum[:])
+}
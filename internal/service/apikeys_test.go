@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"linkreduction/internal/mocks"
+	"linkreduction/internal/models"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAPIKeys_Create(t *testing.T) {
+	t.Run("stores only the hash", func(t *testing.T) {
+		repo := new(mocks.APIKeyRepo)
+		var stored models.APIKey
+		repo.On("CreateAPIKey", mock.Anything, mock.AnythingOfType("models.APIKey")).
+			Run(func(args mock.Arguments) { stored = args.Get(1).(models.APIKey) }).
+			Return(int64(7), nil)
+
+		plain, key, err := NewAPIKeys(repo).Create(context.Background(), " team ", "ci")
+		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(plain, apiKeyPrefix))
+		assert.Equal(t, int64(7), key.ID)
+		assert.Equal(t, "team", stored.OwnerID)
+		assert.Equal(t, "ci", stored.Name)
+		assert.Equal(t, hashAPIKey(plain), stored.KeyHash)
+		assert.NotContains(t, stored.KeyHash, plain)
+	})
+
+	t.Run("empty owner", func(t *testing.T) {
+		repo := new(mocks.APIKeyRepo)
+
+		_, _, err := NewAPIKeys(repo).Create(context.Background(), "  ", "ci")
+		assert.Error(t, err)
+		repo.AssertNotCalled(t, "CreateAPIKey", mock.Anything, mock.Anything)
+	})
+}
+
+func TestAPIKeys_Authenticate(t *testing.T) {
+	const plain = apiKeyPrefix + "secret"
+	revokedAt := time.Now()
+
+	tests := []struct {
+		name      string
+		plain     string
+		setupMock func(repo *mocks.APIKeyRepo)
+		wantOwner string
+		wantErr   error
+	}{
+		{
+			name:  "valid key",
+			plain: plain,
+			setupMock: func(repo *mocks.APIKeyRepo) {
+				repo.On("FindAPIKeyByHash", mock.Anything, hashAPIKey(plain)).Return(&models.APIKey{ID: 1, OwnerID: "team"}, nil)
+			},
+			wantOwner: "team",
+		},
+		{
+			name:  "unknown key",
+			plain: plain,
+			setupMock: func(repo *mocks.APIKeyRepo) {
+				repo.On("FindAPIKeyByHash", mock.Anything, hashAPIKey(plain)).Return(nil, nil)
+			},
+			wantErr: ErrUnauthorized,
+		},
+		{
+			name:  "revoked key",
+			plain: plain,
+			setupMock: func(repo *mocks.APIKeyRepo) {
+				repo.On("FindAPIKeyByHash", mock.Anything, hashAPIKey(plain)).Return(&models.APIKey{ID: 1, OwnerID: "team", RevokedAt: &revokedAt}, nil)
+			},
+			wantErr: ErrUnauthorized,
+		},
+		{
+			name:      "bad prefix",
+			plain:     "secret",
+			setupMock: func(repo *mocks.APIKeyRepo) {},
+			wantErr:   ErrUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(mocks.APIKeyRepo)
+			tt.setupMock(repo)
+
+			key, err := NewAPIKeys(repo).Authenticate(context.Background(), tt.plain)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Nil(t, key)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantOwner, key.OwnerID)
+			}
+			repo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAPIKeys_Revoke(t *testing.T) {
+	repo := new(mocks.APIKeyRepo)
+	repo.On("RevokeAPIKey", mock.Anything, int64(1)).Return(true, nil)
+	repo.On("RevokeAPIKey", mock.Anything, int64(2)).Return(false, nil)
+	repo.On("RevokeAPIKey", mock.Anything, int64(3)).Return(false, fmt.Errorf("db error"))
+
+	keys := NewAPIKeys(repo)
+	assert.NoError(t, keys.Revoke(context.Background(), 1))
+	assert.ErrorIs(t, keys.Revoke(context.Background(), 2), ErrAPIKeyNotFound)
+	assert.Error(t, keys.Revoke(context.Background(), 3))
+}
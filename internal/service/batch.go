@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"linkreduction/internal/models"
+)
+
+const defaultBatchMaxItems = 10000
+
+// BatchItem — один URL в пакетном запросе на сокращение.
+type BatchItem struct {
+	URL     string
+	Options ShortenOptions
+}
+
+// BatchResult — результат сокращения одного элемента пакета; при ошибке Err не nil.
+type BatchResult struct {
+	Link models.LinkURL
+	Err  error
+}
+
+// ShortenBatch сокращает пачку URL: каждый элемент проверяется отдельно, а новые ссылки
+// сохраняются в базе одной транзакцией; ключ, занятый к моменту вставки, отклоняет только
+// свой элемент. На сетевые проверки политики всех элементов отводится общее время
+// policy.batch_check_budget.
+func (s *Service) ShortenBatch(ctx context.Context, items []BatchItem, baseUrl string) ([]BatchResult, error) {
+	if len(items) > s.batchMaxItems {
+		return nil, wrapf(ErrInvalidInput, "пакет содержит %d элементов, максимум %d", len(items), s.batchMaxItems)
+	}
+
+	checkCtx := ctx
+	if s.policy != nil {
+		var cancel context.CancelFunc
+		checkCtx, cancel = s.policy.batchContext(ctx)
+		defer cancel()
+	}
+
+	results := make([]BatchResult, len(items))
+	taken := make(map[string]struct{}, len(items))
+	shared := make(map[string]models.LinkURL)
+	links := make([]models.LinkURL, 0, len(items))
+	pending := make([]int, 0, len(items))
+
+	for i, item := range items {
+		// Повтор того же URL без собственных параметров получает ту же общую ссылку
+		if !item.Options.dedicated() {
+			if link, ok := shared[item.URL]; ok {
+				results[i].Link = link
+				continue
+			}
+		}
+
+		link, err := s.shorten(ctx, checkCtx, item.URL, baseUrl, item.Options, taken)
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+
+		results[i].Link = link
+		taken[link.ShortLink] = struct{}{}
+		if !link.Custom {
+			shared[link.OriginalURL] = link
+		}
+		links = append(links, link)
+		pending = append(pending, i)
+	}
+
+	if len(links) == 0 {
+		return results, nil
+	}
+
+	saved, err := s.saveLinks(ctx, links)
+	if err != nil {
+		for _, i := range pending {
+			results[i] = BatchResult{Err: err}
+		}
+		return results, nil
+	}
+
+	failed := make(map[string]error)
+	for j, i := range pending {
+		results[i] = saved[j]
+		if !links[j].Custom {
+			if saved[j].Err != nil {
+				failed[links[j].OriginalURL] = saved[j].Err
+			} else {
+				shared[links[j].OriginalURL] = saved[j].Link
+			}
+		}
+	}
+	// Повторы URL получают ссылку в том виде, в каком она сохранена в базе
+	for i := range results {
+		if results[i].Err == nil && !results[i].Link.Custom {
+			if err, ok := failed[results[i].Link.OriginalURL]; ok {
+				results[i] = BatchResult{Err: err}
+			} else if link, ok := shared[results[i].Link.OriginalURL]; ok {
+				results[i].Link = link
+			}
+		}
+	}
+
+	return results, nil
+}
+
+// saveLinks сохраняет ссылки в базе одной транзакцией и возвращает результат для каждой.
+// Ссылка, ключ которой заняли между проверкой и вставкой, получает ErrAliasTaken, остальные
+// сохраняются. Если доступна Kafka, в той же транзакции записываются сообщения outbox,
+// которые затем публикует OutboxRelay, поэтому возвращённая ссылка уже доступна для перехода.
+func (s *Service) saveLinks(ctx context.Context, links []models.LinkURL) ([]BatchResult, error) {
+	for i := range links {
+		s.fillRedirectCode(&links[i])
+	}
+
+	saved, statuses, err := s.repo.SaveLinks(ctx, links, s.producer != nil)
+	if err != nil {
+		s.countCreated("error", "db_insert", len(links))
+		return nil, fmt.Errorf("ошибка сохранения ссылок: %w", err)
+	}
+
+	results := make([]BatchResult, len(saved))
+	shortLinks := make([]string, 0, len(saved))
+	for i, link := range saved {
+		if !statuses[i].Persisted() {
+			s.countCreated("error", "alias_taken", 1)
+			results[i].Err = wrapf(ErrAliasTaken, "%s", link.ShortLink)
+			continue
+		}
+		s.countCreated("success", "none", 1)
+		results[i].Link = link
+		shortLinks = append(shortLinks, link.ShortLink)
+	}
+	if len(shortLinks) == 0 {
+		return results, nil
+	}
+
+	// Ключ могли запросить до создания ссылки, и в кэше осталась отрицательная запись
+	if err := s.cache.DeleteRedirects(ctx, shortLinks); err != nil {
+		s.cacheFailed("delete_redirects")
+	}
+
+	for _, result := range results {
+		if result.Err == nil && !result.Link.Custom {
+			s.cacheShortLink(ctx, result.Link)
+		}
+	}
+	return results, nil
+}
+
+func (s *Service) countCreated(status, reason string, n int) {
+	if s.metrics != nil && s.metrics.CreateShortLinkTotal != nil {
+		s.metrics.CreateShortLinkTotal.WithLabelValues(status, reason).Add(float64(n))
+	}
+}
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"linkreduction/internal/mocks"
+	"linkreduction/internal/models"
+	"testing"
+
+	saramamocks "github.com/IBM/sarama/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestService_ShortenBatch(t *testing.T) {
+	const baseURL = "https://localhost:8080"
+
+	t.Run("per-item results and single batch insert", func(t *testing.T) {
+		ctx, repo, cache, svc := getMocksWithService()
+
+		cache.On("GetShortLink", ctx, mock.Anything).Return("", nil)
+		repo.On("FindByOriginalURL", ctx, mock.Anything).Return(nil, nil)
+		repo.On("FindByShortLink", ctx, mock.Anything).Return("", nil)
+		repo.On("SaveLinks", ctx, mock.MatchedBy(func(links []models.LinkURL) bool {
+			return len(links) == 2
+		}), false).Return(saveAsIs).Once()
+		cache.On("DeleteRedirects", ctx, mock.Anything).Return(nil)
+		cache.On("SetShortLink", ctx, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+		results, err := svc.ShortenBatch(ctx, []BatchItem{
+			{URL: "https://a.com"},
+			{URL: "not a url"},
+			{URL: "https://b.com"},
+			{URL: "https://a.com"},
+		}, baseURL)
+
+		assert.NoError(t, err)
+		assert.Len(t, results, 4)
+		assert.NoError(t, results[0].Err)
+		assert.Error(t, results[1].Err)
+		assert.NoError(t, results[2].Err)
+		assert.Equal(t, results[0].Link, results[3].Link, "повтор URL должен получить ту же ссылку")
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("keys are unique within a batch", func(t *testing.T) {
+		ctx, repo, cache, _ := getMocksWithService()
+		svc := NewLinkService(ctx, repo, cache, nil, nil, WithKeyGenerator(constKeyGenerator{"dup"}), WithKeyMaxAttempts(1))
+
+		cache.On("GetShortLink", ctx, mock.Anything).Return("", nil)
+		repo.On("FindByOriginalURL", ctx, mock.Anything).Return(nil, nil)
+		repo.On("FindByShortLink", ctx, "dup").Return("", nil)
+		repo.On("SaveLinks", ctx, mock.Anything, false).Return(saveAsIs)
+		cache.On("DeleteRedirects", ctx, mock.Anything).Return(nil)
+		cache.On("SetShortLink", ctx, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+		results, err := svc.ShortenBatch(ctx, []BatchItem{
+			{URL: "https://a.com"},
+			{URL: "https://b.com"},
+			{URL: "https://c.com", Options: ShortenOptions{Alias: "dup"}},
+		}, baseURL)
+
+		assert.NoError(t, err)
+		assert.NoError(t, results[0].Err)
+		assert.Error(t, results[1].Err)
+		assert.ErrorIs(t, results[2].Err, ErrAliasTaken)
+	})
+
+	t.Run("persistence error is reported per item", func(t *testing.T) {
+		ctx, repo, cache, svc := getMocksWithService()
+
+		cache.On("GetShortLink", ctx, mock.Anything).Return("", nil)
+		repo.On("FindByOriginalURL", ctx, mock.Anything).Return(nil, nil)
+		repo.On("FindByShortLink", ctx, mock.Anything).Return("", nil)
+		repo.On("SaveLinks", ctx, mock.Anything, false).Return(nil, nil, fmt.Errorf("db down"))
+
+		results, err := svc.ShortenBatch(ctx, []BatchItem{{URL: "https://a.com"}, {URL: "bad"}}, baseURL)
+
+		assert.NoError(t, err)
+		assert.Error(t, results[0].Err)
+		assert.Error(t, results[1].Err)
+		assert.Empty(t, results[0].Link.ShortLink)
+	})
+
+	t.Run("key taken at insert fails only its item", func(t *testing.T) {
+		ctx, repo, cache, svc := getMocksWithService()
+
+		cache.On("GetShortLink", ctx, mock.Anything).Return("", nil)
+		repo.On("FindByOriginalURL", ctx, mock.Anything).Return(nil, nil)
+		repo.On("FindByShortLink", ctx, mock.Anything).Return("", nil)
+		repo.On("SaveLinks", ctx, mock.Anything, false).Return(
+			func(_ context.Context, links []models.LinkURL, _ bool) ([]models.LinkURL, []models.InsertStatus, error) {
+				return links, []models.InsertStatus{models.InsertConflict, models.InsertInserted}, nil
+			})
+		cache.On("DeleteRedirects", ctx, []string{"sale"}).Return(nil)
+
+		results, err := svc.ShortenBatch(ctx, []BatchItem{
+			{URL: "https://a.com"},
+			{URL: "https://b.com", Options: ShortenOptions{Alias: "sale"}},
+			{URL: "https://a.com"},
+		}, baseURL)
+
+		assert.NoError(t, err)
+		assert.ErrorIs(t, results[0].Err, ErrAliasTaken)
+		assert.NoError(t, results[1].Err)
+		assert.Equal(t, "sale", results[1].Link.ShortLink)
+		assert.ErrorIs(t, results[2].Err, ErrAliasTaken, "повтор URL должен получить ошибку своей ссылки")
+		cache.AssertExpectations(t)
+		cache.AssertNotCalled(t, "SetShortLink", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("too many items", func(t *testing.T) {
+		ctx, repo, cache, _ := getMocksWithService()
+		svc := NewLinkService(ctx, repo, cache, nil, nil, WithBatchMaxItems(1))
+
+		_, err := svc.ShortenBatch(ctx, []BatchItem{{URL: "https://a.com"}, {URL: "https://b.com"}}, baseURL)
+		assert.Error(t, err)
+	})
+
+	t.Run("repeats get the link stored by a concurrent request", func(t *testing.T) {
+		ctx, repo, cache, svc := getMocksWithService()
+
+		cache.On("GetShortLink", ctx, mock.Anything).Return("", nil)
+		repo.On("FindByOriginalURL", ctx, mock.Anything).Return(nil, nil)
+		repo.On("FindByShortLink", ctx, mock.Anything).Return("", nil)
+		repo.On("SaveLinks", ctx, mock.Anything, false).Return([]models.LinkURL{
+			{OriginalURL: "https://a.com", ShortLink: "stored", RedirectCode: 301},
+		}, []models.InsertStatus{models.InsertExisting}, nil)
+		cache.On("DeleteRedirects", ctx, []string{"stored"}).Return(nil)
+		cache.On("SetShortLink", ctx, "https://a.com", "stored", mock.Anything).Return(nil)
+
+		results, err := svc.ShortenBatch(ctx, []BatchItem{{URL: "https://a.com"}, {URL: "https://a.com"}}, baseURL)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "stored", results[0].Link.ShortLink)
+		assert.Equal(t, "stored", results[1].Link.ShortLink)
+	})
+}
+
+func TestService_SaveLinksWritesOutboxWithProducer(t *testing.T) {
+	ctx := context.Background()
+	repo := new(mocks.LinkRepo)
+	cache := new(mocks.LinkCache)
+	svc := NewLinkService(ctx, repo, cache, saramamocks.NewSyncProducer(t, nil), nil)
+	links := []models.LinkURL{{OriginalURL: "https://a.com", ShortLink: "a1"}}
+
+	repo.On("SaveLinks", ctx, links, true).Return(saveAsIs)
+	cache.On("DeleteRedirects", ctx, []string{"a1"}).Return(nil)
+	cache.On("SetShortLink", ctx, "https://a.com", "a1", mock.Anything).Return(nil)
+
+	saved, err := svc.saveLinks(ctx, links)
+	assert.NoError(t, err)
+	assert.NoError(t, saved[0].Err)
+	assert.Equal(t, 301, saved[0].Link.RedirectCode)
+	repo.AssertExpectations(t)
+	cache.AssertExpectations(t)
+}
+
+func saveAsIs(_ context.Context, links []models.LinkURL, _ bool) ([]models.LinkURL, []models.InsertStatus, error) {
+	statuses := make([]models.InsertStatus, len(links))
+	for i := range statuses {
+		statuses[i] = models.InsertInserted
+	}
+	return links, statuses, nil
+}
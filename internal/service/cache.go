@@ -0,0 +1,147 @@
+package service
+
+import (
+	"context"
+	"github.com/sirupsen/logrus"
+	"linkreduction/internal/models"
+	"sync"
+	"time"
+)
+
+// invalidationRetryInterval — период повтора очистки кэша, не удавшейся после изменения ссылки в базе.
+const invalidationRetryInterval = 5 * time.Second
+
+// nopCache используется, когда сервис запущен без Redis: каждое чтение — промах, запись ничего не делает.
+type nopCache struct{}
+
+func (nopCache) GetShortLink(context.Context, string) (string, error) { return "", nil }
+
+func (nopCache) SetShortLink(context.Context, string, string, time.Duration) error { return nil }
+
+func (nopCache) GetRedirect(context.Context, string) (*models.Redirect, error) { return nil, nil }
+
+func (nopCache) SetRedirect(context.Context, string, models.Redirect, time.Duration) error {
+	return nil
+}
+
+func (nopCache) DeleteRedirects(context.Context, []string) error { return nil }
+
+func (nopCache) Invalidate(context.Context, string, string) error { return nil }
+
+func (nopCache) GetTitle(context.Context, string) (string, bool, error) { return "", false, nil }
+
+func (nopCache) SetTitle(context.Context, string, string, time.Duration) error { return nil }
+
+// cacheFailed учитывает сбой кэша, после которого запрос продолжает обслуживаться без него.
+func (s *Service) cacheFailed(op string) {
+	if s.metrics != nil && s.metrics.CacheErrorsTotal != nil {
+		s.metrics.CacheErrorsTotal.WithLabelValues(op).Inc()
+	}
+}
+
+// invalidation — записи кэша ссылки, которые нужно сбросить.
+type invalidation struct {
+	shortLink   string
+	originalURL string
+}
+
+// staleCache — ссылки, изменённые в базе, записи которых не удалось сбросить в кэше. Пока сброс
+// не повторён успешно, кэш для них не читается: после восстановления Redis вернул бы прежний адрес.
+type staleCache struct {
+	mu      sync.Mutex
+	pending map[invalidation]struct{}
+	links   map[string]int
+	urls    map[string]int
+}
+
+func (c *staleCache) add(inv invalidation) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.pending == nil {
+		c.pending = make(map[invalidation]struct{})
+		c.links = make(map[string]int)
+		c.urls = make(map[string]int)
+	}
+	if _, ok := c.pending[inv]; ok {
+		return
+	}
+	c.pending[inv] = struct{}{}
+	c.links[inv.shortLink]++
+	c.urls[inv.originalURL]++
+}
+
+func (c *staleCache) remove(inv invalidation) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.pending[inv]; !ok {
+		return
+	}
+	delete(c.pending, inv)
+	if c.links[inv.shortLink]--; c.links[inv.shortLink] == 0 {
+		delete(c.links, inv.shortLink)
+	}
+	if c.urls[inv.originalURL]--; c.urls[inv.originalURL] == 0 {
+		delete(c.urls, inv.originalURL)
+	}
+}
+
+func (c *staleCache) snapshot() []invalidation {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	invs := make([]invalidation, 0, len(c.pending))
+	for inv := range c.pending {
+		invs = append(invs, inv)
+	}
+	return invs
+}
+
+func (c *staleCache) hasLink(shortLink string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.links[shortLink] > 0
+}
+
+func (c *staleCache) hasURL(originalURL string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.urls[originalURL] > 0
+}
+
+// invalidate сбрасывает записи ссылки в кэше после её изменения в базе. Сбой не возвращается:
+// изменение уже сохранено, а сброс повторяет RetryInvalidations.
+func (s *Service) invalidate(ctx context.Context, shortLink, originalURL string) {
+	inv := invalidation{shortLink: shortLink, originalURL: originalURL}
+	if err := s.cache.Invalidate(ctx, shortLink, originalURL); err != nil {
+		s.cacheFailed("invalidate")
+		s.stale.add(inv)
+	}
+}
+
+// RetryInvalidations периодически повторяет не удавшиеся сбросы кэша до отмены контекста сервиса.
+func (s *Service) RetryInvalidations(logger *logrus.Logger) {
+	ticker := time.NewTicker(invalidationRetryInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.retryInvalidations(logger)
+		case <-s.ctx.Done():
+			return
+		}
+	}
+}
+
+func (s *Service) retryInvalidations(logger *logrus.Logger) {
+	for _, inv := range s.stale.snapshot() {
+		if err := s.cache.Invalidate(s.ctx, inv.shortLink, inv.originalURL); err != nil {
+			// Кэш всё ещё недоступен: остальные попытки отложены до следующего периода
+			return
+		}
+		s.stale.remove(inv)
+		logger.WithField("short_link", inv.shortLink).Info("Кэш ссылки сброшен повторно")
+	}
+}
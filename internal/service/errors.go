@@ -0,0 +1,85 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Error — ошибка сервиса со стабильным машиночитаемым кодом. Конкретные ошибки
+// относятся к одной из базовых категорий, которую можно проверить через errors.Is.
+type Error struct {
+	Code    string
+	Message string
+	kind    error
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.kind
+}
+
+// Базовые категории ошибок.
+var (
+	// ErrInvalidURL — адрес назначения некорректен или запрещён.
+	ErrInvalidURL = &Error{Code: "invalid_url", Message: "некорректный URL"}
+	// ErrInvalidInput — некорректны остальные параметры запроса.
+	ErrInvalidInput = &Error{Code: "invalid_input", Message: "некорректные параметры запроса"}
+	ErrNotFound     = &Error{Code: "not_found", Message: "не найдено"}
+	ErrConflict     = &Error{Code: "conflict", Message: "конфликт с существующими данными"}
+	ErrExpired      = &Error{Code: "expired", Message: "срок действия истёк"}
+	// ErrUnavailable — временно недоступна внешняя зависимость (Kafka, Redis); запрос можно повторить.
+	ErrUnavailable = &Error{Code: "unavailable", Message: "сервис временно недоступен"}
+)
+
+var (
+	// ErrAliasTaken возвращается, когда запрошенный псевдоним уже занят другой ссылкой.
+	ErrAliasTaken = newError(ErrConflict, "alias_taken", "псевдоним уже занят")
+	// ErrLinkExpired возвращается при обращении к ссылке с истёкшим сроком действия.
+	ErrLinkExpired = newError(ErrExpired, "link_expired", "срок действия ссылки истёк")
+	// ErrLinkNotFound возвращается, если короткой ссылки не существует.
+	ErrLinkNotFound = newError(ErrNotFound, "link_not_found", "короткая ссылка не найдена")
+
+	ErrInvalidAlias        = newError(ErrInvalidInput, "invalid_alias", "некорректный псевдоним")
+	ErrInvalidTTL          = newError(ErrInvalidInput, "invalid_ttl", "некорректное время жизни ссылки")
+	ErrInvalidRedirectCode = newError(ErrInvalidInput, "invalid_redirect_code", "некорректный код перенаправления")
+	ErrInvalidPassword     = newError(ErrInvalidInput, "invalid_password", "некорректный пароль ссылки")
+)
+
+func newError(kind *Error, code, message string) *Error {
+	return &Error{Code: code, Message: message, kind: kind}
+}
+
+// wrapf дополняет ошибку err подробностями, сохраняя её код и категорию.
+func wrapf(err error, format string, args ...any) error {
+	return fmt.Errorf("%w: %s", err, fmt.Sprintf(format, args...))
+}
+
+// ErrorCode возвращает машиночитаемый код самой конкретной ошибки сервиса в цепочке;
+// пустая строка означает непредвиденную ошибку.
+func ErrorCode(err error) string {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Code
+	}
+	return ""
+}
+
+// Retryable сообщает, может ли повтор исправить ошибку записи. Повтор бесполезен, если ошибку
+// вызвали сами данные: некорректные параметры или отказ базы с SQLSTATE класса 22 (некорректное
+// значение) или 23 (нарушение ограничения). Остальные ошибки, в том числе обрыв соединения
+// и ErrUnavailable, считаются временными.
+func Retryable(err error) bool {
+	if err == nil || errors.Is(err, ErrInvalidInput) || errors.Is(err, ErrInvalidURL) {
+		return false
+	}
+	var sqlErr interface{ SQLState() string }
+	if errors.As(err, &sqlErr) {
+		state := sqlErr.SQLState()
+		return !strings.HasPrefix(state, "22") && !strings.HasPrefix(state, "23")
+	}
+	return true
+}
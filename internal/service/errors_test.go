@@ -0,0 +1,60 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorCode(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		kind     error
+		wantCode string
+	}{
+		{name: "specific error keeps its code", err: wrapf(ErrAliasTaken, "%s", "sale"), kind: ErrConflict, wantCode: "alias_taken"},
+		{name: "wrapped again by caller", err: fmt.Errorf("сокращение: %w", ErrLinkNotFound), kind: ErrNotFound, wantCode: "link_not_found"},
+		{name: "category only", err: wrapf(ErrUnavailable, "ошибка чтения из кэша: %v", errors.New("timeout")), kind: ErrUnavailable, wantCode: "unavailable"},
+		{name: "policy rejection is an invalid URL", err: wrapf(ErrDestinationBlocked, "домен заблокирован"), kind: ErrInvalidURL, wantCode: "destination_blocked"},
+		{name: "unexpected error", err: errors.New("db error"), wantCode: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.wantCode, ErrorCode(tt.err))
+			if tt.kind != nil {
+				assert.ErrorIs(t, tt.err, tt.kind)
+			}
+		})
+	}
+
+	assert.Equal(t, "псевдоним уже занят: sale", wrapf(ErrAliasTaken, "%s", "sale").Error())
+}
+
+func TestRetryable(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		retryable bool
+	}{
+		{name: "no error", err: nil},
+		{name: "invalid input", err: wrapf(ErrInvalidInput, "длина батча нулевая")},
+		{name: "invalid url", err: fmt.Errorf("сохранение: %w", ErrInvalidURL)},
+		{name: "value too long", err: fmt.Errorf("ошибка при внедрение батча: %w", &pgconn.PgError{Code: "22001"})},
+		{name: "check constraint", err: &pgconn.PgError{Code: "23514"}},
+		{name: "admin shutdown", err: &pgconn.PgError{Code: "57P01"}, retryable: true},
+		{name: "serialization failure", err: &pgconn.PgError{Code: "40001"}, retryable: true},
+		{name: "unavailable", err: wrapf(ErrUnavailable, "ошибка отправки в Kafka"), retryable: true},
+		{name: "connection error", err: errors.New("dial tcp 10.0.0.1:5432: connect: connection refused"), retryable: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.retryable, Retryable(tt.err))
+		})
+	}
+}
@@ -8,17 +8,83 @@ import (
 
 //go:generate mockery --name=LinkRepo --output=../mocks --filename=link_repo.go --with-expecter=true
 type LinkRepo interface {
-	FindByOriginalURL(ctx context.Context, originalURL string) (string, error)
+	// FindByOriginalURL возвращает действующую общую ссылку на URL со сроком её действия; nil — ссылки нет.
+	FindByOriginalURL(ctx context.Context, originalURL string) (*models.LinkURL, error)
 	FindByShortLink(ctx context.Context, shortLink string) (string, error)
-	Insert(ctx context.Context, originalURL, shortLink string) error
-	InsertBatch(ctx context.Context, links []models.LinkURL) error
-	DeleteOldLinks(ctx context.Context, threshold string) error
+	FindLink(ctx context.Context, shortLink string) (*models.LinkURL, error)
+	// SaveLinks сохраняет ссылки в одной транзакции, вместе с сообщениями outbox, если outbox установлен,
+	// и возвращает статус каждой ссылки. Для общей ссылки, уже существующей в базе, возвращается
+	// сохранённая ссылка (InsertExisting). Ссылка с занятым ключом не сохраняется (InsertConflict),
+	// остальные ссылки это не затрагивает.
+	SaveLinks(ctx context.Context, links []models.LinkURL, outbox bool) ([]models.LinkURL, []models.InsertStatus, error)
+	// InsertBatch идемпотентно сохраняет ссылки, полученные из Kafka, и возвращает результат
+	// для каждой ссылки в том же порядке.
+	InsertBatch(ctx context.Context, links []models.LinkURL) ([]models.InsertStatus, error)
+	UpdateOriginalURL(ctx context.Context, shortLink, originalURL string) error
+	Delete(ctx context.Context, shortLink string) error
+	DeleteExpiredLinks(ctx context.Context) error
+}
+
+// OutboxRepo выдаёт неотправленные сообщения outbox и удаляет их после успешной публикации.
+//
+//go:generate mockery --name=OutboxRepo --output=../mocks --filename=outbox_repo.go --with-expecter=true
+type OutboxRepo interface {
+	ProcessOutbox(ctx context.Context, limit int, publish func([]models.OutboxMessage) error) (int, error)
+}
+
+// KeySequence выдаёт монотонно растущие идентификаторы для стратегии генерации ключей "sequence".
+//
+//go:generate mockery --name=KeySequence --output=../mocks --filename=key_sequence.go --with-expecter=true
+type KeySequence interface {
+	NextKeyID(ctx context.Context) (int64, error)
+}
+
+//go:generate mockery --name=ClickRepo --output=../mocks --filename=click_repo.go --with-expecter=true
+type ClickRepo interface {
+	InsertClicks(ctx context.Context, clicks []models.Click) error
+	// GetStats учитывает только переходы не раньше createdAt: ключ удалённой или истёкшей ссылки
+	// может достаться новой, и её статистика не должна включать чужие переходы.
+	GetStats(ctx context.Context, shortLink string, createdAt, since time.Time, topReferrers int) (*models.LinkStats, error)
+}
+
+//go:generate mockery --name=APIKeyRepo --output=../mocks --filename=api_key_repo.go --with-expecter=true
+type APIKeyRepo interface {
+	CreateAPIKey(ctx context.Context, key models.APIKey) (int64, error)
+	FindAPIKeyByHash(ctx context.Context, keyHash string) (*models.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id int64) (bool, error)
+}
+
+// RateLimitStore считает запросы клиента в скользящем окне. При отказе возвращает,
+// через сколько освободится место в окне.
+//
+//go:generate mockery --name=RateLimitStore --output=../mocks --filename=rate_limit_store.go --with-expecter=true
+type RateLimitStore interface {
+	Allow(ctx context.Context, key string, limit int, window time.Duration) (bool, time.Duration, error)
+}
+
+// TitleCache хранит заголовки страниц назначения; ok=false означает промах.
+//
+//go:generate mockery --name=TitleCache --output=../mocks --filename=title_cache.go --with-expecter=true
+type TitleCache interface {
+	GetTitle(ctx context.Context, pageURL string) (title string, ok bool, err error)
+	SetTitle(ctx context.Context, pageURL, title string, ttl time.Duration) error
+}
+
+// LinkInvalidations сообщает остальным экземплярам сервиса, что кэш ссылки нужно сбросить.
+//
+//go:generate mockery --name=LinkInvalidations --output=../mocks --filename=link_invalidations.go --with-expecter=true
+type LinkInvalidations interface {
+	Publish(ctx context.Context, shortLink, originalURL string) error
 }
 
 //go:generate mockery --name=LinkCache --output=../mocks --filename=link_cache.go --with-expecter=true
 type LinkCache interface {
 	GetShortLink(ctx context.Context, originalURL string) (string, error)
 	SetShortLink(ctx context.Context, originalURL, shortLink string, ttl time.Duration) error
-	GetOriginalURL(ctx context.Context, shortLink string) (string, error)
-	SetOriginalURL(ctx context.Context, shortLink, originalURL string, ttl time.Duration) error
+	GetRedirect(ctx context.Context, shortLink string) (*models.Redirect, error)
+	SetRedirect(ctx context.Context, shortLink string, redirect models.Redirect, ttl time.Duration) error
+	// DeleteRedirects удаляет записи перенаправления, в том числе отрицательные, для только что созданных ссылок.
+	DeleteRedirects(ctx context.Context, shortLinks []string) error
+	// Invalidate удаляет закэшированные соответствия ссылки в обе стороны.
+	Invalidate(ctx context.Context, shortLink, originalURL string) error
 }
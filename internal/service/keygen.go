@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+const (
+	KeyStrategyHash     = "hash"
+	KeyStrategyRandom   = "random"
+	KeyStrategySequence = "sequence"
+
+	defaultKeyLength      = 6
+	defaultKeyMaxAttempts = 10
+	// attemptsPerLength — сколько коллизий подряд допускается до удлинения ключа на один символ.
+	attemptsPerLength = 3
+)
+
+const base62Alphabet = "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+
+// KeyGenerator генерирует ключ короткой ссылки. attempt — номер попытки начиная с 0:
+// после коллизии сервис вызывает генератор повторно с увеличенным attempt.
+type KeyGenerator interface {
+	Generate(ctx context.Context, originalURL string, attempt int) (string, error)
+}
+
+// NewKeyGenerator создаёт генератор по названию стратегии из конфигурации.
+func NewKeyGenerator(strategy string, length int, sequence KeySequence) (KeyGenerator, error) {
+	if length <= 0 {
+		length = defaultKeyLength
+	}
+
+	switch strategy {
+	case "", KeyStrategyHash:
+		return HashKeyGenerator{Length: length}, nil
+	case KeyStrategyRandom:
+		return RandomKeyGenerator{Length: length}, nil
+	case KeyStrategySequence:
+		if sequence == nil {
+			return nil, fmt.Errorf("для стратегии %q требуется последовательность в базе данных", KeyStrategySequence)
+		}
+		return SequenceKeyGenerator{Sequence: sequence}, nil
+	default:
+		return nil, fmt.Errorf("неизвестная стратегия генерации ключей: %q", strategy)
+	}
+}
+
+// HashKeyGenerator берёт префикс MD5 от URL. Повторные попытки хэшируют URL с суффиксом "_N"
+// и постепенно удлиняют ключ, поэтому коллизии не приводят к отказу при росте таблицы.
+type HashKeyGenerator struct {
+	Length int
+}
+
+func (g HashKeyGenerator) Generate(_ context.Context, originalURL string, attempt int) (string, error) {
+	input := originalURL
+	if attempt > 0 {
+		input = fmt.Sprintf("%s_%d", originalURL, attempt)
+	}
+	hash := fmt.Sprintf("%x", md5.Sum([]byte(input)))
+	return hash[:min(len(hash), grownLength(g.Length, attempt))], nil
+}
+
+// RandomKeyGenerator выдаёт случайный ключ в base62.
+type RandomKeyGenerator struct {
+	Length int
+}
+
+func (g RandomKeyGenerator) Generate(_ context.Context, _ string, attempt int) (string, error) {
+	length := grownLength(g.Length, attempt)
+	alphabetSize := big.NewInt(int64(len(base62Alphabet)))
+
+	var key strings.Builder
+	key.Grow(length)
+	for i := 0; i < length; i++ {
+		n, err := rand.Int(rand.Reader, alphabetSize)
+		if err != nil {
+			return "", fmt.Errorf("ошибка генерации случайного ключа: %w", err)
+		}
+		key.WriteByte(base62Alphabet[n.Int64()])
+	}
+	return key.String(), nil
+}
+
+// SequenceKeyGenerator кодирует в base62 следующее значение последовательности базы данных.
+// Ключи уникальны по построению, коллизии возможны только с пользовательскими псевдонимами.
+type SequenceKeyGenerator struct {
+	Sequence KeySequence
+}
+
+func (g SequenceKeyGenerator) Generate(ctx context.Context, _ string, _ int) (string, error) {
+	id, err := g.Sequence.NextKeyID(ctx)
+	if err != nil {
+		return "", fmt.Errorf("ошибка получения значения последовательности: %w", err)
+	}
+	return encodeBase62(id), nil
+}
+
+func grownLength(length, attempt int) int {
+	if length <= 0 {
+		length = defaultKeyLength
+	}
+	return length + attempt/attemptsPerLength
+}
+
+func encodeBase62(n int64) string {
+	if n == 0 {
+		return string(base62Alphabet[0])
+	}
+
+	var buf [11]byte
+	i := len(buf)
+	for n > 0 {
+		i--
+		buf[i] = base62Alphabet[n%62]
+		n /= 62
+	}
+	return string(buf[i:])
+}
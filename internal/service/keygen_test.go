@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"linkreduction/internal/mocks"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestNewKeyGenerator(t *testing.T) {
+	sequence := new(mocks.KeySequence)
+
+	tests := []struct {
+		strategy    string
+		sequence    KeySequence
+		expected    KeyGenerator
+		expectError bool
+	}{
+		{strategy: "", expected: HashKeyGenerator{Length: defaultKeyLength}},
+		{strategy: KeyStrategyHash, expected: HashKeyGenerator{Length: defaultKeyLength}},
+		{strategy: KeyStrategyRandom, expected: RandomKeyGenerator{Length: defaultKeyLength}},
+		{strategy: KeyStrategySequence, sequence: sequence, expected: SequenceKeyGenerator{Sequence: sequence}},
+		{strategy: KeyStrategySequence, expectError: true},
+		{strategy: "uuid", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.strategy, func(t *testing.T) {
+			gen, err := NewKeyGenerator(tt.strategy, 0, tt.sequence)
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, gen)
+		})
+	}
+}
+
+func TestHashKeyGenerator_GrowsAfterCollisions(t *testing.T) {
+	gen := HashKeyGenerator{Length: 6}
+
+	first, _ := gen.Generate(context.Background(), "https://example.com", 0)
+	retry, _ := gen.Generate(context.Background(), "https://example.com", 1)
+	longer, _ := gen.Generate(context.Background(), "https://example.com", attemptsPerLength)
+
+	assert.Len(t, first, 6)
+	assert.Len(t, retry, 6)
+	assert.NotEqual(t, first, retry)
+	assert.Len(t, longer, 7)
+}
+
+func TestRandomKeyGenerator(t *testing.T) {
+	gen := RandomKeyGenerator{Length: 8}
+
+	key, err := gen.Generate(context.Background(), "https://example.com", 0)
+	assert.NoError(t, err)
+	assert.Len(t, key, 8)
+	assert.Regexp(t, "^[0-9a-zA-Z]+$", key)
+
+	other, _ := gen.Generate(context.Background(), "https://example.com", 0)
+	assert.NotEqual(t, key, other)
+}
+
+func TestSequenceKeyGenerator(t *testing.T) {
+	sequence := new(mocks.KeySequence)
+	sequence.On("NextKeyID", mock.Anything).Return(int64(238328), nil).Once()
+	sequence.On("NextKeyID", mock.Anything).Return(int64(0), fmt.Errorf("db error")).Once()
+
+	gen := SequenceKeyGenerator{Sequence: sequence}
+
+	key, err := gen.Generate(context.Background(), "https://example.com", 0)
+	assert.NoError(t, err)
+	assert.Equal(t, "1000", key)
+
+	_, err = gen.Generate(context.Background(), "https://example.com", 1)
+	assert.Error(t, err)
+	sequence.AssertExpectations(t)
+}
+
+func TestEncodeBase62(t *testing.T) {
+	assert.Equal(t, "0", encodeBase62(0))
+	assert.Equal(t, "Z", encodeBase62(61))
+	assert.Equal(t, "10", encodeBase62(62))
+}
+
+func TestService_ShortenURL_RetriesUntilFreeKey(t *testing.T) {
+	ctx, repo, cache, _ := getMocksWithService()
+	svc := NewLinkService(ctx, repo, cache, nil, nil, WithKeyGenerator(RandomKeyGenerator{Length: 4}))
+
+	cache.On("GetShortLink", ctx, "https://busy.com").Return("", nil)
+	repo.On("FindByOriginalURL", ctx, "https://busy.com").Return(nil, nil)
+	repo.On("FindByShortLink", ctx, mock.Anything).Return("taken", nil).Times(attemptsPerLength)
+	repo.On("FindByShortLink", ctx, mock.Anything).Return("", nil).Once()
+
+	link, err := svc.ShortenURL(ctx, "https://busy.com", "https://localhost:8080", ShortenOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, link.ShortLink, 5, "после серии коллизий ключ должен удлиниться")
+	repo.AssertExpectations(t)
+}
+
+// constKeyGenerator всегда возвращает один и тот же ключ, чтобы воспроизводить коллизии.
+type constKeyGenerator struct {
+	key string
+}
+
+func (g constKeyGenerator) Generate(context.Context, string, int) (string, error) {
+	return g.key, nil
+}
@@ -0,0 +1,86 @@
+package service
+
+import "time"
+
+const (
+	defaultCleanupInterval  = 2 * time.Hour
+	defaultNegativeCacheTTL = 30 * time.Second
+)
+
+// Option настраивает Service при создании.
+type Option func(*Service)
+
+// WithDefaultTTL задаёт время жизни ссылок, созданных без явного срока; 0 — бессрочные ссылки.
+func WithDefaultTTL(ttl time.Duration) Option {
+	return func(s *Service) {
+		s.defaultTTL = ttl
+	}
+}
+
+// WithCleanupInterval задаёт период удаления ссылок с истёкшим сроком действия.
+func WithCleanupInterval(interval time.Duration) Option {
+	return func(s *Service) {
+		if interval > 0 {
+			s.cleanupInterval = interval
+		}
+	}
+}
+
+// WithNegativeCacheTTL задаёт, сколько кэшируется отсутствие ссылки с запрошенным ключом.
+func WithNegativeCacheTTL(ttl time.Duration) Option {
+	return func(s *Service) {
+		if ttl > 0 {
+			s.negativeCacheTTL = ttl
+		}
+	}
+}
+
+// WithKeyGenerator задаёт стратегию генерации ключей коротких ссылок.
+func WithKeyGenerator(gen KeyGenerator) Option {
+	return func(s *Service) {
+		if gen != nil {
+			s.keyGen = gen
+		}
+	}
+}
+
+// WithKeyMaxAttempts задаёт число попыток генерации ключа при коллизиях.
+func WithKeyMaxAttempts(attempts int) Option {
+	return func(s *Service) {
+		if attempts > 0 {
+			s.keyMaxAttempts = attempts
+		}
+	}
+}
+
+// WithBatchMaxItems ограничивает число URL в одном пакетном запросе.
+func WithBatchMaxItems(n int) Option {
+	return func(s *Service) {
+		if n > 0 {
+			s.batchMaxItems = n
+		}
+	}
+}
+
+// WithDefaultRedirectCode задаёт код перенаправления для ссылок, созданных без явного кода.
+func WithDefaultRedirectCode(code int) Option {
+	return func(s *Service) {
+		if validateRedirectCode(code) == nil {
+			s.redirectCode = code
+		}
+	}
+}
+
+// WithPageTitles включает загрузку заголовков страниц для страницы предпросмотра.
+func WithPageTitles(titles *PageTitles) Option {
+	return func(s *Service) {
+		s.titles = titles
+	}
+}
+
+// WithDestinationPolicy включает проверку адресов назначения при создании и изменении ссылок.
+func WithDestinationPolicy(policy *DestinationPolicy) Option {
+	return func(s *Service) {
+		s.policy = policy
+	}
+}
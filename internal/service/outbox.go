@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/IBM/sarama"
+	"github.com/sirupsen/logrus"
+	"linkreduction/internal/models"
+	initprometheus "linkreduction/internal/prometheus"
+	"time"
+)
+
+const (
+	defaultOutboxInterval  = time.Second
+	defaultOutboxBatchSize = 100
+)
+
+// OutboxRelay публикует в Kafka сообщения, записанные в outbox вместе с данными. Сообщение
+// удаляется только после подтверждения Kafka, поэтому доставка выполняется хотя бы один раз.
+type OutboxRelay struct {
+	ctx       context.Context
+	repo      OutboxRepo
+	producer  sarama.SyncProducer
+	metrics   *initprometheus.PrometheusMetrics
+	interval  time.Duration
+	batchSize int
+}
+
+func NewOutboxRelay(ctx context.Context, repo OutboxRepo, producer sarama.SyncProducer, metrics *initprometheus.PrometheusMetrics,
+	interval time.Duration, batchSize int) *OutboxRelay {
+
+	if interval <= 0 {
+		interval = defaultOutboxInterval
+	}
+	if batchSize <= 0 {
+		batchSize = defaultOutboxBatchSize
+	}
+	return &OutboxRelay{ctx: ctx, repo: repo, producer: producer, metrics: metrics, interval: interval, batchSize: batchSize}
+}
+
+// Run опрашивает outbox до отмены контекста. Пока выбирается полная пачка, следующая
+// запрашивается сразу, не дожидаясь таймера.
+func (r *OutboxRelay) Run(logger *logrus.Logger) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			for {
+				n, err := r.RelayOnce(r.ctx)
+				if err != nil {
+					logger.WithField("component", "outbox").Error(err)
+					break
+				}
+				if n < r.batchSize {
+					break
+				}
+			}
+		case <-r.ctx.Done():
+			return
+		}
+	}
+}
+
+// RelayOnce публикует одну пачку сообщений outbox и возвращает их число.
+func (r *OutboxRelay) RelayOnce(ctx context.Context) (int, error) {
+	n, err := r.repo.ProcessOutbox(ctx, r.batchSize, r.publish)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка публикации outbox: %w", err)
+	}
+	r.count("published", n)
+	return n, nil
+}
+
+func (r *OutboxRelay) publish(messages []models.OutboxMessage) error {
+	msgs := make([]*sarama.ProducerMessage, 0, len(messages))
+	for _, m := range messages {
+		msg := &sarama.ProducerMessage{Topic: m.Topic, Value: sarama.ByteEncoder(m.Payload)}
+		if m.Key != "" {
+			msg.Key = sarama.StringEncoder(m.Key)
+		}
+		msgs = append(msgs, msg)
+	}
+
+	if err := r.producer.SendMessages(msgs); err != nil {
+		r.count("error", len(messages))
+		return wrapf(ErrUnavailable, "ошибка отправки в Kafka: %v", err)
+	}
+	return nil
+}
+
+func (r *OutboxRelay) count(status string, n int) {
+	if n > 0 && r.metrics != nil && r.metrics.OutboxMessagesTotal != nil {
+		r.metrics.OutboxMessagesTotal.WithLabelValues(status).Add(float64(n))
+	}
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"linkreduction/internal/mocks"
+	"linkreduction/internal/models"
+	"testing"
+
+	"github.com/IBM/sarama"
+	saramamocks "github.com/IBM/sarama/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestOutboxRelay_RelayOnce(t *testing.T) {
+	messages := []models.OutboxMessage{
+		{ID: 1, Topic: "shorten-urls", Key: "a1", Payload: []byte(`{"short_link":"a1"}`)},
+		{ID: 2, Topic: "shorten-urls", Key: "b2", Payload: []byte(`{"short_link":"b2"}`)},
+	}
+
+	// processOutbox передаёт сообщения publish и, как репозиторий, возвращает её ошибку
+	processOutbox := func(repo *mocks.OutboxRepo) *mock.Call {
+		var publishErr error
+		return repo.On("ProcessOutbox", mock.Anything, 10, mock.Anything).
+			Run(func(args mock.Arguments) {
+				publishErr = args.Get(2).(func([]models.OutboxMessage) error)(messages)
+			}).
+			Return(func(context.Context, int, func([]models.OutboxMessage) error) (int, error) {
+				if publishErr != nil {
+					return 0, publishErr
+				}
+				return len(messages), nil
+			})
+	}
+
+	t.Run("publishes messages with keys", func(t *testing.T) {
+		repo := new(mocks.OutboxRepo)
+		producer := saramamocks.NewSyncProducer(t, nil)
+		relay := NewOutboxRelay(context.Background(), repo, producer, nil, 0, 10)
+
+		var keys []string
+		for range messages {
+			producer.ExpectSendMessageWithMessageCheckerFunctionAndSucceed(func(msg *sarama.ProducerMessage) error {
+				key, _ := msg.Key.Encode()
+				keys = append(keys, string(key))
+				return nil
+			})
+		}
+		processOutbox(repo)
+
+		n, err := relay.RelayOnce(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, 2, n)
+		assert.Equal(t, []string{"a1", "b2"}, keys)
+		assert.NoError(t, producer.Close())
+	})
+
+	t.Run("kafka failure keeps messages in outbox", func(t *testing.T) {
+		repo := new(mocks.OutboxRepo)
+		producer := saramamocks.NewSyncProducer(t, nil)
+		relay := NewOutboxRelay(context.Background(), repo, producer, nil, 0, 10)
+
+		producer.ExpectSendMessageAndFail(fmt.Errorf("broker down"))
+		producer.ExpectSendMessageAndSucceed()
+		processOutbox(repo)
+
+		n, err := relay.RelayOnce(context.Background())
+		assert.ErrorIs(t, err, ErrUnavailable)
+		assert.Zero(t, n)
+		assert.NoError(t, producer.Close())
+	})
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"golang.org/x/crypto/bcrypt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	minLinkPasswordLength = 4
+	// bcrypt учитывает только первые 72 байта пароля
+	maxLinkPasswordLength = 72
+
+	defaultAccessTTL = 24 * time.Hour
+)
+
+func hashLinkPassword(password string) (string, error) {
+	if len(password) < minLinkPasswordLength || len(password) > maxLinkPasswordLength {
+		return "", wrapf(ErrInvalidPassword, "должен содержать от %d до %d байт", minLinkPasswordLength, maxLinkPasswordLength)
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("ошибка хэширования пароля: %w", err)
+	}
+	return string(hash), nil
+}
+
+// VerifyLinkPassword сравнивает введённый пароль с хэшем пароля ссылки.
+func VerifyLinkPassword(passwordHash, password string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(passwordHash), []byte(password)) == nil
+}
+
+// AccessSigner подписывает cookie доступа к защищённым паролем ссылкам. Подпись
+// зависит от хэша пароля, поэтому смена пароля отзывает выданные cookie.
+type AccessSigner struct {
+	secret []byte
+	ttl    time.Duration
+}
+
+// NewAccessSigner создаёт подписчик; при пустом secret используется случайный ключ,
+// и cookie перестают действовать после перезапуска.
+func NewAccessSigner(secret string, ttl time.Duration) (*AccessSigner, error) {
+	key := []byte(secret)
+	if len(key) == 0 {
+		key = make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			return nil, fmt.Errorf("ошибка генерации ключа подписи: %w", err)
+		}
+	}
+	if ttl <= 0 {
+		ttl = defaultAccessTTL
+	}
+	return &AccessSigner{secret: key, ttl: ttl}, nil
+}
+
+// Sign возвращает значение cookie доступа к ссылке и момент его истечения.
+func (s *AccessSigner) Sign(shortLink, passwordHash string, now time.Time) (string, time.Time) {
+	expires := now.Add(s.ttl)
+	exp := strconv.FormatInt(expires.Unix(), 10)
+	return exp + "." + s.mac(shortLink, passwordHash, exp), expires
+}
+
+// Verify проверяет подпись и срок действия cookie доступа.
+func (s *AccessSigner) Verify(value, shortLink, passwordHash string, now time.Time) bool {
+	exp, sig, ok := strings.Cut(value, ".")
+	if !ok {
+		return false
+	}
+	unix, err := strconv.ParseInt(exp, 10, 64)
+	if err != nil || now.Unix() >= unix {
+		return false
+	}
+	return hmac.Equal([]byte(sig), []byte(s.mac(shortLink, passwordHash, exp)))
+}
+
+func (s *AccessSigner) mac(shortLink, passwordHash, exp string) string {
+	m := hmac.New(sha256.New, s.secret)
+	m.Write([]byte(shortLink + "\x00" + passwordHash + "\x00" + exp))
+	return base64.RawURLEncoding.EncodeToString(m.Sum(nil))
+}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestHashLinkPassword(t *testing.T) {
+	hash, err := hashLinkPassword("secret")
+	assert.NoError(t, err)
+	assert.NotContains(t, hash, "secret")
+	assert.True(t, VerifyLinkPassword(hash, "secret"))
+	assert.False(t, VerifyLinkPassword(hash, "Secret"))
+
+	_, err = hashLinkPassword("abc")
+	assert.Error(t, err)
+	_, err = hashLinkPassword(strings.Repeat("a", maxLinkPasswordLength+1))
+	assert.Error(t, err)
+}
+
+func TestAccessSigner(t *testing.T) {
+	signer, err := NewAccessSigner("cookie-secret", time.Hour)
+	assert.NoError(t, err)
+
+	now := time.Now()
+	value, expires := signer.Sign("docs", "hash1", now)
+	assert.Equal(t, now.Add(time.Hour), expires)
+
+	assert.True(t, signer.Verify(value, "docs", "hash1", now))
+	assert.False(t, signer.Verify(value, "other", "hash1", now), "cookie другой ссылки")
+	assert.False(t, signer.Verify(value, "docs", "hash2", now), "пароль ссылки сменился")
+	assert.False(t, signer.Verify(value, "docs", "hash1", now.Add(2*time.Hour)), "срок cookie истёк")
+	assert.False(t, signer.Verify(value+"x", "docs", "hash1", now))
+	assert.False(t, signer.Verify("garbage", "docs", "hash1", now))
+
+	other, err := NewAccessSigner("another-secret", time.Hour)
+	assert.NoError(t, err)
+	assert.False(t, other.Verify(value, "docs", "hash1", now), "подпись другим ключом")
+}
+
+func TestService_ShortenURL_Password(t *testing.T) {
+	ctx, repo, cache, svc := getMocksWithService()
+
+	repo.On("FindByShortLink", ctx, mock.Anything).Return("", nil)
+
+	link, err := svc.ShortenURL(ctx, "https://example.com/doc", "https://localhost:8080", ShortenOptions{Password: "secret"})
+	assert.NoError(t, err)
+	assert.True(t, link.Custom, "защищённая ссылка не должна переиспользоваться")
+	assert.True(t, VerifyLinkPassword(link.PasswordHash, "secret"))
+
+	_, err = svc.ShortenURL(ctx, "https://example.com/doc", "https://localhost:8080", ShortenOptions{Password: "123"})
+	assert.Error(t, err)
+
+	cache.AssertNotCalled(t, "GetShortLink", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,370 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	defaultPolicyMaxRedirects      = 5
+	defaultPolicyRedirectTimeout   = 5 * time.Second
+	defaultBlocklistReloadInterval = time.Minute
+	defaultPolicyBatchCheckBudget  = 30 * time.Second
+)
+
+// ErrDestinationBlocked возвращается, если адрес назначения запрещён политикой.
+var ErrDestinationBlocked = newError(ErrInvalidURL, "destination_blocked", "адрес назначения запрещён")
+
+// defaultShorteners используются, если список сокращателей не задан в конфигурации.
+var defaultShorteners = []string{
+	"bit.ly", "tinyurl.com", "t.co", "goo.gl", "ow.ly", "is.gd", "buff.ly", "cutt.ly",
+	"rebrand.ly", "shorturl.at", "tiny.cc", "rb.gy", "clck.ru", "v.gd", "s.id",
+}
+
+// PolicyRules описывает ограничения на адреса назначения коротких ссылок.
+type PolicyRules struct {
+	// AllowIPLiterals разрешает ссылки вида http://203.0.113.7/.
+	AllowIPLiterals bool
+	// AllowPrivateNetworks разрешает адреса локальных и частных сетей.
+	AllowPrivateNetworks bool
+	// ResolveHosts проверяет IP-адреса, в которые разрешается домен.
+	ResolveHosts bool
+	// BlockedDomains запрещает домены вместе с поддоменами.
+	BlockedDomains []string
+	// AllowedDomains, если задан, разрешает только перечисленные домены и их поддомены.
+	AllowedDomains []string
+	// BlocklistFile — файл с запрещёнными доменами (по одному в строке), перечитывается при изменении.
+	BlocklistFile           string
+	BlocklistReloadInterval time.Duration
+	// Shorteners — домены других сервисов сокращения ссылок.
+	Shorteners []string
+	// FollowRedirects проходит по цепочке перенаправлений адреса и проверяет каждый переход.
+	FollowRedirects bool
+	MaxRedirects    int
+	RedirectTimeout time.Duration
+	// BatchCheckBudget — общее время сетевых проверок (DNS и перенаправления) одного пакета.
+	// Адреса, до которых очередь не дошла, проверяются только по правилам для домена.
+	BatchCheckBudget time.Duration
+}
+
+// DestinationPolicy проверяет адреса назначения перед созданием и изменением ссылок.
+type DestinationPolicy struct {
+	rules      PolicyRules
+	blocked    domainSet
+	allowed    domainSet
+	shorteners domainSet
+	resolver   *net.Resolver
+	client     *http.Client
+
+	mu          sync.RWMutex
+	fileBlocked domainSet
+	fileModTime time.Time
+}
+
+func NewDestinationPolicy(rules PolicyRules) (*DestinationPolicy, error) {
+	if rules.MaxRedirects <= 0 {
+		rules.MaxRedirects = defaultPolicyMaxRedirects
+	}
+	if rules.RedirectTimeout <= 0 {
+		rules.RedirectTimeout = defaultPolicyRedirectTimeout
+	}
+	if rules.BlocklistReloadInterval <= 0 {
+		rules.BlocklistReloadInterval = defaultBlocklistReloadInterval
+	}
+	if rules.BatchCheckBudget <= 0 {
+		rules.BatchCheckBudget = defaultPolicyBatchCheckBudget
+	}
+	if rules.Shorteners == nil {
+		rules.Shorteners = defaultShorteners
+	}
+
+	p := &DestinationPolicy{
+		rules:      rules,
+		blocked:    newDomainSet(rules.BlockedDomains),
+		allowed:    newDomainSet(rules.AllowedDomains),
+		shorteners: newDomainSet(rules.Shorteners),
+		resolver:   net.DefaultResolver,
+	}
+
+	p.client = newOutboundClient(rules.RedirectTimeout, rules.AllowPrivateNetworks)
+	p.client.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+
+	if rules.BlocklistFile != "" {
+		if _, err := p.reloadBlocklist(); err != nil {
+			return nil, err
+		}
+	}
+
+	return p, nil
+}
+
+// Check возвращает ErrDestinationBlocked с причиной, если ссылку на адрес создавать нельзя.
+//
+// Политика запрещает только то, что удалось проверить: ошибки DNS и сети, в том числе истёкший ctx,
+// не считаются нарушением. Ссылка на адрес, который временно недоступен, создаётся, а подключиться
+// к частной сети при проверке перенаправлений не даёт сам HTTP-клиент.
+func (p *DestinationPolicy) Check(ctx context.Context, rawURL string) error {
+	if err := p.CheckHost(ctx, rawURL); err != nil {
+		return err
+	}
+	return p.CheckRedirects(ctx, rawURL)
+}
+
+// CheckHost проверяет домен адреса по спискам политики и, если включено ResolveHosts, его IP-адреса.
+func (p *DestinationPolicy) CheckHost(ctx context.Context, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ErrInvalidURL
+	}
+	return p.checkHost(ctx, u.Hostname())
+}
+
+// CheckRedirects проходит по цепочке перенаправлений адреса, если включено FollowRedirects.
+// Это самая дорогая проверка, поэтому она выполняется только для действительно новых ссылок.
+func (p *DestinationPolicy) CheckRedirects(ctx context.Context, rawURL string) error {
+	if !p.rules.FollowRedirects {
+		return nil
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ErrInvalidURL
+	}
+	return p.checkRedirects(ctx, u)
+}
+
+// batchContext ограничивает сетевые проверки одного пакета временем BatchCheckBudget.
+func (p *DestinationPolicy) batchContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, p.rules.BatchCheckBudget)
+}
+
+func (p *DestinationPolicy) checkHost(ctx context.Context, host string) error {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+
+	if ip := net.ParseIP(host); ip != nil {
+		if !p.rules.AllowIPLiterals {
+			return fmt.Errorf("%w: ссылки на IP-адреса запрещены", ErrDestinationBlocked)
+		}
+		if !p.rules.AllowPrivateNetworks && isPrivateIP(ip) {
+			return fmt.Errorf("%w: ссылки на адреса частных сетей запрещены", ErrDestinationBlocked)
+		}
+		return nil
+	}
+
+	if !p.rules.AllowPrivateNetworks && isLocalHostname(host) {
+		return fmt.Errorf("%w: ссылки на адреса частных сетей запрещены", ErrDestinationBlocked)
+	}
+	if len(p.allowed) > 0 && !p.allowed.match(host) {
+		return fmt.Errorf("%w: домен %s не входит в список разрешённых", ErrDestinationBlocked, host)
+	}
+	if p.blocked.match(host) || p.fileBlockedMatch(host) {
+		return fmt.Errorf("%w: домен %s заблокирован", ErrDestinationBlocked, host)
+	}
+	if p.shorteners.match(host) {
+		return fmt.Errorf("%w: ссылки на другие сервисы сокращения (%s) запрещены", ErrDestinationBlocked, host)
+	}
+
+	if p.rules.ResolveHosts && !p.rules.AllowPrivateNetworks {
+		addrs, err := p.resolver.LookupIPAddr(ctx, host)
+		if err != nil {
+			return nil
+		}
+		for _, addr := range addrs {
+			if isPrivateIP(addr.IP) {
+				return fmt.Errorf("%w: домен %s указывает на адрес частной сети", ErrDestinationBlocked, host)
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkRedirects проходит по цепочке перенаправлений и проверяет каждый следующий адрес
+// до запроса к нему.
+func (p *DestinationPolicy) checkRedirects(ctx context.Context, u *url.URL) error {
+	for hop := 0; hop < p.rules.MaxRedirects; hop++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodHead, u.String(), nil)
+		if err != nil {
+			return nil
+		}
+		resp, err := p.client.Do(req)
+		if err != nil {
+			return nil
+		}
+		_ = resp.Body.Close()
+
+		location := resp.Header.Get("Location")
+		if resp.StatusCode < 300 || resp.StatusCode >= 400 || location == "" {
+			return nil
+		}
+
+		next, err := u.Parse(location)
+		if err != nil {
+			return nil
+		}
+		if next.Scheme != "http" && next.Scheme != "https" {
+			return fmt.Errorf("%w: перенаправление на недопустимую схему %s", ErrDestinationBlocked, next.Scheme)
+		}
+		if err := p.checkHost(ctx, next.Hostname()); err != nil {
+			return fmt.Errorf("цепочка перенаправлений ведёт на %s: %w", next.Host, err)
+		}
+		u = next
+	}
+
+	return fmt.Errorf("%w: слишком длинная цепочка перенаправлений", ErrDestinationBlocked)
+}
+
+// WatchBlocklist перечитывает файл блок-листа при изменении, пока не отменён ctx.
+func (p *DestinationPolicy) WatchBlocklist(ctx context.Context, logger *logrus.Logger) {
+	if p.rules.BlocklistFile == "" {
+		return
+	}
+
+	ticker := time.NewTicker(p.rules.BlocklistReloadInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			reloaded, err := p.reloadBlocklist()
+			if err != nil {
+				logger.WithField("file", p.rules.BlocklistFile).Error(err)
+				continue
+			}
+			if reloaded {
+				logger.WithField("file", p.rules.BlocklistFile).Info("Блок-лист доменов перечитан")
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// reloadBlocklist читает файл блок-листа, если он изменился с прошлого чтения.
+// При ошибке продолжает действовать предыдущая версия списка.
+func (p *DestinationPolicy) reloadBlocklist() (bool, error) {
+	info, err := os.Stat(p.rules.BlocklistFile)
+	if err != nil {
+		return false, fmt.Errorf("ошибка чтения блок-листа: %w", err)
+	}
+
+	p.mu.RLock()
+	unchanged := info.ModTime().Equal(p.fileModTime)
+	p.mu.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+
+	f, err := os.Open(p.rules.BlocklistFile)
+	if err != nil {
+		return false, fmt.Errorf("ошибка чтения блок-листа: %w", err)
+	}
+	defer f.Close()
+
+	var domains []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		if line = strings.TrimSpace(line); line != "" {
+			domains = append(domains, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return false, fmt.Errorf("ошибка чтения блок-листа: %w", err)
+	}
+
+	p.mu.Lock()
+	p.fileBlocked = newDomainSet(domains)
+	p.fileModTime = info.ModTime()
+	p.mu.Unlock()
+
+	return true, nil
+}
+
+func (p *DestinationPolicy) fileBlockedMatch(host string) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.fileBlocked.match(host)
+}
+
+// domainSet совпадает с доменом и всеми его поддоменами.
+type domainSet map[string]struct{}
+
+func newDomainSet(domains []string) domainSet {
+	set := make(domainSet, len(domains))
+	for _, d := range domains {
+		d = strings.Trim(strings.ToLower(strings.TrimSpace(d)), ".")
+		if d != "" {
+			set[d] = struct{}{}
+		}
+	}
+	return set
+}
+
+func (s domainSet) match(host string) bool {
+	for host != "" {
+		if _, ok := s[host]; ok {
+			return true
+		}
+		_, parent, found := strings.Cut(host, ".")
+		if !found {
+			return false
+		}
+		host = parent
+	}
+	return false
+}
+
+// newOutboundClient создаёт клиент для запросов к адресам пользователей. Без allowPrivate
+// подключение к частным адресам запрещено и для доменов, которые в них разрешаются.
+func newOutboundClient(timeout time.Duration, allowPrivate bool) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout}
+	if !allowPrivate {
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip != nil && isPrivateIP(ip) {
+				return fmt.Errorf("подключение к частному адресу %s запрещено", ip)
+			}
+			return nil
+		}
+	}
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: &http.Transport{DialContext: dialer.DialContext},
+	}
+}
+
+func isPrivateIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || sharedAddressSpace.Contains(ip)
+}
+
+// sharedAddressSpace — диапазон CGNAT (RFC 6598), не входящий в net.IP.IsPrivate.
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// isLocalHostname распознаёт имена, которые разрешаются только внутри локальной сети.
+func isLocalHostname(host string) bool {
+	if !strings.Contains(host, ".") {
+		return true
+	}
+	for _, suffix := range []string{".localhost", ".local", ".internal", ".lan", ".home.arpa"} {
+		if strings.HasSuffix(host, suffix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,194 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestDestinationPolicy_Check(t *testing.T) {
+	tests := []struct {
+		name      string
+		rules     PolicyRules
+		url       string
+		expectErr bool
+	}{
+		{name: "public domain", url: "https://example.com/page"},
+		{name: "IP literal", url: "http://203.0.113.7/", expectErr: true},
+		{name: "allowed public IP literal", rules: PolicyRules{AllowIPLiterals: true}, url: "http://203.0.113.7/"},
+		{name: "loopback", rules: PolicyRules{AllowIPLiterals: true}, url: "http://127.0.0.1:8080/", expectErr: true},
+		{name: "private network", rules: PolicyRules{AllowIPLiterals: true}, url: "http://10.1.2.3/", expectErr: true},
+		{name: "IPv6 loopback", rules: PolicyRules{AllowIPLiterals: true}, url: "http://[::1]/", expectErr: true},
+		{name: "CGNAT", rules: PolicyRules{AllowIPLiterals: true}, url: "http://100.64.0.1/", expectErr: true},
+		{name: "private network allowed", rules: PolicyRules{AllowIPLiterals: true, AllowPrivateNetworks: true}, url: "http://10.1.2.3/"},
+		{name: "localhost", url: "http://localhost:8080/", expectErr: true},
+		{name: "single-label host", url: "http://intranet/", expectErr: true},
+		{name: "internal zone", url: "http://db.internal/", expectErr: true},
+		{name: "blocked domain", rules: PolicyRules{BlockedDomains: []string{"phish.example"}}, url: "https://phish.example/login", expectErr: true},
+		{name: "blocked subdomain", rules: PolicyRules{BlockedDomains: []string{"phish.example"}}, url: "https://login.PHISH.example./", expectErr: true},
+		{name: "similar domain is not blocked", rules: PolicyRules{BlockedDomains: []string{"phish.example"}}, url: "https://notphish.example/"},
+		{name: "allowlist match", rules: PolicyRules{AllowedDomains: []string{"example.com"}}, url: "https://docs.example.com/"},
+		{name: "allowlist miss", rules: PolicyRules{AllowedDomains: []string{"example.com"}}, url: "https://example.org/", expectErr: true},
+		{name: "default shortener", url: "https://bit.ly/abc", expectErr: true},
+		{name: "custom shortener list", rules: PolicyRules{Shorteners: []string{"sho.rt"}}, url: "https://bit.ly/abc"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy, err := NewDestinationPolicy(tt.rules)
+			assert.NoError(t, err)
+
+			err = policy.Check(context.Background(), tt.url)
+			if tt.expectErr {
+				assert.ErrorIs(t, err, ErrDestinationBlocked)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestDestinationPolicy_RedirectChain(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer target.Close()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ok", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, target.URL, http.StatusFound)
+	})
+	mux.HandleFunc("/shortener", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "https://bit.ly/abc", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/loop", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/loop", http.StatusFound)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	// Тестовые серверы слушают 127.0.0.1, поэтому частные адреса разрешены
+	policy, err := NewDestinationPolicy(PolicyRules{AllowIPLiterals: true, AllowPrivateNetworks: true, FollowRedirects: true})
+	assert.NoError(t, err)
+
+	ctx := context.Background()
+	assert.NoError(t, policy.Check(ctx, server.URL+"/ok"))
+	assert.ErrorIs(t, policy.Check(ctx, server.URL+"/shortener"), ErrDestinationBlocked)
+	assert.ErrorIs(t, policy.Check(ctx, server.URL+"/loop"), ErrDestinationBlocked)
+}
+
+func TestDestinationPolicy_BlocklistReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "blocklist.txt")
+	assert.NoError(t, os.WriteFile(path, []byte("# фишинг\nbad.example\n\n"), 0o644))
+
+	policy, err := NewDestinationPolicy(PolicyRules{BlocklistFile: path})
+	assert.NoError(t, err)
+
+	ctx := context.Background()
+	assert.ErrorIs(t, policy.Check(ctx, "https://www.bad.example/"), ErrDestinationBlocked)
+	assert.NoError(t, policy.Check(ctx, "https://worse.example/"))
+
+	assert.NoError(t, os.WriteFile(path, []byte("worse.example # новый домен\n"), 0o644))
+	modTime := time.Now().Add(time.Minute)
+	assert.NoError(t, os.Chtimes(path, modTime, modTime))
+
+	reloaded, err := policy.reloadBlocklist()
+	assert.NoError(t, err)
+	assert.True(t, reloaded)
+	assert.NoError(t, policy.Check(ctx, "https://www.bad.example/"))
+	assert.ErrorIs(t, policy.Check(ctx, "https://worse.example/"), ErrDestinationBlocked)
+
+	reloaded, err = policy.reloadBlocklist()
+	assert.NoError(t, err)
+	assert.False(t, reloaded, "неизменённый файл не перечитывается")
+}
+
+func TestService_ShortenURL_Policy(t *testing.T) {
+	ctx, repo, cache, _ := getMocksWithService()
+	policy, err := NewDestinationPolicy(PolicyRules{BlockedDomains: []string{"phish.example"}})
+	assert.NoError(t, err)
+	svc := NewLinkService(ctx, repo, cache, nil, nil, WithDestinationPolicy(policy))
+
+	_, err = svc.ShortenURL(ctx, "https://phish.example/login", "https://localhost:8080", ShortenOptions{})
+	assert.ErrorIs(t, err, ErrDestinationBlocked)
+
+	_, err = svc.UpdateOriginalURL(ctx, "team", "abc123", "https://phish.example/login", "https://localhost:8080")
+	assert.ErrorIs(t, err, ErrDestinationBlocked)
+
+	repo.AssertNotCalled(t, "FindByOriginalURL", mock.Anything, mock.Anything)
+	repo.AssertNotCalled(t, "FindLink", mock.Anything, mock.Anything)
+	cache.AssertNotCalled(t, "GetShortLink", mock.Anything, mock.Anything)
+}
+
+func TestDestinationPolicy_FailsOpenOnDNSErrors(t *testing.T) {
+	policy, err := NewDestinationPolicy(PolicyRules{ResolveHosts: true})
+	assert.NoError(t, err)
+	policy.resolver = &net.Resolver{PreferGo: true, Dial: func(context.Context, string, string) (net.Conn, error) {
+		return nil, errors.New("dns down")
+	}}
+
+	assert.NoError(t, policy.Check(context.Background(), "https://unresolvable.example/"))
+}
+
+func TestService_RedirectChainCheckedOnlyForNewLinks(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		http.Redirect(w, r, "https://bit.ly/abc", http.StatusFound)
+	}))
+	defer server.Close()
+
+	policy, err := NewDestinationPolicy(PolicyRules{AllowIPLiterals: true, AllowPrivateNetworks: true, FollowRedirects: true})
+	assert.NoError(t, err)
+
+	t.Run("existing shared link skips the chain", func(t *testing.T) {
+		ctx, repo, cache, _ := getMocksWithService()
+		svc := NewLinkService(ctx, repo, cache, nil, nil, WithDestinationPolicy(policy))
+		cache.On("GetShortLink", ctx, server.URL).Return("abc123", nil)
+
+		link, err := svc.ShortenURL(ctx, server.URL, "https://localhost:8080", ShortenOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, "abc123", link.ShortLink)
+		assert.Equal(t, int32(0), hits.Load())
+	})
+
+	t.Run("new link follows the chain", func(t *testing.T) {
+		ctx, repo, cache, _ := getMocksWithService()
+		svc := NewLinkService(ctx, repo, cache, nil, nil, WithDestinationPolicy(policy))
+		cache.On("GetShortLink", ctx, server.URL).Return("", nil)
+		repo.On("FindByOriginalURL", ctx, server.URL).Return(nil, nil)
+
+		_, err := svc.ShortenURL(ctx, server.URL, "https://localhost:8080", ShortenOptions{})
+		assert.ErrorIs(t, err, ErrDestinationBlocked)
+		assert.Equal(t, int32(1), hits.Load())
+	})
+
+	t.Run("exhausted batch budget skips the chain", func(t *testing.T) {
+		policy, err := NewDestinationPolicy(PolicyRules{AllowIPLiterals: true, AllowPrivateNetworks: true, FollowRedirects: true,
+			BatchCheckBudget: time.Nanosecond})
+		assert.NoError(t, err)
+		ctx, repo, cache, _ := getMocksWithService()
+		svc := NewLinkService(ctx, repo, cache, nil, nil, WithDestinationPolicy(policy))
+		cache.On("GetShortLink", ctx, server.URL).Return("", nil)
+		repo.On("FindByOriginalURL", ctx, server.URL).Return(nil, nil)
+		repo.On("FindByShortLink", ctx, mock.Anything).Return("", nil)
+		repo.On("SaveLinks", ctx, mock.Anything, false).Return(saveAsIs)
+		cache.On("DeleteRedirects", ctx, mock.Anything).Return(nil)
+		cache.On("SetShortLink", ctx, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+		results, err := svc.ShortenBatch(ctx, []BatchItem{{URL: server.URL}}, "https://localhost:8080")
+		assert.NoError(t, err)
+		assert.NoError(t, results[0].Err)
+		assert.Equal(t, int32(1), hits.Load())
+	})
+}
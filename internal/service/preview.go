@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"html"
+	"io"
+	"mime"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	defaultTitleTimeout = 3 * time.Second
+	titleCacheTTL       = time.Hour
+	// заголовок ищется только в начале страницы
+	maxTitleBodyBytes = 64 << 10
+	maxTitleLength    = 200
+)
+
+var titlePattern = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+
+// PageTitles загружает заголовки страниц назначения для страницы предпросмотра.
+// Результат, в том числе пустой, кэшируется, чтобы не обращаться к сайту при каждом показе.
+type PageTitles struct {
+	cache  TitleCache
+	client *http.Client
+}
+
+// NewPageTitles создаёт загрузчик заголовков; cache может быть nil — тогда заголовки не кэшируются.
+func NewPageTitles(cache TitleCache, timeout time.Duration, allowPrivateNetworks bool) *PageTitles {
+	if cache == nil {
+		cache = nopCache{}
+	}
+	if timeout <= 0 {
+		timeout = defaultTitleTimeout
+	}
+	return &PageTitles{cache: cache, client: newOutboundClient(timeout, allowPrivateNetworks)}
+}
+
+// Title возвращает заголовок страницы или пустую строку, если его не удалось получить.
+func (p *PageTitles) Title(ctx context.Context, pageURL string) string {
+	if title, ok, err := p.cache.GetTitle(ctx, pageURL); err == nil && ok {
+		return title
+	}
+
+	title := p.fetch(ctx, pageURL)
+	_ = p.cache.SetTitle(ctx, pageURL, title, titleCacheTTL)
+	return title
+}
+
+func (p *PageTitles) fetch(ctx context.Context, pageURL string) string {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, nil)
+	if err != nil {
+		return ""
+	}
+	req.Header.Set("Accept", "text/html")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return ""
+	}
+	defer resp.Body.Close()
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if resp.StatusCode != http.StatusOK || (mediaType != "text/html" && mediaType != "application/xhtml+xml") {
+		return ""
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxTitleBodyBytes))
+	if err != nil {
+		return ""
+	}
+	return extractTitle(body)
+}
+
+func extractTitle(body []byte) string {
+	m := titlePattern.FindSubmatch(body)
+	if m == nil || !utf8.Valid(m[1]) {
+		return ""
+	}
+
+	title := strings.Join(strings.Fields(html.UnescapeString(string(m[1]))), " ")
+	if utf8.RuneCountInString(title) > maxTitleLength {
+		title = string([]rune(title)[:maxTitleLength]) + "…"
+	}
+	return title
+}
+
+// PageTitle возвращает заголовок страницы назначения, если загрузка заголовков включена.
+func (s *Service) PageTitle(ctx context.Context, pageURL string) string {
+	if s.titles == nil {
+		return ""
+	}
+	return s.titles.Title(ctx, pageURL)
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"linkreduction/internal/mocks"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestExtractTitle(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		expected string
+	}{
+		{name: "simple", body: "<html><head><title>Example</title></head></html>", expected: "Example"},
+		{name: "attributes and case", body: `<TITLE lang="ru">Пример</TITLE>`, expected: "Пример"},
+		{name: "entities and whitespace", body: "<title>\n  Tom &amp; Jerry\n\t</title>", expected: "Tom & Jerry"},
+		{name: "no title", body: "<html><body>hi</body></html>", expected: ""},
+		{name: "long title", body: "<title>" + strings.Repeat("a", maxTitleLength+10) + "</title>", expected: strings.Repeat("a", maxTitleLength) + "…"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, extractTitle([]byte(tt.body)))
+		})
+	}
+}
+
+func TestPageTitles_Title(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/page":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			_, _ = w.Write([]byte("<title>Страница</title>"))
+		case "/file":
+			w.Header().Set("Content-Type", "application/pdf")
+			_, _ = w.Write([]byte("<title>not html</title>"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+
+	t.Run("fetches and caches title", func(t *testing.T) {
+		cache := new(mocks.TitleCache)
+		cache.On("GetTitle", ctx, server.URL+"/page").Return("", false, nil)
+		cache.On("SetTitle", ctx, server.URL+"/page", "Страница", titleCacheTTL).Return(nil)
+
+		// Тестовый сервер слушает 127.0.0.1, поэтому частные адреса разрешены
+		titles := NewPageTitles(cache, time.Second, true)
+		assert.Equal(t, "Страница", titles.Title(ctx, server.URL+"/page"))
+		cache.AssertExpectations(t)
+	})
+
+	t.Run("cached title", func(t *testing.T) {
+		cache := new(mocks.TitleCache)
+		cache.On("GetTitle", ctx, server.URL+"/page").Return("Из кэша", true, nil)
+
+		titles := NewPageTitles(cache, time.Second, true)
+		assert.Equal(t, "Из кэша", titles.Title(ctx, server.URL+"/page"))
+		cache.AssertNotCalled(t, "SetTitle", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("non-HTML and missing pages cache empty title", func(t *testing.T) {
+		cache := new(mocks.TitleCache)
+		cache.On("GetTitle", ctx, mock.Anything).Return("", false, nil)
+		cache.On("SetTitle", ctx, mock.Anything, "", titleCacheTTL).Return(nil)
+
+		titles := NewPageTitles(cache, time.Second, true)
+		assert.Equal(t, "", titles.Title(ctx, server.URL+"/file"))
+		assert.Equal(t, "", titles.Title(ctx, server.URL+"/missing"))
+		cache.AssertNumberOfCalls(t, "SetTitle", 2)
+	})
+
+	t.Run("private networks are not fetched", func(t *testing.T) {
+		cache := new(mocks.TitleCache)
+		cache.On("GetTitle", ctx, server.URL+"/page").Return("", false, nil)
+		cache.On("SetTitle", ctx, server.URL+"/page", "", titleCacheTTL).Return(nil)
+
+		titles := NewPageTitles(cache, time.Second, false)
+		assert.Equal(t, "", titles.Title(ctx, server.URL+"/page"))
+	})
+}
+
+func TestService_ShortenURL_Interstitial(t *testing.T) {
+	ctx, repo, cache, svc := getMocksWithService()
+
+	repo.On("FindByShortLink", ctx, mock.Anything).Return("", nil)
+
+	link, err := svc.ShortenURL(ctx, "https://example.com", "https://localhost:8080", ShortenOptions{Interstitial: true})
+	assert.NoError(t, err)
+	assert.True(t, link.Interstitial)
+	assert.True(t, link.Custom, "ссылка с предпросмотром не должна переиспользоваться")
+
+	cache.AssertNotCalled(t, "GetShortLink", mock.Anything, mock.Anything)
+}
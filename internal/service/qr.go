@@ -0,0 +1,160 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/skip2/go-qrcode"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+)
+
+const (
+	QRFormatPNG = "png"
+	QRFormatSVG = "svg"
+
+	defaultQRSize   = 256
+	minQRSize       = 64
+	maxQRSize       = 2048
+	defaultQRMargin = 4
+	maxQRMargin     = 16
+)
+
+var qrLevels = map[string]qrcode.RecoveryLevel{
+	"L": qrcode.Low,
+	"M": qrcode.Medium,
+	"Q": qrcode.High,
+	"H": qrcode.Highest,
+}
+
+// QROptions — параметры QR-кода. Нулевые значения заменяются значениями по умолчанию:
+// PNG 256×256, поле в 4 модуля, уровень коррекции ошибок M.
+type QROptions struct {
+	Format string
+	// Size — ширина и высота изображения в пикселях.
+	Size int
+	// Margin — ширина белого поля в модулях QR-кода.
+	Margin *int
+	// Level — уровень коррекции ошибок: L, M, Q или H.
+	Level string
+}
+
+func (o QROptions) normalize() (QROptions, error) {
+	o.Format = strings.ToLower(o.Format)
+	if o.Format == "" {
+		o.Format = QRFormatPNG
+	}
+	if o.Format != QRFormatPNG && o.Format != QRFormatSVG {
+		return o, wrapf(ErrInvalidInput, "неподдерживаемый формат QR-кода %q: допустимы png и svg", o.Format)
+	}
+
+	if o.Size == 0 {
+		o.Size = defaultQRSize
+	}
+	if o.Size < minQRSize || o.Size > maxQRSize {
+		return o, wrapf(ErrInvalidInput, "размер QR-кода должен быть от %d до %d пикселей", minQRSize, maxQRSize)
+	}
+
+	if o.Margin == nil {
+		margin := defaultQRMargin
+		o.Margin = &margin
+	}
+	if *o.Margin < 0 || *o.Margin > maxQRMargin {
+		return o, wrapf(ErrInvalidInput, "поле QR-кода должно быть от 0 до %d модулей", maxQRMargin)
+	}
+
+	o.Level = strings.ToUpper(o.Level)
+	if o.Level == "" {
+		o.Level = "M"
+	}
+	if _, ok := qrLevels[o.Level]; !ok {
+		return o, wrapf(ErrInvalidInput, "неизвестный уровень коррекции ошибок %q: допустимы L, M, Q и H", o.Level)
+	}
+
+	return o, nil
+}
+
+// ContentType возвращает MIME-тип изображения для формата.
+func (o QROptions) ContentType() string {
+	if strings.ToLower(o.Format) == QRFormatSVG {
+		return "image/svg+xml"
+	}
+	return "image/png"
+}
+
+// QRCode кодирует content в QR-код в формате PNG или SVG.
+func QRCode(content string, opts QROptions) ([]byte, error) {
+	opts, err := opts.normalize()
+	if err != nil {
+		return nil, err
+	}
+
+	code, err := qrcode.New(content, qrLevels[opts.Level])
+	if err != nil {
+		return nil, fmt.Errorf("ошибка генерации QR-кода: %w", err)
+	}
+	code.DisableBorder = true
+	bitmap := code.Bitmap()
+
+	if opts.Format == QRFormatSVG {
+		return renderQRSVG(bitmap, opts.Size, *opts.Margin), nil
+	}
+	return renderQRPNG(bitmap, opts.Size, *opts.Margin)
+}
+
+// renderQRPNG рисует модули целым числом пикселей, чтобы код хорошо читался,
+// и центрирует его в изображении запрошенного размера.
+func renderQRPNG(bitmap [][]bool, size, margin int) ([]byte, error) {
+	total := len(bitmap) + 2*margin
+	scale := max(size/total, 1)
+	size = max(size, scale*total)
+	offset := (size-scale*total)/2 + margin*scale
+
+	img := image.NewPaletted(image.Rect(0, 0, size, size), color.Palette{color.White, color.Black})
+	for y, row := range bitmap {
+		for x, dark := range row {
+			if !dark {
+				continue
+			}
+			for dy := 0; dy < scale; dy++ {
+				for dx := 0; dx < scale; dx++ {
+					img.SetColorIndex(offset+x*scale+dx, offset+y*scale+dy, 1)
+				}
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	encoder := png.Encoder{CompressionLevel: png.BestCompression}
+	if err := encoder.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("ошибка кодирования PNG: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// renderQRSVG описывает тёмные модули одним путём, объединяя соседние модули строки.
+func renderQRSVG(bitmap [][]bool, size, margin int) []byte {
+	total := len(bitmap) + 2*margin
+
+	var path strings.Builder
+	for y, row := range bitmap {
+		for x := 0; x < len(row); {
+			if !row[x] {
+				x++
+				continue
+			}
+			start := x
+			for x < len(row) && row[x] {
+				x++
+			}
+			fmt.Fprintf(&path, "M%d %dh%dv1h-%dz", start+margin, y+margin, x-start, x-start)
+		}
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`,
+		size, size, total, total)
+	fmt.Fprintf(&buf, `<rect width="%d" height="%d" fill="#fff"/><path fill="#000" d="%s"/></svg>`, total, total, path.String())
+	return buf.Bytes()
+}
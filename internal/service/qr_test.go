@@ -0,0 +1,76 @@
+package service
+
+import (
+	"bytes"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQRCode_PNG(t *testing.T) {
+	data, err := QRCode("https://localhost:8080/abc123", QROptions{Size: 300})
+	assert.NoError(t, err)
+
+	img, err := png.Decode(bytes.NewReader(data))
+	assert.NoError(t, err)
+	assert.Equal(t, 300, img.Bounds().Dx())
+	assert.Equal(t, 300, img.Bounds().Dy())
+
+	r, _, _, _ := img.At(0, 0).RGBA()
+	assert.Equal(t, uint32(0xffff), r, "поле вокруг кода должно быть белым")
+}
+
+func TestQRCode_NoMargin(t *testing.T) {
+	margin := 0
+	data, err := QRCode("https://localhost:8080/abc123", QROptions{Size: 250, Margin: &margin})
+	assert.NoError(t, err)
+
+	img, err := png.Decode(bytes.NewReader(data))
+	assert.NoError(t, err)
+
+	// Без поля код начинается с тёмного углового маркера; изображение центрировано,
+	// поэтому первый тёмный пиксель диагонали находится не дальше нескольких пикселей от угла
+	dark := -1
+	for i := 0; i < 10; i++ {
+		if r, _, _, _ := img.At(i, i).RGBA(); r == 0 {
+			dark = i
+			break
+		}
+	}
+	assert.GreaterOrEqual(t, dark, 0)
+}
+
+func TestQRCode_SVG(t *testing.T) {
+	margin := 2
+	data, err := QRCode("https://localhost:8080/abc123", QROptions{Format: "SVG", Size: 128, Margin: &margin, Level: "h"})
+	assert.NoError(t, err)
+
+	svg := string(data)
+	assert.True(t, strings.HasPrefix(svg, "<svg"))
+	assert.Contains(t, svg, `width="128" height="128"`)
+	assert.Contains(t, svg, "M2 2h7v1h-7z", "угловой маркер начинается после поля")
+	assert.Equal(t, "image/svg+xml", QROptions{Format: "SVG"}.ContentType())
+}
+
+func TestQRCode_InvalidOptions(t *testing.T) {
+	negative := -1
+	tests := []struct {
+		name string
+		opts QROptions
+	}{
+		{name: "format", opts: QROptions{Format: "gif"}},
+		{name: "too small", opts: QROptions{Size: 10}},
+		{name: "too large", opts: QROptions{Size: 10000}},
+		{name: "negative margin", opts: QROptions{Margin: &negative}},
+		{name: "level", opts: QROptions{Level: "X"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := QRCode("https://localhost:8080/abc123", tt.opts)
+			assert.Error(t, err)
+		})
+	}
+}
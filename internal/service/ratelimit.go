@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	initprometheus "linkreduction/internal/prometheus"
+	"time"
+)
+
+// Области ограничения частоты запросов.
+const (
+	RateLimitCreate   = "create"
+	RateLimitRedirect = "redirect"
+	RateLimitBot      = "bot"
+	// RateLimitAuth ограничивает проверки API-ключей; клиент — всегда IP.
+	RateLimitAuth = "auth"
+)
+
+var ErrRateLimited = &Error{Code: "rate_limited", Message: "слишком много запросов, повторите позже"}
+
+// RateLimit — допустимое число запросов за окно. Requests <= 0 отключает ограничение.
+type RateLimit struct {
+	Requests int
+	Window   time.Duration
+}
+
+// RateLimiter применяет лимиты областей к клиентам, счётчики хранятся в RateLimitStore.
+type RateLimiter struct {
+	store   RateLimitStore
+	limits  map[string]RateLimit
+	metrics *initprometheus.PrometheusMetrics
+}
+
+// NewRateLimiter создаёт ограничитель. Без хранилища (store == nil) лимиты не применяются.
+func NewRateLimiter(store RateLimitStore, limits map[string]RateLimit, metrics *initprometheus.PrometheusMetrics) *RateLimiter {
+	return &RateLimiter{store: store, limits: limits, metrics: metrics}
+}
+
+// Allow учитывает запрос клиента в области scope. При превышении лимита возвращает
+// ErrRateLimited и время до освобождения окна. Ошибка хранилища возвращается как ErrUnavailable
+// с исходной ошибкой в цепочке, вызывающий код сам решает, пропускать ли запрос.
+func (l *RateLimiter) Allow(ctx context.Context, scope, client string) (time.Duration, error) {
+	limit, ok := l.limits[scope]
+	if !ok || limit.Requests <= 0 || limit.Window <= 0 || l.store == nil {
+		return 0, nil
+	}
+
+	allowed, retryAfter, err := l.store.Allow(ctx, "ratelimit:"+scope+":"+client, limit.Requests, limit.Window)
+	if err != nil {
+		// Исходная ошибка сохраняется в цепочке: вызывающий код отличает разомкнутый автомат защиты Redis
+		return 0, fmt.Errorf("%w: ошибка проверки лимита запросов: %w", ErrUnavailable, err)
+	}
+	if allowed {
+		return 0, nil
+	}
+
+	if l.metrics != nil && l.metrics.RateLimitedTotal != nil {
+		l.metrics.RateLimitedTotal.WithLabelValues(scope).Inc()
+	}
+	return retryAfter, ErrRateLimited
+}
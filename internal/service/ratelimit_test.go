@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"linkreduction/internal/mocks"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRateLimiter_Allow(t *testing.T) {
+	limits := map[string]RateLimit{
+		RateLimitCreate:   {Requests: 10, Window: time.Minute},
+		RateLimitRedirect: {Requests: 0, Window: time.Minute},
+	}
+
+	tests := []struct {
+		name        string
+		scope       string
+		setupMock   func(store *mocks.RateLimitStore)
+		expectRetry time.Duration
+		expectErr   error
+		anyErr      bool
+	}{
+		{
+			name:  "allowed",
+			scope: RateLimitCreate,
+			setupMock: func(store *mocks.RateLimitStore) {
+				store.On("Allow", mock.Anything, "ratelimit:create:ip:10.0.0.1", 10, time.Minute).Return(true, time.Duration(0), nil)
+			},
+		},
+		{
+			name:  "limit exceeded",
+			scope: RateLimitCreate,
+			setupMock: func(store *mocks.RateLimitStore) {
+				store.On("Allow", mock.Anything, "ratelimit:create:ip:10.0.0.1", 10, time.Minute).Return(false, 15*time.Second, nil)
+			},
+			expectRetry: 15 * time.Second,
+			expectErr:   ErrRateLimited,
+		},
+		{
+			name:      "unlimited scope",
+			scope:     RateLimitRedirect,
+			setupMock: func(store *mocks.RateLimitStore) {},
+		},
+		{
+			name:      "unknown scope",
+			scope:     RateLimitBot,
+			setupMock: func(store *mocks.RateLimitStore) {},
+		},
+		{
+			name:  "store error",
+			scope: RateLimitCreate,
+			setupMock: func(store *mocks.RateLimitStore) {
+				store.On("Allow", mock.Anything, "ratelimit:create:ip:10.0.0.1", 10, time.Minute).Return(false, time.Duration(0), fmt.Errorf("redis down"))
+			},
+			anyErr: true,
+		},
+		{
+			name:  "store error is unavailable",
+			scope: RateLimitCreate,
+			setupMock: func(store *mocks.RateLimitStore) {
+				store.On("Allow", mock.Anything, "ratelimit:create:ip:10.0.0.1", 10, time.Minute).Return(false, time.Duration(0), context.DeadlineExceeded)
+			},
+			expectErr: ErrUnavailable,
+		},
+		{
+			name:  "store error keeps its cause",
+			scope: RateLimitCreate,
+			setupMock: func(store *mocks.RateLimitStore) {
+				store.On("Allow", mock.Anything, "ratelimit:create:ip:10.0.0.1", 10, time.Minute).Return(false, time.Duration(0), context.DeadlineExceeded)
+			},
+			expectErr: context.DeadlineExceeded,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := new(mocks.RateLimitStore)
+			tt.setupMock(store)
+
+			retry, err := NewRateLimiter(store, limits, nil).Allow(context.Background(), tt.scope, "ip:10.0.0.1")
+			switch {
+			case tt.expectErr != nil:
+				assert.ErrorIs(t, err, tt.expectErr)
+			case tt.anyErr:
+				assert.Error(t, err)
+				assert.NotErrorIs(t, err, ErrRateLimited)
+			default:
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expectRetry, retry)
+			store.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRateLimiter_AllowWithoutStore(t *testing.T) {
+	limiter := NewRateLimiter(nil, map[string]RateLimit{RateLimitCreate: {Requests: 1, Window: time.Minute}}, nil)
+
+	for range 3 {
+		retryAfter, err := limiter.Allow(context.Background(), RateLimitCreate, "ip:10.0.0.1")
+		assert.NoError(t, err)
+		assert.Zero(t, retryAfter)
+	}
+}
@@ -2,195 +2,499 @@ package service
 
 import (
 	"context"
-	"crypto/md5"
-	"encoding/json"
 	"fmt"
 	"github.com/IBM/sarama"
 	"github.com/sirupsen/logrus"
-	"linkreduction/internal/const"
+	"golang.org/x/sync/singleflight"
 	"linkreduction/internal/models"
 	initprometheus "linkreduction/internal/prometheus"
+	"net/http"
 	"net/url"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 )
 
+const (
+	minAliasLength = 3
+	maxAliasLength = 64
+
+	linkCacheTTL = 10 * time.Minute
+)
+
+var aliasPattern = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// redirectCodes — допустимые коды перенаправления коротких ссылок.
+var redirectCodes = map[int]struct{}{
+	http.StatusMovedPermanently:  {},
+	http.StatusFound:             {},
+	http.StatusTemporaryRedirect: {},
+	http.StatusPermanentRedirect: {},
+}
+
+// reservedAliases совпадают с маршрутами приложения и не могут быть короткими ссылками.
+var reservedAliases = map[string]struct{}{
+	"metrics":         {},
+	"api":             {},
+	"createshortlink": {},
+}
+
+// ShortenOptions содержит необязательные параметры создания короткой ссылки.
+type ShortenOptions struct {
+	Alias string
+	// TTL — время жизни ссылки: nil означает значение по умолчанию, 0 — бессрочную ссылку.
+	TTL *time.Duration
+	// OwnerID — владелец ссылки, определённый по API-ключу.
+	OwnerID string
+	// RedirectCode — код перенаправления; 0 означает значение по умолчанию.
+	RedirectCode int
+	// Password закрывает переход по ссылке паролем; в базе хранится только его хэш.
+	Password string
+	// Interstitial показывает страницу предпросмотра перед каждым переходом.
+	Interstitial bool
+}
+
+// dedicated сообщает, что ссылка создаётся с собственными параметрами и не может быть общей.
+func (o ShortenOptions) dedicated() bool {
+	return o.Alias != "" || o.TTL != nil || o.OwnerID != "" || o.RedirectCode != 0 || o.Password != "" || o.Interstitial
+}
+
 type Service struct {
-	ctx      context.Context
-	repo     LinkRepo
-	cache    LinkCache
-	producer sarama.SyncProducer
-	metrics  *initprometheus.PrometheusMetrics
+	ctx             context.Context
+	repo            LinkRepo
+	cache           LinkCache
+	producer        sarama.SyncProducer
+	metrics         *initprometheus.PrometheusMetrics
+	defaultTTL      time.Duration
+	cleanupInterval time.Duration
+	keyGen          KeyGenerator
+	keyMaxAttempts  int
+	batchMaxItems   int
+	redirectCode    int
+	policy          *DestinationPolicy
+	titles          *PageTitles
+	// negativeCacheTTL — время жизни отрицательных записей кэша для несуществующих ключей.
+	negativeCacheTTL time.Duration
+	// redirects объединяет одновременные чтения из базы одной и той же ссылки при промахе кэша.
+	redirects singleflight.Group
+	// stale — ссылки, записи которых в кэше не удалось сбросить после изменения.
+	stale staleCache
 }
 
-func NewLinkService(ctx context.Context, repo LinkRepo, cache LinkCache, producer sarama.SyncProducer, metrics *initprometheus.PrometheusMetrics) *Service {
-	return &Service{ctx: ctx, repo: repo, cache: cache, producer: producer, metrics: metrics}
+// NewLinkService создаёт сервис ссылок. cache может быть nil — тогда сервис работает без кэша.
+func NewLinkService(ctx context.Context, repo LinkRepo, cache LinkCache, producer sarama.SyncProducer, metrics *initprometheus.PrometheusMetrics, opts ...Option) *Service {
+	if cache == nil {
+		cache = nopCache{}
+	}
+	s := &Service{ctx: ctx, repo: repo, cache: cache, producer: producer, metrics: metrics,
+		cleanupInterval:  defaultCleanupInterval,
+		negativeCacheTTL: defaultNegativeCacheTTL,
+		keyGen:           HashKeyGenerator{Length: defaultKeyLength},
+		keyMaxAttempts:   defaultKeyMaxAttempts,
+		batchMaxItems:    defaultBatchMaxItems,
+		redirectCode:     http.StatusMovedPermanently}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
-func (s *Service) CleanupOldLinks(logger *logrus.Logger) {
+// CleanupExpiredLinks периодически удаляет ссылки, срок действия которых истёк.
+func (s *Service) CleanupExpiredLinks(logger *logrus.Logger) {
 
-	ticker := time.NewTicker(2 * time.Hour)
+	ticker := time.NewTicker(s.cleanupInterval)
 	defer ticker.Stop()
 
 	for {
 		select {
 		case <-ticker.C:
-			err := s.repo.DeleteOldLinks(s.ctx, "2 weeks")
+			err := s.repo.DeleteExpiredLinks(s.ctx)
 			if err != nil {
 				logger.Error(err)
 				continue
 			}
+		case <-s.ctx.Done():
+			return
 		}
 	}
 }
 
-func (s *Service) ShortenURL(ctx context.Context, originalURL string, baseUrl string) (string, error) {
+func (s *Service) ShortenURL(ctx context.Context, originalURL string, baseUrl string, opts ShortenOptions) (models.LinkURL, error) {
+	return s.shorten(ctx, ctx, originalURL, baseUrl, opts, nil)
+}
+
+// shorten создаёт короткую ссылку. taken содержит ключи, уже выданные в текущем пакете,
+// но ещё не сохранённые в базе: они считаются занятыми. checkCtx ограничивает сетевые проверки
+// политики: в пакете на них отводится общее время. Цепочка перенаправлений проверяется, только
+// если ссылка действительно создаётся, а не найдена среди существующих.
+func (s *Service) shorten(ctx, checkCtx context.Context, originalURL string, baseUrl string, opts ShortenOptions,
+	taken map[string]struct{}) (models.LinkURL, error) {
 
-	if err := validateURL(originalURL, baseUrl); err != nil {
-		return "", err
+	if err := s.checkDestination(checkCtx, originalURL, baseUrl); err != nil {
+		return models.LinkURL{}, err
 	}
 
-	if cachedShortLink, err := s.cache.GetShortLink(ctx, originalURL); err != nil {
-		return "", fmt.Errorf("ошибка чтения из кэша: %v", err)
-	} else if cachedShortLink != "" {
-		return cachedShortLink, nil
+	code := s.redirectCode
+	if opts.RedirectCode != 0 {
+		if err := validateRedirectCode(opts.RedirectCode); err != nil {
+			return models.LinkURL{}, err
+		}
+		code = opts.RedirectCode
 	}
 
-	shortLink, err := s.repo.FindByOriginalURL(ctx, originalURL)
-	if err != nil {
-		return "", fmt.Errorf("ошибка проверки URL в базе данных: %w", err)
+	var passwordHash string
+	if opts.Password != "" {
+		hash, err := hashLinkPassword(opts.Password)
+		if err != nil {
+			return models.LinkURL{}, err
+		}
+		passwordHash = hash
 	}
-	if shortLink != "" {
-		if err := s.cache.SetShortLink(ctx, originalURL, shortLink, time.Minute*10); err != nil {
-			return "", fmt.Errorf("ошибка записи в кэш: %w", err)
+
+	expiresAt := s.expiresAt(opts.TTL)
+
+	if opts.Alias != "" {
+		link, err := s.reserveAlias(ctx, originalURL, opts.Alias, expiresAt, taken)
+		if err == nil {
+			err = s.checkRedirects(checkCtx, originalURL)
 		}
-		return shortLink, nil
+		link.OwnerID = opts.OwnerID
+		link.RedirectCode = code
+		link.PasswordHash = passwordHash
+		link.Interstitial = opts.Interstitial
+		return link, err
 	}
 
-	for i := 0; i < 3; i++ {
-		inputURL := originalURL
-		if i > 0 {
-			inputURL = fmt.Sprintf("%s_%d", originalURL, i)
+	// Ссылка с собственным сроком жизни или владельцем не должна совпадать с общей ссылкой на тот же URL
+	if opts.dedicated() {
+		if err := s.checkRedirects(checkCtx, originalURL); err != nil {
+			return models.LinkURL{}, err
+		}
+		shortLink, err := s.generateUniqueKey(ctx, originalURL+"#"+strconv.FormatInt(time.Now().UnixNano(), 36), taken)
+		if err != nil {
+			return models.LinkURL{}, err
+		}
+		return models.LinkURL{OriginalURL: originalURL, ShortLink: shortLink, Custom: true, ExpiresAt: expiresAt,
+			OwnerID: opts.OwnerID, RedirectCode: code, PasswordHash: passwordHash, Interstitial: opts.Interstitial}, nil
+	}
+
+	// Запись в кэше для URL с несброшенным кэшем может указывать на изменённую или удалённую ссылку.
+	// Срок общей ссылки из кэша неизвестен, поэтому она получает новый срок: SaveLinks вернёт
+	// сохранённый срок действующей ссылки, а истёкшую за это время заменит с новым, а не бессрочно
+	if !s.stale.hasURL(originalURL) {
+		if cachedShortLink, err := s.cache.GetShortLink(ctx, originalURL); err != nil {
+			s.cacheFailed("get_short_link")
+		} else if cachedShortLink != "" {
+			return models.LinkURL{OriginalURL: originalURL, ShortLink: cachedShortLink, ExpiresAt: expiresAt, RedirectCode: code}, nil
+		}
+	}
+
+	existing, err := s.repo.FindByOriginalURL(ctx, originalURL)
+	if err != nil {
+		return models.LinkURL{}, fmt.Errorf("ошибка проверки URL в базе данных: %w", err)
+	}
+	// Истёкшая общая ссылка считается отсутствующей и создаётся заново с новым сроком
+	if existing != nil && !existing.Expired(time.Now()) {
+		link := models.LinkURL{OriginalURL: originalURL, ShortLink: existing.ShortLink, ExpiresAt: existing.ExpiresAt, RedirectCode: code}
+		s.cacheShortLink(ctx, link)
+		return link, nil
+	}
+
+	if err := s.checkRedirects(checkCtx, originalURL); err != nil {
+		return models.LinkURL{}, err
+	}
+	shortLink, err := s.generateUniqueKey(ctx, originalURL, taken)
+	if err != nil {
+		return models.LinkURL{}, err
+	}
+
+	return models.LinkURL{OriginalURL: originalURL, ShortLink: shortLink, ExpiresAt: expiresAt, RedirectCode: code}, nil
+}
+
+func (s *Service) generateUniqueKey(ctx context.Context, originalURL string, taken map[string]struct{}) (string, error) {
+	for attempt := 0; attempt < s.keyMaxAttempts; attempt++ {
+		shortLink, err := s.keyGen.Generate(ctx, originalURL, attempt)
+		if err != nil {
+			return "", err
+		}
+		if _, ok := taken[shortLink]; ok {
+			continue
 		}
-		shortLink := generateShortLink(inputURL)
 
 		if existing, err := s.repo.FindByShortLink(ctx, shortLink); err != nil {
-			return "", fmt.Errorf("ошибка проверки ключа: %v", err)
+			return "", fmt.Errorf("ошибка проверки ключа: %w", err)
 		} else if existing == "" {
 			return shortLink, nil
 		}
-
-		if i == 2 {
-			return "", fmt.Errorf("не удалось сгенерировать уникальный ключ после %d попыток", i+1)
-		}
 	}
 
-	return "", fmt.Errorf("не удалось сгенерировать короткую ссылку")
+	return "", fmt.Errorf("не удалось сгенерировать уникальный ключ после %d попыток", s.keyMaxAttempts)
+}
+
+// expiresAt вычисляет момент истечения ссылки; nil означает бессрочную ссылку.
+func (s *Service) expiresAt(ttl *time.Duration) *time.Time {
+	d := s.defaultTTL
+	if ttl != nil {
+		d = *ttl
+	}
+	if d <= 0 {
+		return nil
+	}
+	t := time.Now().Add(d).UTC()
+	return &t
 }
 
-func (s *Service) InsertLink(ctx context.Context, originalURL, shortLink string) error {
-	err := s.repo.Insert(ctx, originalURL, shortLink)
+// reserveAlias проверяет пользовательский псевдоним и его уникальность. Проверка не атомарна:
+// псевдоним, занятый параллельным запросом, обнаруживается при сохранении.
+// Ссылки с псевдонимом не переиспользуются для других запросов с тем же URL.
+func (s *Service) reserveAlias(ctx context.Context, originalURL, alias string, expiresAt *time.Time, taken map[string]struct{}) (models.LinkURL, error) {
+	if err := validateAlias(alias); err != nil {
+		return models.LinkURL{}, err
+	}
+	if _, ok := taken[alias]; ok {
+		return models.LinkURL{}, wrapf(ErrAliasTaken, "%s", alias)
+	}
+
+	existing, err := s.repo.FindByShortLink(ctx, alias)
 	if err != nil {
-		return err
+		return models.LinkURL{}, fmt.Errorf("ошибка проверки псевдонима: %w", err)
 	}
-	if err := s.cache.SetShortLink(ctx, originalURL, shortLink, time.Minute*10); err != nil {
-		return fmt.Errorf("не удалось вставить новую ссылку: %v", err)
+	if existing != "" {
+		return models.LinkURL{}, wrapf(ErrAliasTaken, "%s", alias)
 	}
 
-	return nil
+	return models.LinkURL{OriginalURL: originalURL, ShortLink: alias, Custom: true, ExpiresAt: expiresAt}, nil
+}
+
+// fillRedirectCode подставляет код перенаправления по умолчанию для сообщений,
+// отправленных до появления этого поля.
+func (s *Service) fillRedirectCode(link *models.LinkURL) {
+	if link.RedirectCode == 0 {
+		link.RedirectCode = s.redirectCode
+	}
 }
 
-func (s *Service) GetOriginalURL(ctx context.Context, shortLink string) (string, error) {
+// GetRedirect возвращает адрес и код перенаправления ссылки.
+func (s *Service) GetRedirect(ctx context.Context, shortLink string) (*models.Redirect, error) {
+
+	// При сбое кэша ссылка читается из базы. Ссылка, записи которой не удалось сбросить после
+	// изменения, тоже читается из базы: кэш может указывать на прежний адрес
+	if !s.stale.hasLink(shortLink) {
+		if cached, err := s.cache.GetRedirect(ctx, shortLink); err != nil {
+			s.cacheFailed("get_redirect")
+		} else if cached != nil {
+			if cached.NotFound {
+				return nil, ErrLinkNotFound
+			}
+			if cached.Expired(time.Now()) {
+				return nil, ErrLinkExpired
+			}
+			return cached, nil
+		}
+	}
 
-	if cachedURL, err := s.cache.GetOriginalURL(ctx, shortLink); err != nil {
-		return "", fmt.Errorf("ошибка чтения из кэша: %v", err)
-	} else if cachedURL != "" {
-		return cachedURL, nil
+	// Базу читает только первый запрос; остальные ждут его результат, но могут уйти раньше по своему ctx
+	result := s.redirects.DoChan(shortLink, func() (interface{}, error) {
+		return s.loadRedirect(context.WithoutCancel(ctx), shortLink)
+	})
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-result:
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		redirect := res.Val.(models.Redirect)
+		return &redirect, nil
 	}
+}
 
-	originalURL, err := s.repo.FindByShortLink(ctx, shortLink)
+// loadRedirect читает ссылку из базы и кэширует результат, в том числе отсутствие ссылки.
+func (s *Service) loadRedirect(ctx context.Context, shortLink string) (models.Redirect, error) {
+	link, err := s.repo.FindLink(ctx, shortLink)
 	if err != nil {
-		return "", fmt.Errorf("ошибка базы данных: %v", err)
+		return models.Redirect{}, fmt.Errorf("ошибка базы данных: %w", err)
 	}
-	if originalURL == "" {
-		return "", nil
+	if link == nil {
+		if err := s.cache.SetRedirect(ctx, shortLink, models.Redirect{NotFound: true}, s.negativeCacheTTL); err != nil {
+			s.cacheFailed("set_redirect")
+		}
+		return models.Redirect{}, ErrLinkNotFound
+	}
+	if link.Expired(time.Now()) {
+		return models.Redirect{}, ErrLinkExpired
 	}
 
-	if err := s.cache.SetOriginalURL(ctx, shortLink, originalURL, 10*60); err != nil {
-		return "", fmt.Errorf("ошибка записи в кэш: %v", err)
+	s.fillRedirectCode(link)
+	redirect := models.Redirect{URL: link.OriginalURL, Code: link.RedirectCode, PasswordHash: link.PasswordHash,
+		Interstitial: link.Interstitial, ExpiresAt: link.ExpiresAt}
+	if ttl := cacheTTL(link.ExpiresAt); ttl > 0 {
+		if err := s.cache.SetRedirect(ctx, shortLink, redirect, ttl); err != nil {
+			s.cacheFailed("set_redirect")
+		}
 	}
 
-	return originalURL, nil
+	return redirect, nil
 }
 
-func generateShortLink(originalURL string) string {
-	hash := md5.Sum([]byte(originalURL))
-	return fmt.Sprintf("%x", hash)[:6]
+// GetLink возвращает сведения о ссылке владельца, включая истёкшие, но ещё не удалённые.
+// Чужие ссылки неотличимы от несуществующих.
+func (s *Service) GetLink(ctx context.Context, ownerID, shortLink string) (*models.LinkURL, error) {
+	link, err := s.repo.FindLink(ctx, shortLink)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка базы данных: %w", err)
+	}
+	if link == nil || ownerID == "" || link.OwnerID != ownerID {
+		return nil, ErrLinkNotFound
+	}
+	return link, nil
 }
 
-func (s *Service) InsertBatch(ctx context.Context, batch []models.LinkURL) error {
-	if len(batch) == 0 {
-		return fmt.Errorf("длина батча нулевая")
+// UpdateOriginalURL перенаправляет существующую ссылку на новый адрес и сбрасывает её кэш.
+// Сбой сброса не отменяет сохранённое изменение: сброс повторяет RetryInvalidations.
+func (s *Service) UpdateOriginalURL(ctx context.Context, ownerID, shortLink, originalURL, baseUrl string) (*models.LinkURL, error) {
+	if err := s.checkDestination(ctx, originalURL, baseUrl); err != nil {
+		return nil, err
+	}
+	if err := s.checkRedirects(ctx, originalURL); err != nil {
+		return nil, err
 	}
 
-	err := s.repo.InsertBatch(ctx, batch)
+	link, err := s.GetLink(ctx, ownerID, shortLink)
 	if err != nil {
-		return fmt.Errorf("ошибка при внедрение батча %v", err)
+		return nil, err
 	}
 
-	for _, link := range batch {
-		if err := s.cache.SetShortLink(ctx, link.OriginalURL, link.ShortLink, time.Minute*10); err != nil {
-			return fmt.Errorf("ошибка записи в Redis (shorten): %v,%v", link.OriginalURL, err)
-		}
+	if err := s.repo.UpdateOriginalURL(ctx, shortLink, originalURL); err != nil {
+		return nil, fmt.Errorf("ошибка обновления ссылки: %w", err)
 	}
+	s.invalidate(ctx, shortLink, link.OriginalURL)
+
+	link.OriginalURL = originalURL
+	link.Custom = true
+	return link, nil
+}
+
+// DeleteLink удаляет ссылку и её записи в кэше; сбой сброса кэша повторяет RetryInvalidations.
+func (s *Service) DeleteLink(ctx context.Context, ownerID, shortLink string) error {
+	link, err := s.GetLink(ctx, ownerID, shortLink)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.Delete(ctx, shortLink); err != nil {
+		return fmt.Errorf("ошибка удаления ссылки: %w", err)
+	}
+	s.invalidate(ctx, shortLink, link.OriginalURL)
 	return nil
 }
 
-func (s *Service) SendMessageToDB(originalURL string, shortLink string) error {
+// cacheTTL ограничивает время жизни записи в кэше моментом истечения ссылки. Неположительное
+// значение означает, что ссылка уже истекла: Redis сохранил бы такую запись без срока действия.
+func cacheTTL(expiresAt *time.Time) time.Duration {
+	if expiresAt == nil {
+		return linkCacheTTL
+	}
+	return min(linkCacheTTL, time.Until(*expiresAt))
+}
 
-	if s.producer != nil {
-		msg := &message.ShortenMessage{OriginalURL: originalURL, ShortLink: shortLink}
-		messageBytes, err := json.Marshal(msg)
-		if err != nil {
+// cacheShortLink кэширует общую ссылку по исходному URL; истёкшая ссылка не кэшируется.
+func (s *Service) cacheShortLink(ctx context.Context, link models.LinkURL) {
+	ttl := cacheTTL(link.ExpiresAt)
+	if ttl <= 0 {
+		return
+	}
+	if err := s.cache.SetShortLink(ctx, link.OriginalURL, link.ShortLink, ttl); err != nil {
+		s.cacheFailed("set_short_link")
+	}
+}
 
-			if s.metrics != nil && s.metrics.CreateShortLinkTotal != nil {
-				s.metrics.CreateShortLinkTotal.WithLabelValues("error", "kafka_serialization").Inc()
-			}
+// InsertBatch сохраняет ссылки из Kafka и возвращает результат для каждой из них. Ссылки, срок
+// которых истёк, пока сообщение ждало обработки, не сохраняются. В кэш попадают только
+// сохранённые ссылки: при конфликте ключ в базе указывает на другой URL.
+func (s *Service) InsertBatch(ctx context.Context, batch []models.LinkURL) ([]models.InsertStatus, error) {
+	if len(batch) == 0 {
+		return nil, wrapf(ErrInvalidInput, "длина батча нулевая")
+	}
 
-			return fmt.Errorf("kafka metric error")
+	now := time.Now()
+	statuses := make([]models.InsertStatus, len(batch))
+	live := make([]models.LinkURL, 0, len(batch))
+	liveIdx := make([]int, 0, len(batch))
+	for i := range batch {
+		s.fillRedirectCode(&batch[i])
+		if batch[i].Expired(now) {
+			statuses[i] = models.InsertExpired
+			continue
 		}
+		live = append(live, batch[i])
+		liveIdx = append(liveIdx, i)
+	}
 
-		_, _, err = s.producer.SendMessage(&sarama.ProducerMessage{
-			Topic: message.ShortenURLsTopic,
-			Value: sarama.ByteEncoder(messageBytes),
-		})
+	if len(live) > 0 {
+		inserted, err := s.repo.InsertBatch(ctx, live)
 		if err != nil {
-			if s.metrics != nil && s.metrics.CreateShortLinkTotal != nil {
-				s.metrics.CreateShortLinkTotal.WithLabelValues("error", "kafka_send").Inc()
-			}
-			return fmt.Errorf("kafka send error")
+			return nil, fmt.Errorf("ошибка при внедрение батча: %w", err)
 		}
+		for j, i := range liveIdx {
+			statuses[i] = inserted[j]
+		}
+	}
 
-	} else {
-
-		if err := s.InsertLink(s.ctx, originalURL, shortLink); err != nil {
-			if s.metrics != nil && s.metrics.CreateShortLinkTotal != nil {
-				s.metrics.CreateShortLinkTotal.WithLabelValues("error", "db_insert").Inc()
-			}
-			return nil
+	for i, link := range batch {
+		if s.metrics != nil && s.metrics.BatchLinksTotal != nil {
+			s.metrics.BatchLinksTotal.WithLabelValues(string(statuses[i])).Inc()
+		}
+		if link.Custom || !statuses[i].Persisted() {
+			continue
 		}
+		s.cacheShortLink(ctx, link)
 	}
-	if s.metrics != nil && s.metrics.CreateShortLinkTotal != nil {
-		s.metrics.CreateShortLinkTotal.WithLabelValues("success", "none").Inc()
+	return statuses, nil
+}
+
+// SaveLink сохраняет созданную ссылку и возвращает её в сохранённом виде: если общая ссылка
+// на тот же URL уже создана параллельным запросом, возвращается она.
+func (s *Service) SaveLink(ctx context.Context, link models.LinkURL) (models.LinkURL, error) {
+	saved, err := s.saveLinks(ctx, []models.LinkURL{link})
+	if err != nil {
+		return models.LinkURL{}, err
 	}
-	return nil
+	return saved[0].Link, saved[0].Err
+}
+
+// checkDestination проверяет формат адреса и, если задана, политику адресов назначения для его домена.
+func (s *Service) checkDestination(ctx context.Context, originalURL, baseUrl string) error {
+	if err := validateURL(originalURL, baseUrl); err != nil {
+		return err
+	}
+	if s.policy == nil {
+		return nil
+	}
+	return s.policyRejected(s.policy.CheckHost(ctx, originalURL))
+}
+
+// checkRedirects проверяет по политике цепочку перенаправлений адреса новой ссылки.
+func (s *Service) checkRedirects(ctx context.Context, originalURL string) error {
+	if s.policy == nil {
+		return nil
+	}
+	return s.policyRejected(s.policy.CheckRedirects(ctx, originalURL))
+}
+
+func (s *Service) policyRejected(err error) error {
+	if err != nil && s.metrics != nil && s.metrics.CreateShortLinkTotal != nil {
+		s.metrics.CreateShortLinkTotal.WithLabelValues("error", "policy").Inc()
+	}
+	return err
 }
 
 func validateURL(originalURL, baseUrl string) error {
 
 	parsed, err := url.Parse(originalURL)
 	if err != nil {
-		return fmt.Errorf("некорректный URL")
+		return ErrInvalidURL
 	}
 
 	serverURL, err := url.Parse(baseUrl)
@@ -199,15 +503,75 @@ func validateURL(originalURL, baseUrl string) error {
 	}
 
 	if parsed.Hostname() == serverURL.Hostname() {
-		return fmt.Errorf("это ссылка на наш сайт, ты можешь просто перейти по ней")
+		return wrapf(ErrInvalidURL, "это ссылка на наш сайт, ты можешь просто перейти по ней")
 	}
 
 	if !strings.HasPrefix(originalURL, "http://") && !strings.HasPrefix(originalURL, "https://") {
-		return fmt.Errorf("некорректный URL: должен начинаться с http:// или https://")
+		return wrapf(ErrInvalidURL, "должен начинаться с http:// или https://")
 	}
 	parsedURL, err := url.Parse(originalURL)
 	if err != nil || parsedURL.Scheme == "" || parsedURL.Host == "" {
-		return fmt.Errorf("некорректный формат URL")
+		return wrapf(ErrInvalidURL, "некорректный формат")
+	}
+	return nil
+}
+
+func validateRedirectCode(code int) error {
+	if _, ok := redirectCodes[code]; !ok {
+		return wrapf(ErrInvalidRedirectCode, "%d: допустимы 301, 302, 307 и 308", code)
 	}
 	return nil
 }
+
+func validateAlias(alias string) error {
+	if len(alias) < minAliasLength || len(alias) > maxAliasLength {
+		return wrapf(ErrInvalidAlias, "длина должна быть от %d до %d символов", minAliasLength, maxAliasLength)
+	}
+	if !aliasPattern.MatchString(alias) {
+		return wrapf(ErrInvalidAlias, "допустимы латинские буквы, цифры, '-' и '_'")
+	}
+	if _, reserved := reservedAliases[strings.ToLower(alias)]; reserved {
+		return wrapf(ErrInvalidAlias, "%q зарезервирован", alias)
+	}
+	return nil
+}
+
+// ParseTTL разбирает время жизни ссылки: поддерживаются длительности Go ("36h")
+// и дни ("7d"); "0" означает бессрочную ссылку.
+func ParseTTL(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, wrapf(ErrInvalidTTL, "%q", value)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	ttl, err := time.ParseDuration(value)
+	if err != nil || ttl < 0 {
+		return 0, wrapf(ErrInvalidTTL, "%q", value)
+	}
+	return ttl, nil
+}
+
+// ParseExpiry определяет время жизни ссылки по ttl (в формате ParseTTL) или моменту
+// истечения expiresAt; nil без обоих параметров означает срок по умолчанию.
+func ParseExpiry(ttl string, expiresAt *time.Time) (*time.Duration, error) {
+	switch {
+	case ttl != "" && expiresAt != nil:
+		return nil, wrapf(ErrInvalidTTL, "нельзя одновременно указывать ttl и expires_at")
+	case ttl != "":
+		d, err := ParseTTL(ttl)
+		if err != nil {
+			return nil, err
+		}
+		return &d, nil
+	case expiresAt != nil:
+		d := time.Until(*expiresAt)
+		if d <= 0 {
+			return nil, wrapf(ErrInvalidTTL, "expires_at должен быть в будущем")
+		}
+		return &d, nil
+	}
+	return nil, nil
+}
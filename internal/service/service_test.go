@@ -3,9 +3,14 @@ package service
 import (
 	"context"
 	"fmt"
+	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/mock"
+	"io"
 	"linkreduction/internal/models"
+	"net/http"
+	"sync"
 	"testing"
+	"time"
 
 	"linkreduction/internal/mocks"
 
@@ -31,11 +36,11 @@ func TestShortenURL_ReturnsFromCache(t *testing.T) {
 	mockCache.On("GetShortLink", ctx, originalURL).Return(expectedShortLink, nil)
 
 	// Вызываем тестируемый метод
-	shortLink, err := svc.ShortenURL(ctx, originalURL, baseUrl)
+	link, err := svc.ShortenURL(ctx, originalURL, baseUrl, ShortenOptions{})
 
 	// Проверяем результат
 	assert.NoError(t, err)
-	assert.Equal(t, expectedShortLink, shortLink)
+	assert.Equal(t, expectedShortLink, link.ShortLink)
 
 	// Проверяем, что был вызван только кэш, а репозиторий — нет
 	mockCache.AssertCalled(t, "GetShortLink", ctx, originalURL)
@@ -49,9 +54,11 @@ func TestService_ShortenURL(t *testing.T) {
 		name         string
 		originalURL  string
 		baseURL      string
+		opts         ShortenOptions
 		mockBehavior mockBehavior
 		expectedLink string
 		expectError  bool
+		expectedErr  error
 	}{
 		{
 			name:        "valid - found in cache",
@@ -69,7 +76,7 @@ func TestService_ShortenURL(t *testing.T) {
 			baseURL:     "https://localhost:8080",
 			mockBehavior: func(ctx context.Context, repo *mocks.LinkRepo, cache *mocks.LinkCache) {
 				cache.On("GetShortLink", ctx, "https://db.com").Return("", nil)
-				repo.On("FindByOriginalURL", ctx, "https://db.com").Return("db123", nil)
+				repo.On("FindByOriginalURL", ctx, "https://db.com").Return(&models.LinkURL{OriginalURL: "https://db.com", ShortLink: "db123"}, nil)
 				cache.On("SetShortLink", ctx, "https://db.com", "db123", mock.Anything).Return(nil)
 			},
 			expectedLink: "db123",
@@ -81,10 +88,10 @@ func TestService_ShortenURL(t *testing.T) {
 			baseURL:     "https://localhost:8080",
 			mockBehavior: func(ctx context.Context, repo *mocks.LinkRepo, cache *mocks.LinkCache) {
 				cache.On("GetShortLink", ctx, "https://new.com").Return("", nil)
-				repo.On("FindByOriginalURL", ctx, "https://new.com").Return("", nil)
-				repo.On("FindByShortLink", ctx, generateShortLink("https://new.com")).Return("", nil)
+				repo.On("FindByOriginalURL", ctx, "https://new.com").Return(nil, nil)
+				repo.On("FindByShortLink", ctx, hashKey("https://new.com", 0)).Return("", nil)
 			},
-			expectedLink: generateShortLink("https://new.com"),
+			expectedLink: hashKey("https://new.com", 0),
 			expectError:  false,
 		},
 		{
@@ -96,16 +103,19 @@ func TestService_ShortenURL(t *testing.T) {
 			},
 			expectedLink: "",
 			expectError:  true,
+			expectedErr:  ErrInvalidURL,
 		},
 		{
-			name:        "cache error",
+			name:        "cache error falls back to DB",
 			originalURL: "https://error.com",
 			baseURL:     "https://localhost:8080",
 			mockBehavior: func(ctx context.Context, repo *mocks.LinkRepo, cache *mocks.LinkCache) {
 				cache.On("GetShortLink", ctx, "https://error.com").Return("", fmt.Errorf("cache down"))
+				repo.On("FindByOriginalURL", ctx, "https://error.com").Return(&models.LinkURL{OriginalURL: "https://error.com", ShortLink: "db123"}, nil)
+				cache.On("SetShortLink", ctx, "https://error.com", "db123", mock.Anything).Return(fmt.Errorf("cache down"))
 			},
-			expectedLink: "",
-			expectError:  true,
+			expectedLink: "db123",
+			expectError:  false,
 		},
 		{
 			name:        "repo.FindByOriginalURL returns error",
@@ -113,22 +123,22 @@ func TestService_ShortenURL(t *testing.T) {
 			baseURL:     "https://localhost:8080",
 			mockBehavior: func(ctx context.Context, repo *mocks.LinkRepo, cache *mocks.LinkCache) {
 				cache.On("GetShortLink", ctx, "https://errordb.com").Return("", nil)
-				repo.On("FindByOriginalURL", ctx, "https://errordb.com").Return("", fmt.Errorf("db error"))
+				repo.On("FindByOriginalURL", ctx, "https://errordb.com").Return(nil, fmt.Errorf("db error"))
 			},
 			expectedLink: "",
 			expectError:  true,
 		},
 		{
-			name:        "cache.SetShortLink returns error",
+			name:        "cache.SetShortLink error does not fail the request",
 			originalURL: "https://setcache.com",
 			baseURL:     "https://localhost:8080",
 			mockBehavior: func(ctx context.Context, repo *mocks.LinkRepo, cache *mocks.LinkCache) {
 				cache.On("GetShortLink", ctx, "https://setcache.com").Return("", nil)
-				repo.On("FindByOriginalURL", ctx, "https://setcache.com").Return("short-set", nil)
+				repo.On("FindByOriginalURL", ctx, "https://setcache.com").Return(&models.LinkURL{OriginalURL: "https://setcache.com", ShortLink: "short-set"}, nil)
 				cache.On("SetShortLink", ctx, "https://setcache.com", "short-set", mock.Anything).Return(fmt.Errorf("cache write error"))
 			},
-			expectedLink: "",
-			expectError:  true,
+			expectedLink: "short-set",
+			expectError:  false,
 		},
 		{
 			name:        "repo.FindByShortLink returns error",
@@ -136,8 +146,8 @@ func TestService_ShortenURL(t *testing.T) {
 			baseURL:     "https://localhost:8080",
 			mockBehavior: func(ctx context.Context, repo *mocks.LinkRepo, cache *mocks.LinkCache) {
 				cache.On("GetShortLink", ctx, "https://shortgenerr.com").Return("", nil)
-				repo.On("FindByOriginalURL", ctx, "https://shortgenerr.com").Return("", nil)
-				repo.On("FindByShortLink", ctx, generateShortLink("https://shortgenerr.com")).Return("", fmt.Errorf("lookup error"))
+				repo.On("FindByOriginalURL", ctx, "https://shortgenerr.com").Return(nil, nil)
+				repo.On("FindByShortLink", ctx, hashKey("https://shortgenerr.com", 0)).Return("", fmt.Errorf("lookup error"))
 			},
 			expectedLink: "",
 			expectError:  true,
@@ -148,15 +158,64 @@ func TestService_ShortenURL(t *testing.T) {
 			baseURL:     "https://localhost:8080",
 			mockBehavior: func(ctx context.Context, repo *mocks.LinkRepo, cache *mocks.LinkCache) {
 				cache.On("GetShortLink", ctx, "https://collide.com").Return("", nil)
-				repo.On("FindByOriginalURL", ctx, "https://collide.com").Return("", nil)
+				repo.On("FindByOriginalURL", ctx, "https://collide.com").Return(nil, nil)
 
-				repo.On("FindByShortLink", ctx, generateShortLink("https://collide.com")).Return("taken", nil)
-				repo.On("FindByShortLink", ctx, generateShortLink("https://collide.com_1")).Return("taken", nil)
-				repo.On("FindByShortLink", ctx, generateShortLink("https://collide.com_2")).Return("taken", nil)
+				for attempt := 0; attempt < defaultKeyMaxAttempts; attempt++ {
+					repo.On("FindByShortLink", ctx, hashKey("https://collide.com", attempt)).Return("taken", nil).Once()
+				}
 			},
 			expectedLink: "",
 			expectError:  true,
 		},
+		{
+			name:        "valid alias",
+			originalURL: "https://example.com/sale",
+			baseURL:     "https://localhost:8080",
+			opts:        ShortenOptions{Alias: "spring-sale"},
+			mockBehavior: func(ctx context.Context, repo *mocks.LinkRepo, cache *mocks.LinkCache) {
+				repo.On("FindByShortLink", ctx, "spring-sale").Return("", nil)
+			},
+			expectedLink: "spring-sale",
+			expectError:  false,
+		},
+		{
+			name:        "alias already taken",
+			originalURL: "https://example.com/sale",
+			baseURL:     "https://localhost:8080",
+			opts:        ShortenOptions{Alias: "spring-sale"},
+			mockBehavior: func(ctx context.Context, repo *mocks.LinkRepo, cache *mocks.LinkCache) {
+				repo.On("FindByShortLink", ctx, "spring-sale").Return("https://other.com", nil)
+			},
+			expectError: true,
+			expectedErr: ErrAliasTaken,
+		},
+		{
+			name:         "reserved alias",
+			originalURL:  "https://example.com/sale",
+			baseURL:      "https://localhost:8080",
+			opts:         ShortenOptions{Alias: "Metrics"},
+			mockBehavior: func(ctx context.Context, repo *mocks.LinkRepo, cache *mocks.LinkCache) {},
+			expectError:  true,
+			expectedErr:  ErrInvalidAlias,
+		},
+		{
+			name:         "alias with invalid characters",
+			originalURL:  "https://example.com/sale",
+			baseURL:      "https://localhost:8080",
+			opts:         ShortenOptions{Alias: "spring sale!"},
+			mockBehavior: func(ctx context.Context, repo *mocks.LinkRepo, cache *mocks.LinkCache) {},
+			expectError:  true,
+			expectedErr:  ErrInvalidAlias,
+		},
+		{
+			name:         "alias too short",
+			originalURL:  "https://example.com/sale",
+			baseURL:      "https://localhost:8080",
+			opts:         ShortenOptions{Alias: "ab"},
+			mockBehavior: func(ctx context.Context, repo *mocks.LinkRepo, cache *mocks.LinkCache) {},
+			expectError:  true,
+			expectedErr:  ErrInvalidAlias,
+		},
 	}
 
 	for _, tt := range tests {
@@ -164,13 +223,17 @@ func TestService_ShortenURL(t *testing.T) {
 			ctx, repo, cache, svc := getMocksWithService()
 			tt.mockBehavior(ctx, repo, cache)
 
-			result, err := svc.ShortenURL(ctx, tt.originalURL, tt.baseURL)
+			result, err := svc.ShortenURL(ctx, tt.originalURL, tt.baseURL, tt.opts)
 
 			if tt.expectError {
 				assert.Error(t, err)
+				if tt.expectedErr != nil {
+					assert.ErrorIs(t, err, tt.expectedErr)
+				}
 			} else {
 				assert.NoError(t, err)
-				assert.Equal(t, tt.expectedLink, result)
+				assert.Equal(t, tt.expectedLink, result.ShortLink)
+				assert.Equal(t, tt.opts.Alias != "", result.Custom)
 			}
 
 			cache.AssertExpectations(t)
@@ -179,44 +242,240 @@ func TestService_ShortenURL(t *testing.T) {
 	}
 }
 
-func TestService_InsertLink(t *testing.T) {
+func TestService_ShortenURL_TTL(t *testing.T) {
+	ctx, repo, cache, svc := getMocksWithService()
+	svc.defaultTTL = 14 * 24 * time.Hour
+
+	repo.On("FindByShortLink", ctx, mock.Anything).Return("", nil)
+
+	day := 24 * time.Hour
+	link, err := svc.ShortenURL(ctx, "https://example.com", "https://localhost:8080", ShortenOptions{TTL: &day})
+	assert.NoError(t, err)
+	assert.True(t, link.Custom, "ссылка со своим сроком жизни не должна переиспользоваться")
+	if assert.NotNil(t, link.ExpiresAt) {
+		assert.WithinDuration(t, time.Now().Add(day), *link.ExpiresAt, time.Minute)
+	}
+
+	forever := time.Duration(0)
+	link, err = svc.ShortenURL(ctx, "https://example.com", "https://localhost:8080", ShortenOptions{TTL: &forever})
+	assert.NoError(t, err)
+	assert.Nil(t, link.ExpiresAt)
+
+	cache.AssertNotCalled(t, "GetShortLink", mock.Anything, mock.Anything)
+}
+
+func TestService_ShortenURL_SharedLinkExpiry(t *testing.T) {
+	const baseURL = "https://localhost:8080"
+
+	t.Run("stored expiry is kept and caps the cache TTL", func(t *testing.T) {
+		ctx, repo, cache, svc := getMocksWithService()
+		svc.defaultTTL = time.Hour
+
+		expiresAt := time.Now().Add(time.Minute)
+		cache.On("GetShortLink", ctx, "https://example.com").Return("", nil)
+		repo.On("FindByOriginalURL", ctx, "https://example.com").
+			Return(&models.LinkURL{OriginalURL: "https://example.com", ShortLink: "abc123", ExpiresAt: &expiresAt}, nil)
+		cache.On("SetShortLink", ctx, "https://example.com", "abc123",
+			mock.MatchedBy(func(ttl time.Duration) bool { return ttl > 0 && ttl <= time.Minute })).Return(nil)
+
+		link, err := svc.ShortenURL(ctx, "https://example.com", baseURL, ShortenOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, "abc123", link.ShortLink)
+		assert.Equal(t, &expiresAt, link.ExpiresAt)
+		cache.AssertExpectations(t)
+	})
+
+	t.Run("expired shared link is created again with the default TTL", func(t *testing.T) {
+		ctx, repo, cache, svc := getMocksWithService()
+		svc.defaultTTL = time.Hour
+
+		expired := time.Now().Add(-time.Second)
+		cache.On("GetShortLink", ctx, "https://example.com").Return("", nil)
+		repo.On("FindByOriginalURL", ctx, "https://example.com").
+			Return(&models.LinkURL{OriginalURL: "https://example.com", ShortLink: "abc123", ExpiresAt: &expired}, nil)
+		repo.On("FindByShortLink", ctx, mock.Anything).Return("", nil)
+
+		link, err := svc.ShortenURL(ctx, "https://example.com", baseURL, ShortenOptions{})
+		assert.NoError(t, err)
+		if assert.NotNil(t, link.ExpiresAt) {
+			assert.WithinDuration(t, time.Now().Add(time.Hour), *link.ExpiresAt, time.Minute)
+		}
+		cache.AssertNotCalled(t, "SetShortLink", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("cached key is saved with the default TTL", func(t *testing.T) {
+		ctx, repo, cache, svc := getMocksWithService()
+		svc.defaultTTL = time.Hour
+
+		// Ключ ещё в кэше, но ссылка в базе уже истекла: повторное сохранение не делает её бессрочной
+		cache.On("GetShortLink", ctx, "https://example.com").Return("abc123", nil)
+		repo.On("SaveLinks", ctx, mock.MatchedBy(func(links []models.LinkURL) bool {
+			return len(links) == 1 && links[0].ShortLink == "abc123" && links[0].ExpiresAt != nil
+		}), false).Return(saveAsIs)
+		cache.On("DeleteRedirects", ctx, []string{"abc123"}).Return(nil).Maybe()
+		cache.On("SetShortLink", ctx, "https://example.com", "abc123", mock.Anything).Return(nil)
+		cache.On("SetRedirect", ctx, "abc123", mock.Anything, mock.Anything).Return(nil).Maybe()
+
+		link, err := svc.ShortenURL(ctx, "https://example.com", baseURL, ShortenOptions{})
+		assert.NoError(t, err)
+		saved, err := svc.SaveLink(ctx, link)
+		assert.NoError(t, err)
+		if assert.NotNil(t, saved.ExpiresAt) {
+			assert.WithinDuration(t, time.Now().Add(time.Hour), *saved.ExpiresAt, time.Minute)
+		}
+		repo.AssertExpectations(t)
+	})
+}
+
+func TestService_ShortenURL_RedirectCode(t *testing.T) {
+	ctx, repo, cache, _ := getMocksWithService()
+	svc := NewLinkService(ctx, repo, cache, nil, nil, WithDefaultRedirectCode(http.StatusFound))
+
+	repo.On("FindByShortLink", ctx, mock.Anything).Return("", nil)
+	cache.On("GetShortLink", ctx, "https://example.com").Return("", nil)
+	repo.On("FindByOriginalURL", ctx, "https://example.com").Return(nil, nil)
+
+	link, err := svc.ShortenURL(ctx, "https://example.com", "https://localhost:8080", ShortenOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusFound, link.RedirectCode)
+	assert.False(t, link.Custom)
+
+	link, err = svc.ShortenURL(ctx, "https://example.com", "https://localhost:8080", ShortenOptions{RedirectCode: http.StatusPermanentRedirect})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusPermanentRedirect, link.RedirectCode)
+	assert.True(t, link.Custom, "ссылка со своим кодом перенаправления не должна переиспользоваться")
+
+	_, err = svc.ShortenURL(ctx, "https://example.com", "https://localhost:8080", ShortenOptions{RedirectCode: http.StatusSeeOther})
+	assert.Error(t, err)
+}
+
+func TestParseTTL(t *testing.T) {
+	tests := []struct {
+		value       string
+		expected    time.Duration
+		expectError bool
+	}{
+		{value: "24h", expected: 24 * time.Hour},
+		{value: "7d", expected: 7 * 24 * time.Hour},
+		{value: "0", expected: 0},
+		{value: "-1h", expectError: true},
+		{value: "week", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			ttl, err := ParseTTL(tt.value)
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, ttl)
+		})
+	}
+}
+
+func TestParseExpiry(t *testing.T) {
+	future := time.Now().Add(time.Hour)
+	past := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name        string
+		ttl         string
+		expiresAt   *time.Time
+		expectNil   bool
+		expectError bool
+	}{
+		{name: "default", expectNil: true},
+		{name: "ttl", ttl: "7d"},
+		{name: "expires_at", expiresAt: &future},
+		{name: "both", ttl: "24h", expiresAt: &future, expectError: true},
+		{name: "invalid ttl", ttl: "week", expectError: true},
+		{name: "expires_at in the past", expiresAt: &past, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ttl, err := ParseExpiry(tt.ttl, tt.expiresAt)
+			if tt.expectError {
+				assert.ErrorIs(t, err, ErrInvalidTTL)
+				return
+			}
+			assert.NoError(t, err)
+			if tt.expectNil {
+				assert.Nil(t, ttl)
+				return
+			}
+			if assert.NotNil(t, ttl) {
+				assert.Positive(t, *ttl)
+			}
+		})
+	}
+}
+
+func TestService_SaveLink(t *testing.T) {
 	type mockBehavior func(repo *mocks.LinkRepo, cache *mocks.LinkCache)
 
 	tests := []struct {
 		name         string
-		originalURL  string
-		shortLink    string
+		link         models.LinkURL
 		mockBehavior mockBehavior
+		expected     string
 		expectError  bool
 	}{
 		{
-			name:        "success",
-			originalURL: "https://example.com",
-			shortLink:   "short123",
+			name: "success",
+			link: models.LinkURL{OriginalURL: "https://example.com", ShortLink: "short123"},
 			mockBehavior: func(repo *mocks.LinkRepo, cache *mocks.LinkCache) {
-				repo.On("Insert", mock.Anything, "https://example.com", "short123").Return(nil)
+				repo.On("SaveLinks", mock.Anything, []models.LinkURL{{OriginalURL: "https://example.com", ShortLink: "short123", RedirectCode: 301}}, false).
+					Return(saveAsIs)
+				cache.On("DeleteRedirects", mock.Anything, []string{"short123"}).Return(nil)
 				cache.On("SetShortLink", mock.Anything, "https://example.com", "short123", mock.Anything).Return(nil)
 			},
+			expected:    "short123",
+			expectError: false,
+		},
+		{
+			name: "shared link created concurrently is returned",
+			link: models.LinkURL{OriginalURL: "https://example.com", ShortLink: "short123"},
+			mockBehavior: func(repo *mocks.LinkRepo, cache *mocks.LinkCache) {
+				repo.On("SaveLinks", mock.Anything, mock.Anything, false).
+					Return([]models.LinkURL{{OriginalURL: "https://example.com", ShortLink: "other1", RedirectCode: 301}},
+						[]models.InsertStatus{models.InsertExisting}, nil)
+				cache.On("DeleteRedirects", mock.Anything, []string{"other1"}).Return(nil)
+				cache.On("SetShortLink", mock.Anything, "https://example.com", "other1", mock.Anything).Return(nil)
+			},
+			expected:    "other1",
 			expectError: false,
 		},
 		{
-			name:        "repo.Insert returns error",
-			originalURL: "https://repoerror.com",
-			shortLink:   "err123",
+			name: "custom alias is not cached by original URL",
+			link: models.LinkURL{OriginalURL: "https://example.com", ShortLink: "spring-sale", Custom: true},
 			mockBehavior: func(repo *mocks.LinkRepo, cache *mocks.LinkCache) {
-				repo.On("Insert", mock.Anything, "https://repoerror.com", "err123").Return(fmt.Errorf("repo error"))
+				repo.On("SaveLinks", mock.Anything, mock.Anything, false).Return(saveAsIs)
+				cache.On("DeleteRedirects", mock.Anything, []string{"spring-sale"}).Return(nil)
+			},
+			expected:    "spring-sale",
+			expectError: false,
+		},
+		{
+			name: "repo.SaveLinks returns error",
+			link: models.LinkURL{OriginalURL: "https://repoerror.com", ShortLink: "err123"},
+			mockBehavior: func(repo *mocks.LinkRepo, cache *mocks.LinkCache) {
+				repo.On("SaveLinks", mock.Anything, mock.Anything, false).Return(nil, nil, fmt.Errorf("repo error"))
 			},
 			expectError: true,
 		},
 		{
-			name:        "cache.SetShortLink returns error",
-			originalURL: "https://cacheerror.com",
-			shortLink:   "cache123",
+			name: "cache errors do not fail the request",
+			link: models.LinkURL{OriginalURL: "https://cacheerror.com", ShortLink: "cache123"},
 			mockBehavior: func(repo *mocks.LinkRepo, cache *mocks.LinkCache) {
-				repo.On("Insert", mock.Anything, "https://cacheerror.com", "cache123").Return(nil)
+				repo.On("SaveLinks", mock.Anything, mock.Anything, false).Return(saveAsIs)
+				cache.On("DeleteRedirects", mock.Anything, []string{"cache123"}).Return(fmt.Errorf("cache error"))
 				cache.On("SetShortLink", mock.Anything, "https://cacheerror.com", "cache123", mock.Anything).Return(fmt.Errorf("cache error"))
 			},
-			expectError: true,
+			expected:    "cache123",
+			expectError: false,
 		},
 	}
 
@@ -225,12 +484,13 @@ func TestService_InsertLink(t *testing.T) {
 			ctx, repo, cache, svc := getMocksWithService()
 			tt.mockBehavior(repo, cache)
 
-			err := svc.InsertLink(ctx, tt.originalURL, tt.shortLink)
+			link, err := svc.SaveLink(ctx, tt.link)
 
 			if tt.expectError {
 				assert.Error(t, err)
 			} else {
 				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, link.ShortLink)
 			}
 
 			repo.AssertExpectations(t)
@@ -239,7 +499,19 @@ func TestService_InsertLink(t *testing.T) {
 	}
 }
 
-func TestService_GetOriginalURL(t *testing.T) {
+func TestService_SaveLinkAliasTakenConcurrently(t *testing.T) {
+	ctx, repo, cache, svc := getMocksWithService()
+	repo.On("SaveLinks", ctx, mock.Anything, false).Return(
+		[]models.LinkURL{{OriginalURL: "https://example.com", ShortLink: "spring-sale", Custom: true}},
+		[]models.InsertStatus{models.InsertConflict}, nil)
+
+	_, err := svc.SaveLink(ctx, models.LinkURL{OriginalURL: "https://example.com", ShortLink: "spring-sale", Custom: true})
+	assert.ErrorIs(t, err, ErrAliasTaken)
+	assert.Equal(t, "alias_taken", ErrorCode(err))
+	cache.AssertNotCalled(t, "DeleteRedirects", mock.Anything, mock.Anything)
+}
+
+func TestService_GetRedirect(t *testing.T) {
 	type mockBehavior func(repo *mocks.LinkRepo, cache *mocks.LinkCache)
 
 	tests := []struct {
@@ -247,43 +519,73 @@ func TestService_GetOriginalURL(t *testing.T) {
 		shortLink    string
 		mockBehavior mockBehavior
 		expectedURL  string
+		expectedCode int
 		expectError  bool
+		expectedErr  error
 	}{
 		{
 			name:      "found in cache",
 			shortLink: "short123",
 			mockBehavior: func(repo *mocks.LinkRepo, cache *mocks.LinkCache) {
-				cache.On("GetOriginalURL", mock.Anything, "short123").Return("https://example.com", nil)
+				cache.On("GetRedirect", mock.Anything, "short123").Return(&models.Redirect{URL: "https://example.com", Code: 302}, nil)
 			},
-			expectedURL: "https://example.com",
-			expectError: false,
+			expectedURL:  "https://example.com",
+			expectedCode: 302,
+			expectError:  false,
 		},
 		{
-			name:      "cache error",
-			shortLink: "cacheFail",
+			name:      "expired link in cache",
+			shortLink: "expiredCached",
 			mockBehavior: func(repo *mocks.LinkRepo, cache *mocks.LinkCache) {
-				cache.On("GetOriginalURL", mock.Anything, "cacheFail").Return("", fmt.Errorf("cache error"))
+				expiresAt := time.Now().Add(-time.Second)
+				cache.On("GetRedirect", mock.Anything, "expiredCached").
+					Return(&models.Redirect{URL: "https://example.com", Code: 302, ExpiresAt: &expiresAt}, nil)
 			},
-			expectedURL: "",
 			expectError: true,
+			expectedErr: ErrLinkExpired,
+		},
+		{
+			name:      "cache error falls back to DB",
+			shortLink: "cacheFail",
+			mockBehavior: func(repo *mocks.LinkRepo, cache *mocks.LinkCache) {
+				cache.On("GetRedirect", mock.Anything, "cacheFail").Return(nil, fmt.Errorf("cache error"))
+				repo.On("FindLink", mock.Anything, "cacheFail").Return(&models.LinkURL{OriginalURL: "https://fromdb.com", ShortLink: "cacheFail", RedirectCode: 302}, nil)
+				cache.On("SetRedirect", mock.Anything, "cacheFail", models.Redirect{URL: "https://fromdb.com", Code: 302}, mock.Anything).Return(fmt.Errorf("cache error"))
+			},
+			expectedURL:  "https://fromdb.com",
+			expectedCode: 302,
+			expectError:  false,
 		},
 		{
 			name:      "found in DB after cache miss",
 			shortLink: "db123",
 			mockBehavior: func(repo *mocks.LinkRepo, cache *mocks.LinkCache) {
-				cache.On("GetOriginalURL", mock.Anything, "db123").Return("", nil)
-				repo.On("FindByShortLink", mock.Anything, "db123").Return("https://fromdb.com", nil)
-				cache.On("SetOriginalURL", mock.Anything, "db123", "https://fromdb.com", mock.Anything).Return(nil)
+				cache.On("GetRedirect", mock.Anything, "db123").Return(nil, nil)
+				repo.On("FindLink", mock.Anything, "db123").Return(&models.LinkURL{OriginalURL: "https://fromdb.com", ShortLink: "db123", RedirectCode: 307}, nil)
+				cache.On("SetRedirect", mock.Anything, "db123", models.Redirect{URL: "https://fromdb.com", Code: 307}, mock.Anything).Return(nil)
 			},
-			expectedURL: "https://fromdb.com",
-			expectError: false,
+			expectedURL:  "https://fromdb.com",
+			expectedCode: 307,
+			expectError:  false,
+		},
+		{
+			name:      "protected link keeps password hash in cache",
+			shortLink: "secret",
+			mockBehavior: func(repo *mocks.LinkRepo, cache *mocks.LinkCache) {
+				cache.On("GetRedirect", mock.Anything, "secret").Return(nil, nil)
+				repo.On("FindLink", mock.Anything, "secret").Return(&models.LinkURL{OriginalURL: "https://docs.com", ShortLink: "secret", RedirectCode: 302, PasswordHash: "hash"}, nil)
+				cache.On("SetRedirect", mock.Anything, "secret", models.Redirect{URL: "https://docs.com", Code: 302, PasswordHash: "hash"}, mock.Anything).Return(nil)
+			},
+			expectedURL:  "https://docs.com",
+			expectedCode: 302,
+			expectError:  false,
 		},
 		{
 			name:      "DB returns error",
 			shortLink: "dberror",
 			mockBehavior: func(repo *mocks.LinkRepo, cache *mocks.LinkCache) {
-				cache.On("GetOriginalURL", mock.Anything, "dberror").Return("", nil)
-				repo.On("FindByShortLink", mock.Anything, "dberror").Return("", fmt.Errorf("db error"))
+				cache.On("GetRedirect", mock.Anything, "dberror").Return(nil, nil)
+				repo.On("FindLink", mock.Anything, "dberror").Return(nil, fmt.Errorf("db error"))
 			},
 			expectedURL: "",
 			expectError: true,
@@ -292,22 +594,47 @@ func TestService_GetOriginalURL(t *testing.T) {
 			name:      "not found in cache or DB",
 			shortLink: "notfound",
 			mockBehavior: func(repo *mocks.LinkRepo, cache *mocks.LinkCache) {
-				cache.On("GetOriginalURL", mock.Anything, "notfound").Return("", nil)
-				repo.On("FindByShortLink", mock.Anything, "notfound").Return("", nil)
+				cache.On("GetRedirect", mock.Anything, "notfound").Return(nil, nil)
+				repo.On("FindLink", mock.Anything, "notfound").Return(nil, nil)
+				cache.On("SetRedirect", mock.Anything, "notfound", models.Redirect{NotFound: true}, defaultNegativeCacheTTL).Return(nil)
 			},
 			expectedURL: "",
-			expectError: false,
+			expectError: true,
+			expectedErr: ErrLinkNotFound,
+		},
+		{
+			name:      "missing link is served from negative cache",
+			shortLink: "probe",
+			mockBehavior: func(repo *mocks.LinkRepo, cache *mocks.LinkCache) {
+				cache.On("GetRedirect", mock.Anything, "probe").Return(&models.Redirect{NotFound: true}, nil)
+			},
+			expectedURL: "",
+			expectError: true,
+			expectedErr: ErrLinkNotFound,
 		},
 		{
-			name:      "cache set fails after DB hit",
+			name:      "cache set failure after DB hit does not fail the request",
 			shortLink: "setfail",
 			mockBehavior: func(repo *mocks.LinkRepo, cache *mocks.LinkCache) {
-				cache.On("GetOriginalURL", mock.Anything, "setfail").Return("", nil)
-				repo.On("FindByShortLink", mock.Anything, "setfail").Return("https://setfail.com", nil)
-				cache.On("SetOriginalURL", mock.Anything, "setfail", "https://setfail.com", mock.Anything).Return(fmt.Errorf("cache set error"))
+				cache.On("GetRedirect", mock.Anything, "setfail").Return(nil, nil)
+				repo.On("FindLink", mock.Anything, "setfail").Return(&models.LinkURL{OriginalURL: "https://setfail.com", ShortLink: "setfail"}, nil)
+				cache.On("SetRedirect", mock.Anything, "setfail", models.Redirect{URL: "https://setfail.com", Code: 301}, mock.Anything).Return(fmt.Errorf("cache set error"))
+			},
+			expectedURL:  "https://setfail.com",
+			expectedCode: 301,
+			expectError:  false,
+		},
+		{
+			name:      "expired link",
+			shortLink: "expired",
+			mockBehavior: func(repo *mocks.LinkRepo, cache *mocks.LinkCache) {
+				expiresAt := time.Now().Add(-time.Hour)
+				cache.On("GetRedirect", mock.Anything, "expired").Return(nil, nil)
+				repo.On("FindLink", mock.Anything, "expired").Return(&models.LinkURL{OriginalURL: "https://old.com", ShortLink: "expired", ExpiresAt: &expiresAt}, nil)
 			},
 			expectedURL: "",
 			expectError: true,
+			expectedErr: ErrLinkExpired,
 		},
 	}
 
@@ -316,13 +643,21 @@ func TestService_GetOriginalURL(t *testing.T) {
 			ctx, repo, cache, svc := getMocksWithService()
 			tt.mockBehavior(repo, cache)
 
-			url, err := svc.GetOriginalURL(ctx, tt.shortLink)
+			redirect, err := svc.GetRedirect(ctx, tt.shortLink)
 
-			if tt.expectError {
+			switch {
+			case tt.expectError:
 				assert.Error(t, err)
-			} else {
+				if tt.expectedErr != nil {
+					assert.ErrorIs(t, err, tt.expectedErr)
+				}
+			case tt.expectedURL == "":
+				assert.NoError(t, err)
+				assert.Nil(t, redirect)
+			default:
 				assert.NoError(t, err)
-				assert.Equal(t, tt.expectedURL, url)
+				assert.Equal(t, tt.expectedURL, redirect.URL)
+				assert.Equal(t, tt.expectedCode, redirect.Code)
 			}
 
 			repo.AssertExpectations(t)
@@ -331,8 +666,48 @@ func TestService_GetOriginalURL(t *testing.T) {
 	}
 }
 
+func TestService_GetRedirectCoalescesDatabaseReads(t *testing.T) {
+	ctx, repo, cache, svc := getMocksWithService()
+	const callers = 10
+
+	release := make(chan struct{})
+	var waiting sync.WaitGroup
+	waiting.Add(callers)
+	cache.On("GetRedirect", mock.Anything, "hot").Return(nil, nil).Run(func(mock.Arguments) { waiting.Done() })
+	repo.On("FindLink", mock.Anything, "hot").Return(&models.LinkURL{OriginalURL: "https://hot.com", ShortLink: "hot", RedirectCode: 302}, nil).
+		Run(func(mock.Arguments) { <-release }).Once()
+	cache.On("SetRedirect", mock.Anything, "hot", models.Redirect{URL: "https://hot.com", Code: 302}, mock.Anything).Return(nil).Once()
+
+	var done sync.WaitGroup
+	redirects := make([]*models.Redirect, callers)
+	for i := range callers {
+		done.Add(1)
+		go func() {
+			defer done.Done()
+			redirect, err := svc.GetRedirect(ctx, "hot")
+			assert.NoError(t, err)
+			redirects[i] = redirect
+		}()
+	}
+
+	// Все запросы промахнулись мимо кэша, пока первый читает базу
+	waiting.Wait()
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	done.Wait()
+
+	for _, redirect := range redirects {
+		assert.Equal(t, "https://hot.com", redirect.URL)
+	}
+	redirects[0].URL = "https://changed.com"
+	assert.Equal(t, "https://hot.com", redirects[1].URL, "каждый запрос получает свою копию")
+	repo.AssertExpectations(t)
+	cache.AssertExpectations(t)
+}
+
 func TestService_InsertBatch(t *testing.T) {
 	type mockBehavior func(repo *mocks.LinkRepo, cache *mocks.LinkCache)
+	past := time.Now().Add(-time.Minute)
 
 	tests := []struct {
 		name         string
@@ -354,12 +729,46 @@ func TestService_InsertBatch(t *testing.T) {
 			},
 			mockBehavior: func(repo *mocks.LinkRepo, cache *mocks.LinkCache) {
 				repo.On("InsertBatch", mock.Anything, mock.AnythingOfType("[]models.LinkURL")).
-					Return(nil) // <- int64 здесь!
+					Return([]models.InsertStatus{models.InsertInserted, models.InsertExisting}, nil)
 				cache.On("SetShortLink", mock.Anything, "https://example.com/1", "short1", mock.Anything).Return(nil)
 				cache.On("SetShortLink", mock.Anything, "https://example.com/2", "short2", mock.Anything).Return(nil)
 			},
 			expectError: false,
 		},
+		{
+			name: "conflicting link is not cached",
+			batch: []models.LinkURL{
+				{OriginalURL: "https://example.com/1", ShortLink: "short1"},
+				{OriginalURL: "https://example.com/2", ShortLink: "taken"},
+			},
+			mockBehavior: func(repo *mocks.LinkRepo, cache *mocks.LinkCache) {
+				repo.On("InsertBatch", mock.Anything, mock.Anything).
+					Return([]models.InsertStatus{models.InsertInserted, models.InsertConflict}, nil)
+				cache.On("SetShortLink", mock.Anything, "https://example.com/1", "short1", mock.Anything).Return(nil).Once()
+			},
+			expectError: false,
+		},
+		{
+			name: "expired link is neither inserted nor cached",
+			batch: []models.LinkURL{
+				{OriginalURL: "https://example.com/1", ShortLink: "short1"},
+				{OriginalURL: "https://example.com/2", ShortLink: "short2", ExpiresAt: &past},
+			},
+			mockBehavior: func(repo *mocks.LinkRepo, cache *mocks.LinkCache) {
+				repo.On("InsertBatch", mock.Anything, []models.LinkURL{{OriginalURL: "https://example.com/1", ShortLink: "short1", RedirectCode: 301}}).
+					Return([]models.InsertStatus{models.InsertInserted}, nil)
+				cache.On("SetShortLink", mock.Anything, "https://example.com/1", "short1", mock.Anything).Return(nil).Once()
+			},
+			expectError: false,
+		},
+		{
+			name: "batch of expired links skips the repository",
+			batch: []models.LinkURL{
+				{OriginalURL: "https://example.com/1", ShortLink: "short1", ExpiresAt: &past},
+			},
+			mockBehavior: func(repo *mocks.LinkRepo, cache *mocks.LinkCache) {},
+			expectError:  false,
+		},
 		{
 			name: "InsertBatch returns error",
 			batch: []models.LinkURL{
@@ -367,21 +776,21 @@ func TestService_InsertBatch(t *testing.T) {
 			},
 			mockBehavior: func(repo *mocks.LinkRepo, cache *mocks.LinkCache) {
 				repo.On("InsertBatch", mock.Anything, mock.Anything).
-					Return(fmt.Errorf("insert batch error"))
+					Return(nil, fmt.Errorf("insert batch error"))
 			},
 			expectError: true,
 		},
 		{
-			name: "cache SetShortLink returns error",
+			name: "cache SetShortLink error does not fail the batch",
 			batch: []models.LinkURL{
 				{OriginalURL: "https://example.com/1", ShortLink: "short1"},
 			},
 			mockBehavior: func(repo *mocks.LinkRepo, cache *mocks.LinkCache) {
-				repo.On("InsertBatch", mock.Anything, mock.Anything).Return(nil)
+				repo.On("InsertBatch", mock.Anything, mock.Anything).Return([]models.InsertStatus{models.InsertInserted}, nil)
 				cache.On("SetShortLink", mock.Anything, "https://example.com/1", "short1", mock.Anything).
 					Return(fmt.Errorf("cache error"))
 			},
-			expectError: true,
+			expectError: false,
 		},
 	}
 
@@ -391,7 +800,7 @@ func TestService_InsertBatch(t *testing.T) {
 
 			tt.mockBehavior(repo, cache)
 
-			err := svc.InsertBatch(ctx, tt.batch)
+			_, err := svc.InsertBatch(ctx, tt.batch)
 
 			if tt.expectError {
 				assert.Error(t, err)
@@ -405,6 +814,11 @@ func TestService_InsertBatch(t *testing.T) {
 	}
 }
 
+func hashKey(originalURL string, attempt int) string {
+	key, _ := HashKeyGenerator{Length: defaultKeyLength}.Generate(context.Background(), originalURL, attempt)
+	return key
+}
+
 func getMocksWithService() (ctx context.Context, mockRepo *mocks.LinkRepo, mockCache *mocks.LinkCache, svc *Service) {
 
 	ctx = context.Background()
@@ -416,3 +830,166 @@ func getMocksWithService() (ctx context.Context, mockRepo *mocks.LinkRepo, mockC
 
 	return ctx, mockRepo, mockCache, svc
 }
+
+func TestService_UpdateOriginalURL(t *testing.T) {
+	const baseURL = "https://localhost:8080"
+
+	t.Run("retargets link and invalidates cache", func(t *testing.T) {
+		ctx, repo, cache, svc := getMocksWithService()
+
+		repo.On("FindLink", ctx, "abc123").Return(&models.LinkURL{OriginalURL: "https://old.com", ShortLink: "abc123", OwnerID: "team"}, nil)
+		repo.On("UpdateOriginalURL", ctx, "abc123", "https://new.com").Return(nil)
+		cache.On("Invalidate", ctx, "abc123", "https://old.com").Return(nil)
+
+		link, err := svc.UpdateOriginalURL(ctx, "team", "abc123", "https://new.com", baseURL)
+		assert.NoError(t, err)
+		assert.Equal(t, "https://new.com", link.OriginalURL)
+		assert.True(t, link.Custom)
+		repo.AssertExpectations(t)
+		cache.AssertExpectations(t)
+	})
+
+	t.Run("cache failure keeps update and bypasses cache until retried", func(t *testing.T) {
+		ctx, repo, cache, svc := getMocksWithService()
+		logger := logrus.New()
+		logger.SetOutput(io.Discard)
+
+		repo.On("FindLink", ctx, "abc123").Return(&models.LinkURL{OriginalURL: "https://old.com", ShortLink: "abc123", OwnerID: "team"}, nil).Once()
+		repo.On("UpdateOriginalURL", ctx, "abc123", "https://new.com").Return(nil)
+		cache.On("Invalidate", mock.Anything, "abc123", "https://old.com").Return(fmt.Errorf("redis down")).Twice()
+
+		link, err := svc.UpdateOriginalURL(ctx, "team", "abc123", "https://new.com", baseURL)
+		assert.NoError(t, err)
+		assert.Equal(t, "https://new.com", link.OriginalURL)
+
+		// Кэш ещё хранит прежний адрес, поэтому ссылка читается из базы
+		repo.On("FindLink", mock.Anything, "abc123").Return(&models.LinkURL{OriginalURL: "https://new.com", ShortLink: "abc123", RedirectCode: 302}, nil)
+		cache.On("SetRedirect", mock.Anything, "abc123", mock.Anything, mock.Anything).Return(nil)
+		redirect, err := svc.GetRedirect(ctx, "abc123")
+		if assert.NoError(t, err) {
+			assert.Equal(t, "https://new.com", redirect.URL)
+		}
+		cache.AssertNotCalled(t, "GetRedirect", mock.Anything, "abc123")
+
+		svc.retryInvalidations(logger)
+		assert.True(t, svc.stale.hasLink("abc123"), "неудачный повтор оставляет ссылку в обход кэша")
+
+		cache.On("Invalidate", mock.Anything, "abc123", "https://old.com").Return(nil).Once()
+		svc.retryInvalidations(logger)
+		assert.False(t, svc.stale.hasLink("abc123"))
+		assert.False(t, svc.stale.hasURL("https://old.com"))
+		cache.AssertExpectations(t)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		ctx, repo, _, svc := getMocksWithService()
+
+		repo.On("FindLink", ctx, "missing").Return(nil, nil)
+
+		_, err := svc.UpdateOriginalURL(ctx, "team", "missing", "https://new.com", baseURL)
+		assert.ErrorIs(t, err, ErrLinkNotFound)
+	})
+
+	t.Run("link of another owner", func(t *testing.T) {
+		ctx, repo, _, svc := getMocksWithService()
+
+		repo.On("FindLink", ctx, "abc123").Return(&models.LinkURL{OriginalURL: "https://old.com", ShortLink: "abc123", OwnerID: "team"}, nil)
+
+		_, err := svc.UpdateOriginalURL(ctx, "other", "abc123", "https://new.com", baseURL)
+		assert.ErrorIs(t, err, ErrLinkNotFound)
+		repo.AssertNotCalled(t, "UpdateOriginalURL", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("invalid URL", func(t *testing.T) {
+		ctx, repo, _, svc := getMocksWithService()
+
+		_, err := svc.UpdateOriginalURL(ctx, "team", "abc123", "ftp://new.com", baseURL)
+		assert.Error(t, err)
+		repo.AssertNotCalled(t, "FindLink", mock.Anything, mock.Anything)
+	})
+}
+
+func TestService_DeleteLink(t *testing.T) {
+	t.Run("deletes link and invalidates cache", func(t *testing.T) {
+		ctx, repo, cache, svc := getMocksWithService()
+
+		repo.On("FindLink", ctx, "abc123").Return(&models.LinkURL{OriginalURL: "https://old.com", ShortLink: "abc123", OwnerID: "team"}, nil)
+		repo.On("Delete", ctx, "abc123").Return(nil)
+		cache.On("Invalidate", ctx, "abc123", "https://old.com").Return(nil)
+
+		assert.NoError(t, svc.DeleteLink(ctx, "team", "abc123"))
+		repo.AssertExpectations(t)
+		cache.AssertExpectations(t)
+	})
+
+	t.Run("cache failure keeps deletion", func(t *testing.T) {
+		ctx, repo, cache, svc := getMocksWithService()
+
+		repo.On("FindLink", ctx, "abc123").Return(&models.LinkURL{OriginalURL: "https://old.com", ShortLink: "abc123", OwnerID: "team"}, nil)
+		repo.On("Delete", ctx, "abc123").Return(nil)
+		cache.On("Invalidate", ctx, "abc123", "https://old.com").Return(fmt.Errorf("redis down"))
+
+		assert.NoError(t, svc.DeleteLink(ctx, "team", "abc123"))
+		assert.True(t, svc.stale.hasLink("abc123"))
+
+		// Кэш URL может указывать на удалённую ссылку, поэтому сокращение ищет его в базе
+		repo.On("FindByOriginalURL", ctx, "https://old.com").Return(&models.LinkURL{OriginalURL: "https://old.com", ShortLink: "def456"}, nil)
+		cache.On("SetShortLink", ctx, "https://old.com", "def456", mock.Anything).Return(nil)
+		link, err := svc.ShortenURL(ctx, "https://old.com", "https://localhost:8080", ShortenOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, "def456", link.ShortLink)
+		cache.AssertNotCalled(t, "GetShortLink", mock.Anything, "https://old.com")
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		ctx, repo, _, svc := getMocksWithService()
+
+		repo.On("FindLink", ctx, "missing").Return(nil, nil)
+
+		assert.ErrorIs(t, svc.DeleteLink(ctx, "team", "missing"), ErrLinkNotFound)
+	})
+
+	t.Run("anonymous link cannot be managed", func(t *testing.T) {
+		ctx, repo, _, svc := getMocksWithService()
+
+		repo.On("FindLink", ctx, "anon").Return(&models.LinkURL{OriginalURL: "https://old.com", ShortLink: "anon"}, nil)
+
+		assert.ErrorIs(t, svc.DeleteLink(ctx, "team", "anon"), ErrLinkNotFound)
+		repo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+	})
+
+	t.Run("repo error", func(t *testing.T) {
+		ctx, repo, _, svc := getMocksWithService()
+
+		repo.On("FindLink", ctx, "abc123").Return(&models.LinkURL{OriginalURL: "https://old.com", ShortLink: "abc123", OwnerID: "team"}, nil)
+		repo.On("Delete", ctx, "abc123").Return(fmt.Errorf("db error"))
+
+		assert.Error(t, svc.DeleteLink(ctx, "team", "abc123"))
+	})
+}
+
+func TestService_WithoutCache(t *testing.T) {
+	ctx := context.Background()
+	repo := new(mocks.LinkRepo)
+	svc := NewLinkService(ctx, repo, nil, nil, nil)
+
+	repo.On("FindLink", mock.Anything, "abc123").Return(&models.LinkURL{OriginalURL: "https://a.com", ShortLink: "abc123", RedirectCode: 302}, nil)
+	repo.On("FindLink", mock.Anything, "missing").Return(nil, nil).Twice()
+	repo.On("SaveLinks", ctx, mock.Anything, false).Return(saveAsIs)
+
+	redirect, err := svc.GetRedirect(ctx, "abc123")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "https://a.com", redirect.URL)
+	}
+
+	// Без кэша отсутствие ссылки каждый раз проверяется по базе
+	for range 2 {
+		_, err = svc.GetRedirect(ctx, "missing")
+		assert.ErrorIs(t, err, ErrLinkNotFound)
+	}
+
+	link, err := svc.SaveLink(ctx, models.LinkURL{OriginalURL: "https://b.com", ShortLink: "b1"})
+	assert.NoError(t, err)
+	assert.Equal(t, "b1", link.ShortLink)
+	repo.AssertExpectations(t)
+}
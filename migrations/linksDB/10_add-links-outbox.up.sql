@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS links_outbox
+(
+    id         BIGSERIAL PRIMARY KEY,
+    topic      TEXT        NOT NULL,
+    key        TEXT        NOT NULL DEFAULT '',
+    payload    BYTEA       NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS links_link_generated_key;
+DELETE FROM links WHERE custom OR length(short_link) > 8;
+ALTER TABLE links DROP COLUMN IF EXISTS custom;
+ALTER TABLE links ADD CONSTRAINT links_link_key UNIQUE (link);
+ALTER TABLE links ALTER COLUMN short_link TYPE VARCHAR(8);
@@ -0,0 +1,7 @@
+ALTER TABLE links ALTER COLUMN short_link TYPE VARCHAR(64);
+
+ALTER TABLE links ADD COLUMN IF NOT EXISTS custom BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Один и тот же URL может иметь несколько псевдонимов, уникальность сохраняется только для сгенерированных ссылок
+ALTER TABLE links DROP CONSTRAINT IF EXISTS links_link_key;
+CREATE UNIQUE INDEX IF NOT EXISTS links_link_generated_key ON links (link) WHERE NOT custom;
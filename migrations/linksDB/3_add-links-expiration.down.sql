@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS links_expires_at_idx;
+ALTER TABLE links DROP COLUMN IF EXISTS expires_at;
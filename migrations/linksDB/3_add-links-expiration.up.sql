@@ -0,0 +1,6 @@
+ALTER TABLE links ADD COLUMN IF NOT EXISTS expires_at TIMESTAMPTZ;
+
+-- Существующие ссылки сохраняют прежний срок жизни в 2 недели
+UPDATE links SET expires_at = created_at + INTERVAL '2 weeks' WHERE expires_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS links_expires_at_idx ON links (expires_at) WHERE expires_at IS NOT NULL;
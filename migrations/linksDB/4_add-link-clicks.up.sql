@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS link_clicks
+(
+    id         BIGSERIAL PRIMARY KEY,
+    short_link VARCHAR(64) NOT NULL,
+    clicked_at TIMESTAMPTZ NOT NULL,
+    referrer   TEXT        NOT NULL DEFAULT '',
+    user_agent TEXT        NOT NULL DEFAULT '',
+    ip_hash    VARCHAR(64) NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS link_clicks_short_link_clicked_at_idx ON link_clicks (short_link, clicked_at);
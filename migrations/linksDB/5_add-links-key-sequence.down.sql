@@ -0,0 +1 @@
+DROP SEQUENCE IF EXISTS links_key_seq;
@@ -0,0 +1,2 @@
+-- 238328 = 62^3: ключи стратегии "sequence" начинаются с 4 символов
+CREATE SEQUENCE IF NOT EXISTS links_key_seq START WITH 238328;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS links_owner_id_idx;
+ALTER TABLE links DROP COLUMN IF EXISTS owner_id;
+DROP TABLE IF EXISTS api_keys;
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS api_keys
+(
+    id         SERIAL PRIMARY KEY,
+    owner_id   VARCHAR(64) NOT NULL,
+    name       TEXT        NOT NULL DEFAULT '',
+    key_hash   VARCHAR(64) UNIQUE NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    revoked_at TIMESTAMPTZ
+);
+
+ALTER TABLE links ADD COLUMN IF NOT EXISTS owner_id VARCHAR(64);
+
+CREATE INDEX IF NOT EXISTS links_owner_id_idx ON links (owner_id) WHERE owner_id IS NOT NULL;
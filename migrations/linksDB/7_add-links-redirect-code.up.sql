@@ -0,0 +1,2 @@
+ALTER TABLE links ADD COLUMN IF NOT EXISTS redirect_code SMALLINT NOT NULL DEFAULT 301
+    CHECK (redirect_code IN (301, 302, 307, 308));